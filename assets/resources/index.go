@@ -13,6 +13,11 @@ import (
 const (
 	AppID      = "com.pojtinger.felicitas.Sessions"
 	AppVersion = "0.1.20"
+
+	// Needs to match the search provider's ini file in assets/meta
+	SearchProviderBusName = AppID + ".SearchProvider"
+
+	ControlBusName = AppID + ".Control"
 )
 
 //go:generate sh -c "blueprint-compiler batch-compile . . *.blp && glib-compile-resources *.gresource.xml"
@@ -22,7 +27,33 @@ var ResourceContents []byte
 //go:generate glib-compile-schemas .
 
 const (
-	SchemaLastPositionKey = "last-position"
+	SchemaLastPositionKey            = "last-position"
+	SchemaShowDialLabelsKey          = "show-dial-labels"
+	SchemaShowRunningNotificationKey = "show-running-notification"
+	SchemaDoNotDisturbKey            = "do-not-disturb"
+	SchemaPreventSuspendKey          = "prevent-suspend"
+	SchemaPauseWhenIdleKey           = "pause-when-idle"
+	SchemaIdleThresholdKey           = "idle-threshold"
+	SchemaCommandOnStartKey          = "command-on-start"
+	SchemaCommandOnStopKey           = "command-on-stop"
+	SchemaCommandOnAlarmStartKey     = "command-on-alarm-start"
+	SchemaCommandOnAlarmStopKey      = "command-on-alarm-stop"
+	SchemaCommandTimeoutKey          = "command-timeout"
+	SchemaWebhookURLsKey             = "webhook-urls"
+	SchemaWebhookSecretKey           = "webhook-secret"
+	SchemaAPIEnabledKey              = "api-enabled"
+	SchemaAPIAddressKey              = "api-address"
+	SchemaAPITokenKey                = "api-token"
+	SchemaTaskFileKey                = "task-file"
+	SchemaGoalPeriodKey              = "goal-period"
+	SchemaGoalSessionsKey            = "goal-sessions"
+	SchemaGoalFocusedTimeKey         = "goal-focused-time"
+	SchemaBreakDurationKey           = "break-duration"
+	SchemaAutoStartBreaksKey         = "auto-start-breaks"
+	SchemaAutoStartWorkKey           = "auto-start-work"
+	SchemaAutoStartGracePeriodKey    = "auto-start-grace-period"
+	SchemaBreakOverlayKey            = "break-overlay"
+	SchemaBreakStrictnessKey         = "break-strictness"
 )
 
 var (
@@ -32,8 +63,13 @@ var (
 	AppArtists    = []string{"Felicitas Pojtinger", "Mirabelle Salles"}
 	AppCopyright  = "© 2026 " + strings.Join(AppDevelopers, ", ")
 
-	ResourceWindowUIPath          = path.Join(AppPath, "window.ui")
-	ResourceShortcutsDialogUIPath = path.Join(AppPath, "shortcuts-dialog.ui")
-	ResourceMetainfoPath          = path.Join(AppPath, "metainfo.xml")
-	ResourceAlarmClockElapsedPath = path.Join(AppPath, "alarm-clock-elapsed.oga")
+	ResourceWindowUIPath            = path.Join(AppPath, "window.ui")
+	ResourceShortcutsDialogUIPath   = path.Join(AppPath, "shortcuts-dialog.ui")
+	ResourcePreferencesDialogUIPath = path.Join(AppPath, "preferences-dialog.ui")
+	ResourceBreakOverlayUIPath      = path.Join(AppPath, "break-overlay.ui")
+	ResourceMetainfoPath            = path.Join(AppPath, "metainfo.xml")
+	ResourceAlarmClockElapsedPath   = path.Join(AppPath, "alarm-clock-elapsed.oga")
+
+	SearchProviderPath = path.Join(AppPath, "SearchProvider")
+	ControlPath        = path.Join(AppPath, "Control")
 )
@@ -7,19 +7,20 @@ tool github.com/dennwc/flatpak-go-mod
 require (
 	codeberg.org/puregotk/purego v0.0.0-20260224095105-2513c838cb80
 	codeberg.org/puregotk/puregotk v0.0.0-20260420231554-98419d54d2d2
+	github.com/godbus/dbus/v5 v5.2.2
 	github.com/mappu/miqt v0.14.0
 	github.com/neilotoole/slogt v1.1.0
 	github.com/pojntfx/go-gettext v0.4.2
 	github.com/qmuntal/stateless v1.8.0
 	github.com/rymdport/portal v0.4.3-0.20260225172009-01112360d2cb
 	github.com/stretchr/testify v1.11.1
+	golang.org/x/image v0.25.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/dennwc/flatpak-go-mod v0.1.1-0.20250809093520-ddf8d84264aa // indirect
 	github.com/goccy/go-yaml v1.18.0 // indirect
-	github.com/godbus/dbus/v5 v5.2.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/mod v0.27.0 // indirect
 	golang.org/x/sys v0.43.0 // indirect
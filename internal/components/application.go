@@ -2,8 +2,14 @@ package components
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net/url"
+	"os"
 	"runtime"
+	"slices"
+	"strings"
+	"time"
 	"unsafe"
 
 	"codeberg.org/puregotk/puregotk/v4/adw"
@@ -13,6 +19,14 @@ import (
 	"codeberg.org/puregotk/puregotk/v4/gtk"
 	. "github.com/pojntfx/go-gettext/pkg/i18n"
 	"github.com/pojntfx/sessions/assets/resources"
+	"github.com/pojntfx/sessions/pkg/duration"
+	"github.com/pojntfx/sessions/pkg/goals"
+	"github.com/pojntfx/sessions/pkg/phases"
+	"github.com/rymdport/portal/filechooser"
+)
+
+const (
+	durationOption = "duration"
 )
 
 var (
@@ -28,6 +42,9 @@ type Application struct {
 
 	window      *MainWindow
 	aboutDialog *adw.AboutDialog
+
+	// Duration from the command line that is set once the window exists, zero otherwise
+	pendingDuration time.Duration
 }
 
 func NewApplication(ctx context.Context, settings *gio.Settings, log *slog.Logger, FirstPropertyNameVar string, varArgs ...interface{}) Application {
@@ -42,6 +59,17 @@ func NewApplication(ctx context.Context, settings *gio.Settings, log *slog.Logge
 	app.settings = settings
 	app.log = log
 
+	app.AddMainOption(
+		durationOption,
+		'd',
+		glib.GOptionFlagNoneValue,
+		glib.GOptionArgStringValue,
+		// TRANSLATORS: Description of the command line option that sets the duration of the timer.
+		L(`Set the duration of the timer, e.g. "25", "1h30" or "17:30"`),
+		// TRANSLATORS: Placeholder for the value of the command line option that sets the duration of the timer.
+		L("DURATION"),
+	)
+
 	return v
 }
 
@@ -72,6 +100,40 @@ func init() {
 
 		applicationClass := (*gio.ApplicationClass)(unsafe.Pointer(tc))
 
+		applicationClass.OverrideHandleLocalOptions(func(a *gio.Application, options *glib.VariantDict) int32 {
+			sessionsApp := (*Application)(unsafe.Pointer(a.GetData(dataKeyGoInstance)))
+
+			value := options.LookupValue(durationOption, glib.NewVariantType("s"))
+			if value == nil {
+				// Continue with the default handling
+				return -1
+			}
+
+			d, err := duration.Parse(value.GetString(nil), localizedUnits())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, L("Invalid duration: %v")+"\n", err)
+
+				return 1
+			}
+
+			if _, err := a.Register(nil); err != nil {
+				fmt.Fprintf(os.Stderr, L("Could not register application: %v")+"\n", err)
+
+				return 1
+			}
+
+			// If the app is already running, we set the duration there and exit
+			if a.GetIsRemote() {
+				a.ActivateAction("setDuration", glib.NewVariantInt64(int64(d.Seconds())))
+
+				return 0
+			}
+
+			sessionsApp.pendingDuration = d
+
+			return -1
+		})
+
 		applicationClass.OverrideActivate(func(a *gio.Application) {
 			sessionsApp := (*Application)(unsafe.Pointer(a.GetData(dataKeyGoInstance)))
 
@@ -108,11 +170,252 @@ func init() {
 			quitAction.ConnectActivate(&onQuit)
 			sessionsApp.Application.AddAction(quitAction)
 
+			preferencesAction := gio.NewSimpleAction("preferences", nil)
+			onPreferences := func(gio.SimpleAction, uintptr) {
+				builder := gtk.NewBuilderFromResource(resources.ResourcePreferencesDialogUIPath)
+				defer builder.Unref()
+
+				// The dialog is built again every time it is opened, so its callbacks are released once it is destroyed
+				callbacks := []interface{}{}
+
+				var (
+					preferencesDialog      adw.PreferencesDialog
+					runningNotificationRow adw.SwitchRow
+					doNotDisturbRow        adw.SwitchRow
+					preventSuspendRow      adw.SwitchRow
+					pauseWhenIdleRow       adw.SwitchRow
+					idleThresholdRow       adw.SpinRow
+					commandTimeoutRow      adw.SpinRow
+					webhookURLsRow         adw.EntryRow
+					webhookSecretRow       adw.PasswordEntryRow
+					apiEnabledRow          adw.SwitchRow
+					apiAddressRow          adw.EntryRow
+					apiTokenRow            adw.PasswordEntryRow
+					taskFileRow            adw.EntryRow
+					taskFileButton         gtk.Button
+					goalPeriodRow          adw.ComboRow
+					goalSessionsRow        adw.SpinRow
+					goalFocusedTimeRow     adw.SpinRow
+					autoStartBreaksRow     adw.SwitchRow
+					autoStartWorkRow       adw.SwitchRow
+					breakDurationRow       adw.SpinRow
+					gracePeriodRow         adw.SpinRow
+					breakOverlayRow        adw.SwitchRow
+					breakStrictnessRow     adw.ComboRow
+				)
+				builder.GetObject("preferences_dialog").Cast(&preferencesDialog)
+				builder.GetObject("running_notification_row").Cast(&runningNotificationRow)
+				builder.GetObject("do_not_disturb_row").Cast(&doNotDisturbRow)
+				builder.GetObject("prevent_suspend_row").Cast(&preventSuspendRow)
+				builder.GetObject("pause_when_idle_row").Cast(&pauseWhenIdleRow)
+				builder.GetObject("idle_threshold_row").Cast(&idleThresholdRow)
+				builder.GetObject("command_timeout_row").Cast(&commandTimeoutRow)
+				builder.GetObject("webhook_urls_row").Cast(&webhookURLsRow)
+				builder.GetObject("webhook_secret_row").Cast(&webhookSecretRow)
+				builder.GetObject("api_enabled_row").Cast(&apiEnabledRow)
+				builder.GetObject("api_address_row").Cast(&apiAddressRow)
+				builder.GetObject("api_token_row").Cast(&apiTokenRow)
+				builder.GetObject("task_file_row").Cast(&taskFileRow)
+				builder.GetObject("task_file_button").Cast(&taskFileButton)
+				builder.GetObject("goal_period_row").Cast(&goalPeriodRow)
+				builder.GetObject("goal_sessions_row").Cast(&goalSessionsRow)
+				builder.GetObject("goal_focused_time_row").Cast(&goalFocusedTimeRow)
+				builder.GetObject("auto_start_breaks_row").Cast(&autoStartBreaksRow)
+				builder.GetObject("auto_start_work_row").Cast(&autoStartWorkRow)
+				builder.GetObject("break_duration_row").Cast(&breakDurationRow)
+				builder.GetObject("auto_start_grace_period_row").Cast(&gracePeriodRow)
+				builder.GetObject("break_overlay_row").Cast(&breakOverlayRow)
+				builder.GetObject("break_strictness_row").Cast(&breakStrictnessRow)
+
+				sessionsApp.settings.Bind(resources.SchemaShowRunningNotificationKey, &runningNotificationRow.Object, "active", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaDoNotDisturbKey, &doNotDisturbRow.Object, "active", gio.GSettingsBindDefaultValue)
+				doNotDisturbRow.SetVisible(doNotDisturbSupported())
+				sessionsApp.settings.Bind(resources.SchemaPreventSuspendKey, &preventSuspendRow.Object, "active", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaPauseWhenIdleKey, &pauseWhenIdleRow.Object, "active", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaIdleThresholdKey, &idleThresholdRow.Object, "value", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaCommandTimeoutKey, &commandTimeoutRow.Object, "value", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaAutoStartBreaksKey, &autoStartBreaksRow.Object, "active", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaAutoStartWorkKey, &autoStartWorkRow.Object, "active", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaBreakDurationKey, &breakDurationRow.Object, "value", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaAutoStartGracePeriodKey, &gracePeriodRow.Object, "value", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaBreakOverlayKey, &breakOverlayRow.Object, "active", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaGoalSessionsKey, &goalSessionsRow.Object, "value", gio.GSettingsBindDefaultValue)
+				sessionsApp.settings.Bind(resources.SchemaGoalFocusedTimeKey, &goalFocusedTimeRow.Object, "value", gio.GSettingsBindDefaultValue)
+
+				// Choices can't be bound to a selected item, so we convert them ourselves
+				goalPeriods := []goals.Period{goals.PeriodDay, goals.PeriodWeek}
+				goalPeriodRow.SetSelected(uint32(max(0, slices.Index(goalPeriods, goals.Period(sessionsApp.settings.GetString(resources.SchemaGoalPeriodKey))))))
+				onGoalPeriodRowNotify := func(_ gobject.Object, pspec uintptr) {
+					if gobject.ParamSpecNewFromInternalPtr(pspec).GetName() != "selected" {
+						return
+					}
+
+					if selected := int(goalPeriodRow.GetSelected()); selected < len(goalPeriods) {
+						sessionsApp.settings.SetString(resources.SchemaGoalPeriodKey, string(goalPeriods[selected]))
+					}
+				}
+				callbacks = append(callbacks, &onGoalPeriodRowNotify)
+				goalPeriodRow.ConnectNotify(&onGoalPeriodRowNotify)
+
+				breakStrictnessRow.SetSelected(uint32(max(0, slices.Index(phases.Strictnesses, phases.Strictness(sessionsApp.settings.GetString(resources.SchemaBreakStrictnessKey))))))
+				onBreakStrictnessRowNotify := func(_ gobject.Object, pspec uintptr) {
+					if gobject.ParamSpecNewFromInternalPtr(pspec).GetName() != "selected" {
+						return
+					}
+
+					if selected := int(breakStrictnessRow.GetSelected()); selected < len(phases.Strictnesses) {
+						sessionsApp.settings.SetString(resources.SchemaBreakStrictnessKey, string(phases.Strictnesses[selected]))
+					}
+				}
+				callbacks = append(callbacks, &onBreakStrictnessRowNotify)
+				breakStrictnessRow.ConnectNotify(&onBreakStrictnessRowNotify)
+
+				for id, key := range map[string]string{
+					"command_on_start_row":       resources.SchemaCommandOnStartKey,
+					"command_on_stop_row":        resources.SchemaCommandOnStopKey,
+					"command_on_alarm_start_row": resources.SchemaCommandOnAlarmStartKey,
+					"command_on_alarm_stop_row":  resources.SchemaCommandOnAlarmStopKey,
+				} {
+					var row adw.EntryRow
+					builder.GetObject(id).Cast(&row)
+
+					sessionsApp.settings.Bind(key, &row.Object, "text", gio.GSettingsBindDefaultValue)
+				}
+
+				sessionsApp.settings.Bind(resources.SchemaWebhookSecretKey, &webhookSecretRow.Object, "text", gio.GSettingsBindDefaultValue)
+
+				// Lists can't be bound to a text property, so we convert them ourselves
+				webhookURLsRow.SetText(strings.Join(sessionsApp.settings.GetStrv(resources.SchemaWebhookURLsKey), ", "))
+				onApplyWebhookURLs := func(row adw.EntryRow) {
+					urls := strings.FieldsFunc(row.GetText(), func(r rune) bool {
+						return r == ',' || r == ' '
+					})
+
+					sessionsApp.settings.SetStrv(resources.SchemaWebhookURLsKey, urls)
+				}
+				callbacks = append(callbacks, &onApplyWebhookURLs)
+				webhookURLsRow.ConnectApply(&onApplyWebhookURLs)
+
+				sessionsApp.settings.Bind(resources.SchemaAPIEnabledKey, &apiEnabledRow.Object, "active", gio.GSettingsBindDefaultValue)
+
+				// The server restarts when these change, so we only store them once they are applied
+				apiAddressRow.SetText(sessionsApp.settings.GetString(resources.SchemaAPIAddressKey))
+				onApplyAPIAddress := func(row adw.EntryRow) {
+					sessionsApp.settings.SetString(resources.SchemaAPIAddressKey, strings.TrimSpace(row.GetText()))
+				}
+				callbacks = append(callbacks, &onApplyAPIAddress)
+				apiAddressRow.ConnectApply(&onApplyAPIAddress)
+
+				apiTokenRow.SetText(sessionsApp.settings.GetString(resources.SchemaAPITokenKey))
+				onApplyAPIToken := func(row adw.EntryRow) {
+					sessionsApp.settings.SetString(resources.SchemaAPITokenKey, strings.TrimSpace(row.GetText()))
+
+					// Show the token that was generated if the row was cleared
+					row.SetText(sessionsApp.settings.GetString(resources.SchemaAPITokenKey))
+				}
+				callbacks = append(callbacks, &onApplyAPIToken)
+				apiTokenRow.ConnectApply(&onApplyAPIToken)
+
+				// The tasks are read again when the file changes, so we only store it once it is applied
+				taskFileRow.SetText(sessionsApp.settings.GetString(resources.SchemaTaskFileKey))
+				onApplyTaskFile := func(row adw.EntryRow) {
+					sessionsApp.settings.SetString(resources.SchemaTaskFileKey, strings.TrimSpace(row.GetText()))
+				}
+				callbacks = append(callbacks, &onApplyTaskFile)
+				taskFileRow.ConnectApply(&onApplyTaskFile)
+
+				onTaskFileButtonClicked := func(gtk.Button) {
+					// The file chooser blocks until a file was chosen, so we don't open it on the main thread
+					go func() {
+						uris, err := filechooser.OpenFile("", L("Choose Task File"), &filechooser.OpenFileOptions{
+							Filters: []*filechooser.Filter{
+								{
+									Name: L("Task Files"),
+									Rules: []filechooser.Rule{
+										{Type: filechooser.GlobPattern, Pattern: "*.txt"},
+										{Type: filechooser.GlobPattern, Pattern: "*.md"},
+										{Type: filechooser.GlobPattern, Pattern: "*.markdown"},
+									},
+								},
+							},
+						})
+						if err != nil {
+							sessionsApp.log.Error("Could not choose task file via file chooser portal", "err", err)
+
+							return
+						}
+
+						if len(uris) == 0 { // The file chooser was cancelled
+							return
+						}
+
+						u, err := url.Parse(uris[0])
+						if err != nil {
+							sessionsApp.log.Error("Could not parse chosen task file", "err", err)
+
+							return
+						}
+
+						var fn glib.SourceFunc
+						fn = glib.SourceFunc(func(uintptr) bool {
+							defer glib.UnrefCallback(&fn)
+
+							taskFileRow.SetText(u.Path)
+							sessionsApp.settings.SetString(resources.SchemaTaskFileKey, u.Path)
+
+							return false
+						})
+						glib.IdleAdd(&fn, 0)
+					}()
+				}
+				callbacks = append(callbacks, &onTaskFileButtonClicked)
+				taskFileButton.ConnectClicked(&onTaskFileButtonClicked)
+
+				var cleanupCallback glib.DestroyNotify
+				cleanupCallback = func(data uintptr) {
+					defer glib.UnrefCallback(&cleanupCallback)
+
+					for _, callback := range callbacks {
+						if err := glib.UnrefCallback(callback); err != nil {
+							sessionsApp.log.Error("Could not unref callback", "err", err)
+						}
+					}
+				}
+				preferencesDialog.SetDataFull(dataKeyCallbacks, uintptr(unsafe.Pointer(&callbacks)), &cleanupCallback)
+
+				preferencesDialog.Present(&sessionsApp.window.ApplicationWindow.Widget)
+			}
+			preferencesAction.ConnectActivate(&onPreferences)
+			sessionsApp.Application.AddAction(preferencesAction)
+
+			sessionsApp.Application.SetAccelsForAction("app.preferences", []string{`<Primary>comma`})
 			sessionsApp.Application.SetAccelsForAction("app.shortcuts", []string{`<Primary>question`})
 			sessionsApp.Application.SetAccelsForAction("app.quit", []string{`<Primary>q`})
 
 			sessionsApp.Application.AddWindow(&sessionsApp.window.ApplicationWindow.Window)
 			sessionsApp.window.ApplicationWindow.Present()
+
+			if sessionsApp.pendingDuration != 0 {
+				sessionsApp.Application.ActivateAction("setDuration", glib.NewVariantInt64(int64(sessionsApp.pendingDuration.Seconds())))
+
+				sessionsApp.pendingDuration = 0
+			}
+		})
+
+		applicationClass.OverrideShutdown(func(a *gio.Application) {
+			sessionsApp := (*Application)(unsafe.Pointer(a.GetData(dataKeyGoInstance)))
+
+			// Other apps' notifications would stay hidden until the next start otherwise
+			if sessionsApp.window != nil {
+				sessionsApp.window.releaseNotifications()
+
+				// Unix sockets would stay behind otherwise
+				sessionsApp.window.stopAPI()
+			}
+
+			parentApplicationClass := (*gio.ApplicationClass)(unsafe.Pointer(tc.PeekParent()))
+
+			parentApplicationClass.GetShutdown()(a)
 		})
 	}
 
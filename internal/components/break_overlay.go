@@ -0,0 +1,93 @@
+package components
+
+import (
+	"log/slog"
+
+	"codeberg.org/puregotk/puregotk/v4/adw"
+	"codeberg.org/puregotk/puregotk/v4/gobject"
+	"codeberg.org/puregotk/puregotk/v4/gtk"
+	"github.com/pojntfx/sessions/assets/resources"
+)
+
+// breakOverlay covers the screen with the countdown of a break until it is skipped, postponed or over
+type breakOverlay struct {
+	window adw.Window
+
+	// Whether we are hiding the overlay ourselves, since users can't close it
+	hiding bool
+
+	callbacks []interface{}
+}
+
+// newBreakOverlay creates an overlay that mirrors the remaining time of a dial
+func newBreakOverlay(app *adw.Application, log *slog.Logger, source *Dial) *breakOverlay {
+	overlay := &breakOverlay{}
+
+	builder := gtk.NewBuilderFromResource(resources.ResourceBreakOverlayUIPath)
+	defer builder.Unref()
+
+	var (
+		dialArea  gtk.Box
+		timeLabel gtk.Label
+	)
+	builder.GetObject("break_overlay").Cast(&overlay.window)
+	builder.GetObject("break_overlay_dial_area").Cast(&dialArea)
+	builder.GetObject("break_overlay_time_label").Cast(&timeLabel)
+
+	overlay.window.SetApplication(&app.Application)
+	overlay.window.SetHideOnClose(true)
+
+	// The countdown can only be changed in the main window
+	dial := NewDial(app, log, "css-name")
+	dial.Widget.SetHexpand(true)
+	dial.Widget.SetVexpand(true)
+	dial.Widget.SetCanTarget(false)
+	dial.Widget.SetCanFocus(false)
+	dialArea.Append(&dial.Widget)
+
+	for _, property := range []string{propertyDialRemainingTime, propertyDialCountingDown, propertyDialShowLabels} {
+		source.Widget.Object.BindProperty(property, &dial.Widget.Object, property, gobject.GBindingSyncCreateValue)
+	}
+
+	var remainingToLabel gobject.BindingTransformFunc = func(_ uintptr, from *gobject.Value, to *gobject.Value, _ uintptr) bool {
+		to.SetString(formatRemainingTime(int(from.GetInt())))
+
+		return true
+	}
+	overlay.callbacks = append(overlay.callbacks, &remainingToLabel)
+	source.Widget.Object.BindPropertyFull(
+		propertyDialRemainingTime,
+		&timeLabel.Widget.Object,
+		"label",
+		gobject.GBindingSyncCreateValue,
+		&remainingToLabel,
+		nil,
+		0,
+		nil,
+	)
+
+	onCloseRequest := func(gtk.Window) bool {
+		// Breaks have to be skipped or postponed explicitly
+		return !overlay.hiding
+	}
+	overlay.callbacks = append(overlay.callbacks, &onCloseRequest)
+	overlay.window.ConnectCloseRequest(&onCloseRequest)
+
+	return overlay
+}
+
+// Show presents the overlay in fullscreen
+func (o *breakOverlay) Show() {
+	o.window.Fullscreen()
+	o.window.Present()
+}
+
+// Hide hides the overlay if it is shown
+func (o *breakOverlay) Hide() {
+	o.hiding = true
+	defer func() {
+		o.hiding = false
+	}()
+
+	o.window.Close()
+}
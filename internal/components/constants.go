@@ -2,4 +2,5 @@ package components
 
 const (
 	dataKeyGoInstance = "go_instance"
+	dataKeyCallbacks  = "callbacks"
 )
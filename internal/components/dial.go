@@ -1,9 +1,11 @@
 package components
 
 import (
+	"fmt"
 	"log/slog"
 	"math"
 	"runtime"
+	"time"
 	"unsafe"
 
 	"codeberg.org/puregotk/purego"
@@ -15,18 +17,33 @@ import (
 	"codeberg.org/puregotk/puregotk/v4/graphene"
 	"codeberg.org/puregotk/puregotk/v4/gsk"
 	"codeberg.org/puregotk/puregotk/v4/gtk"
+	"github.com/pojntfx/sessions/pkg/geometry"
+	"github.com/pojntfx/sessions/pkg/render"
 	"github.com/pojntfx/sessions/pkg/state"
 )
 
 const (
-	signalDialDragBegin = "drag-begin"
-	signalDialDragEnd   = "drag-end"
+	// Number of adjustment intervals that PageUp and PageDown move the dial by
+	dialPageIntervals = 10
+
+	// Number of surface pixels that touchpads need to scroll to move the dial by one adjustment interval
+	dialScrollPixelsPerInterval = 20.0
+	// How far ahead kinetic touchpad scrolling continues after lifting the fingers
+	dialScrollKineticDuration = time.Millisecond * 150
+	// How long to wait after the last scroll event before applying the new remaining time
+	dialScrollDebounceInterval = time.Millisecond * 300
+
+	signalDialDragBegin  = "drag-begin"
+	signalDialDragEnd    = "drag-end"
+	signalDialAdjustTime = "adjust-time"
 
 	propertyDialRemainingTime = "remaining-time"
 	propertyDialCountingDown  = "counting-down"
+	propertyDialShowLabels    = "show-labels"
 
 	propertyIdDialRemainingTime uint32 = 1
 	propertyIdDialCountingDown  uint32 = 2
+	propertyIdDialShowLabels    uint32 = 3
 )
 
 var (
@@ -41,6 +58,11 @@ type Dial struct {
 	app           *adw.Application
 	remainingTime int
 	countingDown  bool
+	showLabels    bool
+
+	scrollDelta          float64
+	scrollIntervals      int
+	scrollDebounceSource uint32
 
 	callbacks []interface{}
 }
@@ -101,8 +123,109 @@ func NewDial(app *adw.Application, log *slog.Logger, FirstPropertyNameVar string
 	dial.callbacks = append(dial.callbacks, &onPress)
 	click.ConnectPressed(&onPress)
 
+	key := gtk.NewEventControllerKey()
+	onKeyPressed := func(_ gtk.EventControllerKey, keyval uint32, _ uint32, _ gdk.ModifierType) bool {
+		remainingTime := time.Duration(v.GetRemainingTime()) * time.Second
+
+		switch int32(keyval) {
+		case gdk.KEY_Left, gdk.KEY_KP_Left, gdk.KEY_Down, gdk.KEY_KP_Down:
+			remainingTime = adjustRemainingTime(remainingTime, -1)
+		case gdk.KEY_Right, gdk.KEY_KP_Right, gdk.KEY_Up, gdk.KEY_KP_Up:
+			remainingTime = adjustRemainingTime(remainingTime, 1)
+		case gdk.KEY_Page_Down, gdk.KEY_KP_Page_Down:
+			remainingTime = adjustRemainingTime(remainingTime, -dialPageIntervals)
+		case gdk.KEY_Page_Up, gdk.KEY_KP_Page_Up:
+			remainingTime = adjustRemainingTime(remainingTime, dialPageIntervals)
+		case gdk.KEY_Home, gdk.KEY_KP_Home:
+			remainingTime = state.MinInitialRemainingTime
+		case gdk.KEY_End, gdk.KEY_KP_End:
+			remainingTime = state.MaxInitialRemainingTime
+		default:
+			return false
+		}
+
+		// We go through the same signals as when dragging so that the state machine
+		// validates and applies the new remaining time
+		gobject.SignalEmit(obj, gobject.SignalLookup(signalDialDragBegin, gTypeDial), 0)
+		v.SetRemainingTime(int(remainingTime.Seconds()))
+		gobject.SignalEmit(obj, gobject.SignalLookup(signalDialDragEnd, gTypeDial), 0)
+
+		return true
+	}
+	dial.callbacks = append(dial.callbacks, &onKeyPressed)
+	key.ConnectKeyPressed(&onKeyPressed)
+
+	// Scrolling adjusts the time through `adjust-time` once scrolling has settled, so that the
+	// timer only changes once
+	onScrollDebounced := glib.SourceFunc(func(u uintptr) bool {
+		dial.scrollDebounceSource = 0
+
+		if intervals := dial.scrollIntervals; intervals != 0 {
+			dial.scrollIntervals = 0
+
+			gobject.SignalEmit(obj, gobject.SignalLookup(signalDialAdjustTime, gTypeDial), 0, int32(intervals))
+		}
+
+		return false
+	})
+	dial.callbacks = append(dial.callbacks, &onScrollDebounced)
+
+	adjustRemainingTimeFromScroll := func(delta float64) {
+		dial.scrollDelta += delta
+
+		intervals := int(dial.scrollDelta)
+		if intervals == 0 {
+			return
+		}
+		dial.scrollDelta -= float64(intervals)
+
+		dial.scrollIntervals += intervals
+
+		// The time that is counting down would be overwritten by the next tick, so we only preview it otherwise
+		if !v.GetCountingDown() {
+			v.SetRemainingTime(int(adjustRemainingTime(time.Duration(v.GetRemainingTime())*time.Second, intervals).Seconds()))
+		}
+
+		if dial.scrollDebounceSource != 0 {
+			glib.SourceRemove(dial.scrollDebounceSource)
+		}
+		dial.scrollDebounceSource = glib.TimeoutAdd(uint32(dialScrollDebounceInterval.Milliseconds()), &onScrollDebounced, 0)
+	}
+
+	scroll := gtk.NewEventControllerScroll(gtk.EventControllerScrollVerticalValue | gtk.EventControllerScrollKineticValue)
+	onScroll := func(scroll gtk.EventControllerScroll, _ float64, dy float64) bool {
+		// Scrolling up increases the remaining time
+		delta := -dy
+		if scroll.GetUnit() == gdk.ScrollUnitSurfaceValue {
+			delta /= dialScrollPixelsPerInterval
+		}
+
+		adjustRemainingTimeFromScroll(delta)
+
+		return true
+	}
+	dial.callbacks = append(dial.callbacks, &onScroll)
+	scroll.ConnectScroll(&onScroll)
+	onDecelerate := func(_ gtk.EventControllerScroll, _ float64, vy float64) {
+		adjustRemainingTimeFromScroll(-vy * dialScrollKineticDuration.Seconds() / dialScrollPixelsPerInterval)
+	}
+	dial.callbacks = append(dial.callbacks, &onDecelerate)
+	scroll.ConnectDecelerate(&onDecelerate)
+
 	v.Widget.AddController(&drag.EventController)
 	v.Widget.AddController(&click.Gesture.EventController)
+	v.Widget.AddController(&key.EventController)
+	v.Widget.AddController(&scroll.EventController)
+
+	// Redraw when the widget gains or loses focus so that the focus ring is updated
+	onStateFlagsChanged := func(_ gtk.Widget, _ gtk.StateFlags) {
+		v.Widget.QueueDraw()
+	}
+	dial.callbacks = append(dial.callbacks, &onStateFlagsChanged)
+	v.Widget.ConnectStateFlagsChanged(&onStateFlagsChanged)
+
+	v.Widget.SetFocusable(true)
+	dial.updateAccessibleValue()
 
 	return v
 }
@@ -135,6 +258,20 @@ func (x *Dial) ConnectDragEnd(cb *func()) uint32 {
 	return gobject.SignalConnect(x.GoPointer(), signalDialDragEnd, cbRefPtr)
 }
 
+func (x *Dial) ConnectAdjustTime(cb *func(int)) uint32 {
+	cbPtr := uintptr(unsafe.Pointer(cb))
+	if cbRefPtr, ok := glib.GetCallback(cbPtr); ok {
+		return gobject.SignalConnect(x.GoPointer(), signalDialAdjustTime, cbRefPtr)
+	}
+
+	fcb := func(_ uintptr, intervals int32) {
+		(*cb)(int(intervals))
+	}
+	cbRefPtr := purego.NewCallback(fcb)
+	glib.SaveCallback(cbPtr, cbRefPtr)
+	return gobject.SignalConnect(x.GoPointer(), signalDialAdjustTime, cbRefPtr)
+}
+
 func (d *Dial) SetRemainingTime(remainingTime int) {
 	var val gobject.Value
 	val.Init(types.GType(gobject.TypeIntVal))
@@ -169,26 +306,154 @@ func (d *Dial) GetCountingDown() bool {
 	return countingDown
 }
 
-func (d *Dial) positionToRemainingTime(x, y float64) (int, bool) {
-	width, height := float64(d.Widget.GetWidth()), float64(d.Widget.GetHeight())
-	cx, cy := width/2, height/2
-	dx, dy := x-cx, y-cy
+func (d *Dial) updateAccessibleValue() {
+	var minValue, maxValue, nowValue, textValue gobject.Value
+	minValue.Init(types.GType(gobject.TypeDoubleVal))
+	minValue.SetDouble(state.MinInitialRemainingTime.Seconds())
+	maxValue.Init(types.GType(gobject.TypeDoubleVal))
+	maxValue.SetDouble(state.MaxInitialRemainingTime.Seconds())
+	nowValue.Init(types.GType(gobject.TypeDoubleVal))
+	nowValue.SetDouble(float64(d.remainingTime))
+	textValue.Init(types.GType(gobject.TypeStringVal))
+	textValue.SetString(fmt.Sprintf("%02d:%02d", d.remainingTime/60, d.remainingTime%60))
+
+	d.Widget.UpdatePropertyValue(
+		4,
+		[]gtk.AccessibleProperty{
+			gtk.AccessiblePropertyValueMinValue,
+			gtk.AccessiblePropertyValueMaxValue,
+			gtk.AccessiblePropertyValueNowValue,
+			gtk.AccessiblePropertyValueTextValue,
+		},
+		[]gobject.Value{minValue, maxValue, nowValue, textValue},
+	)
+
+	minValue.Unset()
+	maxValue.Unset()
+	nowValue.Unset()
+	textValue.Unset()
+}
+
+// adjustRemainingTime snaps the remaining time to the nearest adjustment interval before
+// adding the intervals, and clamps the result to the valid initial remaining times
+func adjustRemainingTime(remainingTime time.Duration, intervalsToAdd int) time.Duration {
+	remainingTime = remainingTime.Round(state.RemainingTimerAdjustmentInterval) + time.Duration(intervalsToAdd)*state.RemainingTimerAdjustmentInterval
+
+	return min(max(remainingTime, state.MinInitialRemainingTime), state.MaxInitialRemainingTime)
+}
 
-	if math.Sqrt(dx*dx+dy*dy) < 15 {
+func (d *Dial) positionToRemainingTime(x, y float64) (int, bool) {
+	remainingTime, ok := geometry.PositionToRemainingTime(float64(d.Widget.GetWidth()), float64(d.Widget.GetHeight()), x, y)
+	if !ok {
 		return 0, false
 	}
 
-	a := math.Atan2(dy, dx) + math.Pi/2
-	if a < 0 {
-		a += 2 * math.Pi
+	return int(remainingTime.Seconds()), true
+}
+
+// lookupPalette resolves the colors of the dial from the widget's style
+func lookupPalette(widget *gtk.Widget, dark bool) render.Palette {
+	// The track colours are sampled manually since they are slightly lighter than the button colours
+	palette := render.LightPalette
+	if dark {
+		palette = render.DarkPalette
 	}
 
-	intervals := int((a / (2 * math.Pi)) * 120)
-	if intervals == 0 {
-		intervals = 120
+	styleContext := widget.GetStyleContext()
+	for name, color := range map[string]*render.Color{
+		"accent_bg_color":   &palette.Accent,
+		"error_color":       &palette.Error,
+		"destructive_color": &palette.Destructive,
+		"window_bg_color":   &palette.WindowBackground,
+		"window_fg_color":   &palette.WindowForeground,
+	} {
+		var rgba gdk.RGBA
+		if styleContext.LookupColor(name, &rgba) {
+			*color = render.Color{R: float64(rgba.Red), G: float64(rgba.Green), B: float64(rgba.Blue), A: float64(rgba.Alpha)}
+		}
+	}
+
+	return palette
+}
+
+func toGdkRGBA(color render.Color) gdk.RGBA {
+	return gdk.RGBA{Red: float32(color.R), Green: float32(color.G), Blue: float32(color.B), Alpha: float32(color.A)}
+}
+
+func toGskPath(path render.Path) *gsk.Path {
+	builder := gsk.NewPathBuilder()
+	defer builder.Unref()
+
+	for _, segment := range path.Segments {
+		switch segment.Kind {
+		case render.SegmentMoveTo:
+			builder.MoveTo(float32(segment.X), float32(segment.Y))
+
+		case render.SegmentLineTo:
+			builder.LineTo(float32(segment.X), float32(segment.Y))
+
+		case render.SegmentArc:
+			endX, endY := geometry.AngleToPosition(segment.X, segment.Y, segment.R, segment.EndAngle)
+
+			builder.SvgArcTo(
+				float32(segment.R),
+				float32(segment.R),
+				0,
+				math.Abs(segment.EndAngle-segment.StartAngle) > math.Pi,
+				segment.EndAngle > segment.StartAngle,
+				float32(endX),
+				float32(endY),
+			)
+
+		case render.SegmentCircle:
+			builder.AddCircle(&graphene.Point{X: float32(segment.X), Y: float32(segment.Y)}, float32(segment.R))
+		}
 	}
 
-	return intervals * int(state.MinInitialRemainingTime.Seconds()), true
+	return builder.ToPath()
+}
+
+// appendSceneToSnapshot draws the scene with GSK. Text uses the widget's font instead of the
+// font size of the scene so that it follows the system's text scaling.
+func appendSceneToSnapshot(widget *gtk.Widget, snapshot *gtk.Snapshot, ops []render.Op) {
+	for _, op := range ops {
+		color := toGdkRGBA(op.Color)
+
+		switch op.Kind {
+		case render.OpFill:
+			path := toGskPath(op.Path)
+			snapshot.AppendFill(path, gsk.FillRuleWindingValue, &color)
+			path.Unref()
+
+		case render.OpStroke:
+			path := toGskPath(op.Path)
+			stroke := gsk.NewStroke(float32(op.LineWidth))
+			if op.RoundCap {
+				stroke.SetLineCap(gsk.LineCapRoundValue)
+			}
+
+			snapshot.AppendStroke(path, stroke, &color)
+
+			stroke.Free()
+			path.Unref()
+
+		case render.OpText:
+			layout := widget.CreatePangoLayout(op.Text)
+
+			var labelWidth, labelHeight int32
+			layout.GetPixelSize(&labelWidth, &labelHeight)
+
+			snapshot.Save()
+			snapshot.Translate(&graphene.Point{
+				X: float32(op.X) - float32(labelWidth)/2,
+				Y: float32(op.Y) - float32(labelHeight)/2,
+			})
+			snapshot.AppendLayout(layout, &color)
+			snapshot.Restore()
+
+			layout.Unref()
+		}
+	}
 }
 
 func init() {
@@ -219,6 +484,19 @@ func init() {
 			nil,
 		)
 
+		gobject.SignalNewv(
+			signalDialAdjustTime,
+			gTypeDial,
+			gobject.GSignalRunFirstValue,
+			nil,
+			nil,
+			0,
+			nil,
+			types.GType(gobject.TypeNoneVal),
+			1,
+			[]types.GType{types.GType(gobject.TypeIntVal)},
+		)
+
 		objClass := (*gobject.ObjectClass)(unsafe.Pointer(tc))
 
 		objClass.OverrideSetProperty(func(o *gobject.Object, u uint32, v *gobject.Value, ps *gobject.ParamSpec) {
@@ -226,10 +504,14 @@ func init() {
 			switch u {
 			case propertyIdDialRemainingTime:
 				w.remainingTime = int(v.GetInt())
+				w.updateAccessibleValue()
 				w.Widget.QueueDraw()
 			case propertyIdDialCountingDown:
 				w.countingDown = v.GetBoolean()
 				w.Widget.QueueDraw()
+			case propertyIdDialShowLabels:
+				w.showLabels = v.GetBoolean()
+				w.Widget.QueueDraw()
 			}
 		})
 
@@ -240,6 +522,8 @@ func init() {
 				v.SetInt(int32(w.remainingTime))
 			case propertyIdDialCountingDown:
 				v.SetBoolean(w.countingDown)
+			case propertyIdDialShowLabels:
+				v.SetBoolean(w.showLabels)
 			}
 		})
 
@@ -261,6 +545,14 @@ func init() {
 			gobject.GParamReadwriteValue,
 		))
 
+		objClass.InstallProperty(propertyIdDialShowLabels, gobject.NewParamSpecBoolean(
+			propertyDialShowLabels,
+			"Show labels",
+			"Whether to show minute labels next to the major tick marks",
+			false,
+			gobject.GParamReadwriteValue,
+		))
+
 		objClass.OverrideConstructed(func(o *gobject.Object) {
 			parentObjClass := (*gobject.ObjectClass)(unsafe.Pointer(tc.PeekParent()))
 			parentObjClass.GetConstructed()(o)
@@ -293,6 +585,8 @@ func init() {
 
 		widgetClass := (*gtk.WidgetClass)(unsafe.Pointer(tc))
 
+		widgetClass.SetAccessibleRole(gtk.AccessibleRoleSliderValue)
+
 		widgetClass.OverrideSnapshot(func(widget *gtk.Widget, snapshot *gtk.Snapshot) {
 			dialW := (*Dial)(unsafe.Pointer(widget.GetData(dataKeyGoInstance)))
 			if dialW == nil {
@@ -301,194 +595,20 @@ func init() {
 
 			w := float64(widget.GetWidth())
 			h := float64(widget.GetHeight())
-			cx := w / 2
-			cy := h / 2
-			r := math.Min(cx, cy) - 15
-
-			styleContext := widget.GetStyleContext()
-			var accent, errColor, destructiveColor, windowBg gdk.RGBA
-			styleContext.LookupColor("accent_bg_color", &accent)
-			styleContext.LookupColor("error_color", &errColor)
-			styleContext.LookupColor("destructive_color", &destructiveColor)
-			styleContext.LookupColor("window_bg_color", &windowBg)
-
-			// We use manually sampled values these since we a slightly lighter colour than the button colours
-			var trackColor gdk.RGBA
-			if dialW.app.GetStyleManager().GetDark() {
-				trackColor = gdk.RGBA{Red: 0x34 / 255.0, Green: 0x34 / 255.0, Blue: 0x37 / 255.0, Alpha: 1.0}
-			} else {
-				trackColor = gdk.RGBA{Red: 0xD8 / 255.0, Green: 0xD8 / 255.0, Blue: 0xD8 / 255.0, Alpha: 1.0}
-			}
-			if !widget.IsSensitive() {
-				// Matches the text colour of a .destructive-action libadwaita button
-				const disabledOpacity float32 = 0.5
-				a := disabledOpacity * destructiveColor.Alpha
-				trackColor = gdk.RGBA{
-					Red:   destructiveColor.Red*a + windowBg.Red*(1-a),
-					Green: destructiveColor.Green*a + windowBg.Green*(1-a),
-					Blue:  destructiveColor.Blue*a + windowBg.Blue*(1-a),
-					Alpha: 1.0,
-				}
-			}
-
-			highContrast := dialW.app.GetStyleManager().GetHighContrast()
-
-			var borderColor gdk.RGBA
-			if highContrast {
-				styleContext.LookupColor("window_fg_color", &borderColor)
-				borderColor.Alpha = 0.5
-			}
-
-			strokeBorder := func(path *gsk.Path) {
-				if highContrast {
-					stroke := gsk.NewStroke(1.0)
-					defer stroke.Free()
-					snapshot.AppendStroke(path, stroke, &borderColor)
-				}
-			}
-
-			fullCircleBuilder := gsk.NewPathBuilder()
-			defer fullCircleBuilder.Unref()
-			centerPoint := graphene.Point{X: float32(cx), Y: float32(cy)}
-			fullCircleBuilder.AddCircle(&centerPoint, float32(r))
-			fullCirclePath := fullCircleBuilder.ToPath()
-			defer fullCirclePath.Unref()
-			fullCircleStroke := gsk.NewStroke(10.0)
-			defer fullCircleStroke.Free()
-			snapshot.AppendStroke(fullCirclePath, fullCircleStroke, &trackColor)
-
-			if highContrast {
-				outerBorderBuilder := gsk.NewPathBuilder()
-				defer outerBorderBuilder.Unref()
-				outerBorderBuilder.AddCircle(&centerPoint, float32(r)+5)
-				outerBorderPath := outerBorderBuilder.ToPath()
-				defer outerBorderPath.Unref()
-				strokeBorder(outerBorderPath)
-
-				innerBorderBuilder := gsk.NewPathBuilder()
-				defer innerBorderBuilder.Unref()
-				innerBorderBuilder.AddCircle(&centerPoint, float32(r)-5)
-				innerBorderPath := innerBorderBuilder.ToPath()
-				defer innerBorderPath.Unref()
-				strokeBorder(innerBorderPath)
-			}
-
-			// Skip the arc when remaining=0 and we're counting down, else we get a red flash
-			// until we render the non-sensitive colour
-			if widget.IsSensitive() && !(dialW.countingDown && dialW.remainingTime == 0) && (dialW.remainingTime > 0 || dialW.countingDown) {
-				progress := float64(dialW.remainingTime) / state.MaxInitialRemainingTime.Seconds()
-				angle := -math.Pi/2 + 2*math.Pi*progress
-				var lineColor gdk.RGBA
-				var fillR, fillG, fillB, fillA float32
-
-				if dialW.countingDown {
-					lineColor = errColor
-					fillR, fillG, fillB, fillA = errColor.Red, errColor.Green, errColor.Blue, 0.3
-				} else {
-					lineColor = accent
-					fillR, fillG, fillB, fillA = 0.6, 0.6, 0.6, 0.2
-				}
 
-				fillColor := gdk.RGBA{
-					Red:   fillR,
-					Green: fillG,
-					Blue:  fillB,
-					Alpha: fillA,
-				}
-
-				startX := float32(cx)
-				startY := float32(cy - r)
-				endX := float32(cx + r*math.Sin(angle+math.Pi/2))
-				endY := float32(cy - r*math.Cos(angle+math.Pi/2))
-
-				isFullCircle := startX == endX && startY == endY
-				largeArc := angle+math.Pi/2 > math.Pi
-
-				drawArcOrCircle := func(builder *gsk.PathBuilder, rx, ry float32, endX, endY float32) {
-					if isFullCircle {
-						builder.AddCircle(&graphene.Point{X: float32(cx), Y: float32(cy)}, rx)
-					} else {
-						builder.SvgArcTo(rx, ry, 0, largeArc, true, endX, endY)
-					}
-				}
-
-				if isFullCircle {
-					fullFillBuilder := gsk.NewPathBuilder()
-					defer fullFillBuilder.Unref()
-					fullFillBuilder.AddCircle(&graphene.Point{X: float32(cx), Y: float32(cy)}, float32(r))
-					fullFillPath := fullFillBuilder.ToPath()
-					defer fullFillPath.Unref()
-					snapshot.AppendFill(fullFillPath, gsk.FillRuleWindingValue, &fillColor)
-					strokeBorder(fullFillPath)
-				} else {
-					arcBuilder := gsk.NewPathBuilder()
-					defer arcBuilder.Unref()
-					arcBuilder.MoveTo(float32(cx), float32(cy))
-					arcBuilder.LineTo(startX, startY)
-					drawArcOrCircle(arcBuilder, float32(r), float32(r), endX, endY)
-					arcBuilder.LineTo(float32(cx), float32(cy))
-					arcPath := arcBuilder.ToPath()
-					defer arcPath.Unref()
-					snapshot.AppendFill(arcPath, gsk.FillRuleWindingValue, &fillColor)
-					strokeBorder(arcPath)
-				}
-
-				lineStrokeColor := gdk.RGBA{
-					Red:   lineColor.Red,
-					Green: lineColor.Green,
-					Blue:  lineColor.Blue,
-					Alpha: 1.0,
-				}
-
-				arcLineBuilder := gsk.NewPathBuilder()
-				defer arcLineBuilder.Unref()
-				arcLineBuilder.MoveTo(startX, startY)
-				drawArcOrCircle(arcLineBuilder, float32(r), float32(r), endX, endY)
-				arcLinePath := arcLineBuilder.ToPath()
-				defer arcLinePath.Unref()
-				arcStroke := gsk.NewStroke(10.0)
-				defer arcStroke.Free()
-				arcStroke.SetLineCap(gsk.LineCapRoundValue)
-				snapshot.AppendStroke(arcLinePath, arcStroke, &lineStrokeColor)
-
-				if highContrast {
-					startCapBuilder := gsk.NewPathBuilder()
-					defer startCapBuilder.Unref()
-					startCapBuilder.MoveTo(float32(cx), float32(cy-(r+5)))
-					startCapBuilder.SvgArcTo(5, 5, 0, false, false, float32(cx), float32(cy-(r-5)))
-					startCapPath := startCapBuilder.ToPath()
-					defer startCapPath.Unref()
-					strokeBorder(startCapPath)
-
-					outerArcBuilder := gsk.NewPathBuilder()
-					defer outerArcBuilder.Unref()
-					outerArcBuilder.MoveTo(float32(cx), float32(cy-(r+5)))
-					drawArcOrCircle(outerArcBuilder, float32(r)+5, float32(r)+5, float32(cx+(r+5)*math.Sin(angle+math.Pi/2)), float32(cy-(r+5)*math.Cos(angle+math.Pi/2)))
-					outerArcPath := outerArcBuilder.ToPath()
-					defer outerArcPath.Unref()
-					strokeBorder(outerArcPath)
-
-					innerArcBuilder := gsk.NewPathBuilder()
-					defer innerArcBuilder.Unref()
-					innerArcBuilder.MoveTo(float32(cx), float32(cy-(r-5)))
-					drawArcOrCircle(innerArcBuilder, float32(r)-5, float32(r)-5, float32(cx+(r-5)*math.Sin(angle+math.Pi/2)), float32(cy-(r-5)*math.Cos(angle+math.Pi/2)))
-					innerArcPath := innerArcBuilder.ToPath()
-					defer innerArcPath.Unref()
-					strokeBorder(innerArcPath)
-				}
-
-				handleX := float32(cx + r*math.Cos(angle))
-				handleY := float32(cy + r*math.Sin(angle))
-
-				handleBuilder := gsk.NewPathBuilder()
-				defer handleBuilder.Unref()
-				handlePoint := graphene.Point{X: handleX, Y: handleY}
-				handleBuilder.AddCircle(&handlePoint, 8)
-				handlePath := handleBuilder.ToPath()
-				defer handlePath.Unref()
-				snapshot.AppendFill(handlePath, gsk.FillRuleWindingValue, &lineColor)
-				strokeBorder(handlePath)
-			}
+			styleManager := dialW.app.GetStyleManager()
+
+			appendSceneToSnapshot(widget, snapshot, render.Scene(render.Options{
+				Width:         w,
+				Height:        h,
+				RemainingTime: time.Duration(dialW.remainingTime) * time.Second,
+				CountingDown:  dialW.countingDown,
+				Disabled:      !widget.IsSensitive(),
+				Focused:       widget.HasVisibleFocus(),
+				HighContrast:  styleManager.GetHighContrast(),
+				ShowLabels:    dialW.showLabels,
+				Palette:       lookupPalette(widget, styleManager.GetDark()),
+			}))
 		})
 	}
 
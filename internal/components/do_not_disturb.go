@@ -0,0 +1,70 @@
+package components
+
+import (
+	"errors"
+	"os"
+
+	"codeberg.org/puregotk/puregotk/v4/gio"
+)
+
+const (
+	notificationsSchemaID  = "org.gnome.desktop.notifications"
+	notificationsBannerKey = "show-banners"
+
+	// Only exists in Flatpak sandboxes
+	flatpakInfoPath = "/.flatpak-info"
+)
+
+var (
+	errNotificationsSchemaMissing = errors.New("notifications schema is not installed")
+	errSandboxed                  = errors.New("notification settings can't be changed from within a sandbox")
+	errCouldNotSetShowBanners     = errors.New("could not set show banners setting")
+)
+
+// notificationSettingsBackend toggles notification banners through GNOME's notification settings
+type notificationSettingsBackend struct {
+	settings *gio.Settings
+}
+
+// doNotDisturbSupported returns whether we can change the notification settings. Sandboxes
+// would need write access to all of dconf for this, so we don't support them.
+func doNotDisturbSupported() bool {
+	_, err := os.Stat(flatpakInfoPath)
+
+	return err != nil
+}
+
+func newNotificationSettingsBackend() (*notificationSettingsBackend, error) {
+	if !doNotDisturbSupported() {
+		return nil, errSandboxed
+	}
+
+	// Creating settings for a schema that isn't installed aborts the process, so we need to check first
+	source := gio.SettingsSchemaSourceGetDefault()
+	if source == nil {
+		return nil, errNotificationsSchemaMissing
+	}
+
+	schema := source.Lookup(notificationsSchemaID, true)
+	if schema == nil {
+		return nil, errNotificationsSchemaMissing
+	}
+	schema.Unref()
+
+	return &notificationSettingsBackend{gio.NewSettings(notificationsSchemaID)}, nil
+}
+
+func (b *notificationSettingsBackend) ShowBanners() (bool, error) {
+	return b.settings.GetBoolean(notificationsBannerKey), nil
+}
+
+func (b *notificationSettingsBackend) SetShowBanners(showBanners bool) error {
+	if !b.settings.SetBoolean(notificationsBannerKey, showBanners) {
+		return errCouldNotSetShowBanners
+	}
+
+	// We might exit right after restoring the previous value, so we need to make sure it is written
+	gio.SettingsSync()
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package components
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"codeberg.org/puregotk/puregotk/v4/glib"
+	. "github.com/pojntfx/go-gettext/pkg/i18n"
+	"github.com/pojntfx/sessions/assets/resources"
+	"github.com/pojntfx/sessions/pkg/export"
+	"github.com/pojntfx/sessions/pkg/history"
+	"github.com/rymdport/portal/filechooser"
+)
+
+const (
+	// ExportCommand is the command line argument that exports the history instead of starting the app
+	ExportCommand = "export"
+
+	exportSinceLayout = "2006-01-02"
+)
+
+func historyPath() string {
+	return filepath.Join(glib.GetUserStateDir(), resources.AppID, "history.jsonl")
+}
+
+// untitledSession is the calendar event summary for sessions without a task
+func untitledSession() string {
+	// TRANSLATORS: Title of calendar events for exported sessions that weren't for a task.
+	return L("Focus Session")
+}
+
+// RunExport exports the history from the command line, e.g. with `export --format ics --since 2026-10-01`,
+// and returns the exit code
+func RunExport(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet(ExportCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var (
+		// TRANSLATORS: Description of the command line option that sets the format of the exported history.
+		formatFlag = flags.String("format", string(export.FormatCSV), L("Format to export the history in, one of csv, json or ics"))
+		// TRANSLATORS: Description of the command line option that only exports sessions since a date.
+		sinceFlag = flags.String("since", "", L("Only export sessions that started on or after this date, e.g. 2026-10-01"))
+		// TRANSLATORS: Description of the command line option that sets the file to export the history to.
+		outputFlag = flags.String("output", "-", L("File to export the history to, or - for standard output"))
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	format, err := export.ParseFormat(*formatFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, L("Invalid format: %v")+"\n", err)
+
+		return 2
+	}
+
+	sessions, err := history.NewLog(historyPath()).Read()
+	if err != nil {
+		fmt.Fprintf(stderr, L("Could not read history: %v")+"\n", err)
+
+		return 1
+	}
+
+	if *sinceFlag != "" {
+		since, err := time.ParseInLocation(exportSinceLayout, *sinceFlag, time.Local)
+		if err != nil {
+			fmt.Fprintf(stderr, L("Invalid date: %v")+"\n", err)
+
+			return 2
+		}
+
+		sessions = export.Since(sessions, since)
+	}
+
+	out := stdout
+	if *outputFlag != "-" {
+		f, err := os.Create(*outputFlag)
+		if err != nil {
+			fmt.Fprintf(stderr, L("Could not create export file: %v")+"\n", err)
+
+			return 1
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	if err := export.Write(out, format, sessions, untitledSession()); err != nil {
+		fmt.Fprintf(stderr, L("Could not export history: %v")+"\n", err)
+
+		return 1
+	}
+
+	return 0
+}
+
+// exportHistory asks where to save the history and exports it in the format of the chosen
+// file's extension
+func (w *MainWindow) exportHistory() {
+	// The file chooser blocks until a file was chosen, so we don't open it on the main thread
+	go func() {
+		uris, err := filechooser.SaveFile("", L("Export History"), &filechooser.SaveFileOptions{
+			// TRANSLATORS: Suggested name of the file that the history is exported to, without the extension.
+			CurrentName: L("sessions") + export.FormatCSV.Extension(),
+			Filters: []*filechooser.Filter{
+				{
+					Name:  L("CSV Spreadsheets"),
+					Rules: []filechooser.Rule{{Type: filechooser.GlobPattern, Pattern: "*" + export.FormatCSV.Extension()}},
+				},
+				{
+					Name:  L("JSON Files"),
+					Rules: []filechooser.Rule{{Type: filechooser.GlobPattern, Pattern: "*" + export.FormatJSON.Extension()}},
+				},
+				{
+					Name:  L("Calendars"),
+					Rules: []filechooser.Rule{{Type: filechooser.GlobPattern, Pattern: "*" + export.FormatICS.Extension()}},
+				},
+			},
+		})
+		if err != nil {
+			w.log.Error("Could not choose export file via file chooser portal", "err", err)
+
+			return
+		}
+
+		if len(uris) == 0 { // The file chooser was cancelled
+			return
+		}
+
+		u, err := url.Parse(uris[0])
+		if err != nil {
+			w.log.Error("Could not parse chosen export file", "err", err)
+
+			return
+		}
+
+		// Files without a known extension are exported as spreadsheets
+		format, err := export.ParseFormat(strings.TrimPrefix(filepath.Ext(u.Path), "."))
+		if err != nil {
+			format = export.FormatCSV
+		}
+
+		sessions, err := w.history.Read()
+		if err != nil {
+			w.log.Error("Could not read history", "err", err)
+
+			return
+		}
+
+		f, err := os.Create(u.Path)
+		if err != nil {
+			w.log.Error("Could not create export file", "err", err)
+
+			return
+		}
+		defer f.Close()
+
+		if err := export.Write(f, format, sessions, untitledSession()); err != nil {
+			w.log.Error("Could not export history", "err", err)
+
+			return
+		}
+	}()
+}
@@ -0,0 +1,72 @@
+package components
+
+import (
+	"context"
+	"time"
+
+	"codeberg.org/puregotk/puregotk/v4/glib"
+)
+
+// mainThreadTimer forwards calls from other goroutines, such as the local API's or the
+// media player's, to the state machine on the main thread, since its hooks update the UI
+type mainThreadTimer struct {
+	// The window's state machine is only created once its hooks, which may already need
+	// a timer, are set up
+	w *MainWindow
+}
+
+func (t *mainThreadTimer) run(fn func() error) error {
+	errs := make(chan error, 1)
+
+	var source glib.SourceFunc
+	source = glib.SourceFunc(func(u uintptr) bool {
+		defer glib.UnrefCallback(&source)
+
+		errs <- fn()
+
+		return false
+	})
+	glib.IdleAdd(&source, 0)
+
+	return <-errs
+}
+
+func (t *mainThreadTimer) StartTimer(ctx context.Context) error {
+	return t.run(func() error { return t.w.s.StartTimer(ctx) })
+}
+
+func (t *mainThreadTimer) StartTimerWithLabel(ctx context.Context, label string) error {
+	return t.run(func() error { return t.w.s.StartTimerWithLabel(ctx, label) })
+}
+
+func (t *mainThreadTimer) StopTimer(ctx context.Context) error {
+	return t.run(func() error { return t.w.s.StopTimer(ctx) })
+}
+
+func (t *mainThreadTimer) PauseTimer(ctx context.Context) error {
+	return t.run(func() error { return t.w.s.PauseTimer(ctx) })
+}
+
+func (t *mainThreadTimer) ResumeTimer(ctx context.Context) error {
+	return t.run(func() error { return t.w.s.ResumeTimer(ctx) })
+}
+
+func (t *mainThreadTimer) StopAlarming(ctx context.Context) error {
+	return t.run(func() error { return t.w.s.StopAlarming(ctx) })
+}
+
+func (t *mainThreadTimer) PlusTimer(ctx context.Context) error {
+	return t.run(func() error { return t.w.s.PlusTimer(ctx) })
+}
+
+func (t *mainThreadTimer) MinusTimer(ctx context.Context) error {
+	return t.run(func() error { return t.w.s.MinusTimer(ctx) })
+}
+
+func (t *mainThreadTimer) SetInitialRemainingTime(ctx context.Context, initialRemainingTime time.Duration) error {
+	return t.run(func() error { return t.w.s.SetInitialRemainingTime(ctx, initialRemainingTime) })
+}
+
+func (t *mainThreadTimer) SetDuration(ctx context.Context, duration time.Duration) error {
+	return t.run(func() error { return t.w.setDuration(duration) })
+}
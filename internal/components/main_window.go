@@ -2,31 +2,90 @@ package components
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"path/filepath"
 	"runtime"
 	"slices"
+	"strings"
 	"time"
 	"unsafe"
 
 	"codeberg.org/puregotk/puregotk/v4/adw"
+	"codeberg.org/puregotk/puregotk/v4/gdk"
 	"codeberg.org/puregotk/puregotk/v4/gio"
 	"codeberg.org/puregotk/puregotk/v4/glib"
 	"codeberg.org/puregotk/puregotk/v4/gobject"
+	"codeberg.org/puregotk/puregotk/v4/gobject/types"
 	"codeberg.org/puregotk/puregotk/v4/gtk"
 
+	"github.com/godbus/dbus/v5"
 	. "github.com/pojntfx/go-gettext/pkg/i18n"
 	"github.com/pojntfx/sessions/assets/resources"
+	"github.com/pojntfx/sessions/pkg/api"
+	"github.com/pojntfx/sessions/pkg/commands"
+	"github.com/pojntfx/sessions/pkg/control"
+	"github.com/pojntfx/sessions/pkg/dnd"
+	"github.com/pojntfx/sessions/pkg/duration"
+	"github.com/pojntfx/sessions/pkg/goals"
+	"github.com/pojntfx/sessions/pkg/history"
+	"github.com/pojntfx/sessions/pkg/idle"
+	"github.com/pojntfx/sessions/pkg/mpris"
+	"github.com/pojntfx/sessions/pkg/phases"
+	"github.com/pojntfx/sessions/pkg/render"
+	"github.com/pojntfx/sessions/pkg/searchprovider"
 	"github.com/pojntfx/sessions/pkg/state"
+	"github.com/pojntfx/sessions/pkg/tasks"
+	"github.com/pojntfx/sessions/pkg/tray"
+	"github.com/pojntfx/sessions/pkg/webhooks"
 	"github.com/rymdport/portal/background"
 )
 
 var (
 	gTypeMainWindow gobject.Type
+
+	// Timers that the search provider offers when only a keyword is typed
+	searchPresets = []time.Duration{time.Minute * 5, time.Minute * 15, time.Minute * 25, time.Minute * 50}
 )
 
 const (
-	notificationIdVar = "session-finished"
+	notificationIdVar        = "session-finished"
+	runningNotificationIdVar = "session-running"
+	goalNotificationIdVar    = "goal-reached"
+	phaseNotificationIdVar   = "phase-started"
+
+	// Time that the running notification's extend button adds
+	extendTimerExtension = time.Minute * 5
+
+	// How long the break overlay's postpone button delays a break
+	breakPostponeDelay = time.Minute * 2
+
+	// Name under which the timer is exposed as a media player
+	mediaPlayerName = "sessions"
+
+	// Shell that runs the commands for timer lifecycle events
+	commandShell = "sh"
+
+	// Webhooks are retried with exponential backoff, for roughly half a minute in total
+	webhookMaxAttempts    = 5
+	webhookInitialBackoff = time.Second * 2
+	webhookTimeout        = time.Second * 10
+
+	// Slow clients shouldn't be able to hold on to connections of the local API forever
+	apiReadHeaderTimeout = time.Second * 10
+
+	// Size of the tray icon that we render; hosts scale it down to fit the panel
+	trayIconSize = 96
+
+	// Minimum time between two updates of the background portal status, since every
+	// update is a round trip to the portal
+	backgroundStatusUpdateInterval = time.Second * 5
+
+	// The background portal rejects longer status messages
+	maxBackgroundStatusLength = 96
 )
 
 type MainWindow struct {
@@ -36,9 +95,17 @@ type MainWindow struct {
 	settings *gio.Settings
 	log      *slog.Logger
 
+	windowTitle  *adw.WindowTitle
 	dialWidget   *Dial
+	breakOverlay *breakOverlay
 	dialArea     gtk.Box
+	timerList    *gtk.ListBox
+	timeStack    *gtk.Stack
 	label        *gtk.Label
+	timeEntry    *gtk.Entry
+	goalLabel    *gtk.Label
+	taskEntry    *gtk.Entry
+	taskButton   *gtk.MenuButton
 	actionButton *gtk.Button
 	plusButton   *gtk.Button
 	minusButton  *gtk.Button
@@ -49,6 +116,53 @@ type MainWindow struct {
 
 	s    *state.StateMachine
 	held bool
+	// Whether a session is running, since the timer also starts again after its time was adjusted
+	running bool
+
+	// Task that the current or next session is for, empty if there is none
+	task string
+	// Whether the current or next phase is work or a break
+	phase phases.Phase
+
+	// Tasks from the task file, nil if there is none
+	taskSource tasks.Source
+	openTasks  []tasks.Task
+	taskMenu   *gio.Menu
+	// Task that was chosen from the task file, which is counted when a session for it completes
+	chosenTask *tasks.Task
+
+	// Cookie of the active suspend inhibition, or zero if there is none
+	suspendInhibitCookie uint32
+
+	backgroundStatusUpdatedAt time.Time
+	alarmStartedAt            time.Time
+	alarmOverrunSource        uint32
+
+	tray           *tray.StatusNotifierItem
+	mpris          *mpris.Player
+	searchProvider *searchprovider.Provider
+	control        *control.Service
+	dnd            *dnd.Inhibitor
+
+	commands *commands.Runner
+	webhooks *webhooks.Sender
+	history  *history.Log
+	goals    *goals.Tracker
+	recorder *history.Recorder
+	phases   *phases.Advancer
+
+	apiTracker *api.Tracker
+	apiServer  *http.Server
+
+	idleSource    *idle.MutterSource
+	idleMonitor   *idle.Monitor
+	idleThreshold time.Duration
+	// Time at which the user went away if we paused the timer because of that, zero otherwise
+	awaySince time.Time
+
+	// setDuration sets the duration of the next session while the timer is stopped, and
+	// restarts the current session with it otherwise
+	setDuration func(d time.Duration) error
 
 	callbacks []interface{}
 }
@@ -70,11 +184,21 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 	dial := NewDial(window.app, window.log, "css-name")
 	dial.Widget.SetHexpand(true)
 	dial.Widget.SetVexpand(true)
+
+	var dialLabel gobject.Value
+	dialLabel.Init(types.GType(gobject.TypeStringVal))
+	dialLabel.SetString(L("Timer Duration"))
+	dial.Widget.UpdatePropertyValue(1, []gtk.AccessibleProperty{gtk.AccessiblePropertyLabelValue}, []gobject.Value{dialLabel})
+	dialLabel.Unset()
+
 	window.dialArea.Append(&dial.Widget)
 	window.dialWidget = &dial
 
+	window.settings.Bind(resources.SchemaShowDialLabelsKey, &dial.Widget.Object, propertyDialShowLabels, gio.GSettingsBindGetValue)
+	window.AddAction(window.settings.CreateAction(resources.SchemaShowDialLabelsKey))
+
 	var remainingToLabel gobject.BindingTransformFunc = func(_ uintptr, from *gobject.Value, to *gobject.Value, _ uintptr) bool {
-		to.SetString(fmt.Sprintf("%02d:%02d", from.GetInt()/60, from.GetInt()%60))
+		to.SetString(formatRemainingTime(int(from.GetInt())))
 
 		return true
 	}
@@ -97,14 +221,403 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 		addTimeAction     = gio.NewSimpleAction("addTime", nil)
 		removeTimeAction  = gio.NewSimpleAction("removeTime", nil)
 
+		// These are attached to `app`, not `win` since they need to be reachable from
+		// notifications when the window is hidden
+		stopTimerAction   = gio.NewSimpleAction("stopTimer", nil)
+		pauseTimerAction  = gio.NewSimpleAction("pauseTimer", nil)
+		resumeTimerAction = gio.NewSimpleAction("resumeTimer", nil)
+		extendTimerAction = gio.NewSimpleAction("extendTimer", nil)
+
+		// Attached to `app` since the break overlay is a separate window
+		postponeBreakAction = gio.NewSimpleAction("postponeBreak", nil)
+
+		canStartTimer,
 		canStopTimer,
-		canStopAlarming bool
+		canStopAlarming,
+		canResumeTimer bool
+	)
+
+	units := localizedUnits()
+
+	window.setDuration = func(d time.Duration) error {
+		// A ringing alarm needs to be stopped before we can set a duration, which replaces the next phase's
+		if canStopAlarming {
+			if err := window.s.StopAlarming(phases.WithoutAdvancing(window.ctx)); err != nil {
+				return err
+			}
+		}
+
+		ok, err := window.s.CanSetInitialRemainingTime(window.ctx, d)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			return window.s.SetInitialRemainingTime(window.ctx, d)
+		}
+
+		return window.resumeWithRemainingTime(d)
+	}
+
+	activateOnMainThread := func(action *gio.SimpleAction) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			var fn glib.SourceFunc
+			fn = glib.SourceFunc(func(u uintptr) bool {
+				defer glib.UnrefCallback(&fn)
+
+				action.Activate(nil)
+
+				return false
+			})
+			glib.IdleAdd(&fn, 0)
+
+			return nil
+		}
+	}
+
+	showWindowAction := gio.NewSimpleAction("showWindow", nil)
+	onShowWindow := func(gio.SimpleAction, uintptr) {
+		window.Present()
+	}
+	window.callbacks = append(window.callbacks, &onShowWindow)
+	showWindowAction.ConnectActivate(&onShowWindow)
+	window.AddAction(showWindowAction)
+
+	// The state machine doesn't tick while alarming, so we count the time since the session finished ourselves
+	onAlarmOverrun := glib.SourceFunc(func(u uintptr) bool {
+		window.updateBackgroundStatus(alarmingBackgroundStatus(int(time.Since(window.alarmStartedAt).Seconds())), true)
+
+		return true
+	})
+	window.callbacks = append(window.callbacks, &onAlarmOverrun)
+
+	if conn, err := dbus.SessionBus(); err != nil {
+		window.log.Warn("Could not connect to session bus, not showing tray icon, media player or search results, detecting idle time or offering control interface", "err", err)
+	} else {
+		window.tray = tray.NewStatusNotifierItem(
+			window.ctx,
+			conn,
+			window.log,
+			resources.AppID,
+			L("Sessions"),
+			&tray.Hooks{
+				OnActivate: activateOnMainThread(showWindowAction),
+			},
+		)
+
+		if err := window.tray.Open(); err != nil {
+			window.log.Warn("Could not open tray icon", "err", err)
+
+			window.tray = nil
+		}
+
+		window.mpris = mpris.NewPlayer(
+			window.ctx,
+			conn,
+			window.log,
+			&mainThreadTimer{window},
+			mediaPlayerName,
+			L("Sessions"),
+			resources.AppID,
+			// TRANSLATORS: Title of the track shown in media player widgets while the timer is exposed as a media player.
+			L("Session"),
+			lastInitialRemainingTime,
+			&mpris.Hooks{
+				OnRaise: activateOnMainThread(showWindowAction),
+			},
+		)
+
+		if err := window.mpris.Open(); err != nil {
+			window.log.Warn("Could not open media player", "err", err)
+
+			window.mpris = nil
+		}
+
+		// Keywords are matched in English as well, since that's what many users type regardless of their language
+		keywords := []string{"timer", "session"}
+		// TRANSLATORS: Words that can be typed in the system search to start a timer, e.g. "timer 10".
+		//              Do NOT translate or localize the semicolons!
+		keywords = append(keywords, strings.Split(L("timer;session"), ";")...)
+
+		window.searchProvider = searchprovider.NewProvider(
+			window.ctx,
+			conn,
+			window.log,
+			resources.SearchProviderBusName,
+			dbus.ObjectPath(resources.SearchProviderPath),
+			resources.AppID,
+			keywords,
+			searchPresets,
+			units,
+			func(duration time.Duration) (string, string) {
+				// TRANSLATORS: Name of a search result that starts a timer. The placeholder is its duration, e.g. "25 min".
+				return fmt.Sprintf(L("%v Timer"), formatDuration(duration)),
+					// TRANSLATORS: Description of a search result that starts a timer. The placeholder is the time of day at which the session would end, e.g. "14:35".
+					fmt.Sprintf(L("Ends at %v"), time.Now().Add(duration).Format("15:04"))
+			},
+			&searchprovider.Hooks{
+				OnActivateResult: func(ctx context.Context, duration time.Duration) error {
+					var fn glib.SourceFunc
+					fn = glib.SourceFunc(func(u uintptr) bool {
+						defer glib.UnrefCallback(&fn)
+
+						window.Present()
+
+						// A ringing alarm needs to be stopped before we can start over
+						if canStopAlarming {
+							if err := window.s.StopAlarming(phases.WithoutAdvancing(window.ctx)); err != nil {
+								window.log.Error("Could not stop alarming before starting timer from search", "err", err)
+
+								return false
+							}
+						}
+
+						if err := window.resumeWithRemainingTime(duration); err != nil {
+							window.log.Error("Could not start timer from search", "err", err)
+						}
+
+						return false
+					})
+					glib.IdleAdd(&fn, 0)
+
+					return nil
+				},
+				OnLaunchSearch: func(ctx context.Context, terms []string) error {
+					return activateOnMainThread(showWindowAction)(ctx)
+				},
+			},
+		)
+
+		if err := window.searchProvider.Open(); err != nil {
+			window.log.Warn("Could not open search provider", "err", err)
+
+			window.searchProvider = nil
+		}
+
+		window.control = control.NewService(
+			window.ctx,
+			conn,
+			window.log,
+			resources.ControlBusName,
+			dbus.ObjectPath(resources.ControlPath),
+			units,
+			&control.Hooks{
+				OnSetDuration: func(ctx context.Context, d time.Duration) error {
+					return (&mainThreadTimer{window}).run(func() error {
+						return window.setDuration(d)
+					})
+				},
+			},
+		)
+
+		if err := window.control.Open(); err != nil {
+			window.log.Warn("Could not open control interface", "err", err)
+
+			window.control = nil
+		}
+
+		window.idleSource = idle.NewMutterSource(window.ctx, conn)
+		if err := window.idleSource.Open(); err != nil {
+			window.log.Warn("Could not open idle monitor, not detecting idle time", "err", err)
+
+			window.idleSource = nil
+		}
+	}
+
+	if backend, err := newNotificationSettingsBackend(); err != nil {
+		window.log.Warn("Could not access notification settings, not supporting do not disturb", "err", err)
+	} else {
+		window.dnd = dnd.NewInhibitor(
+			backend,
+			dnd.NewFileStore(filepath.Join(glib.GetUserStateDir(), resources.AppID, "do-not-disturb.json")),
+			window.log,
+		)
+
+		if err := window.dnd.Recover(); err != nil {
+			window.log.Warn("Could not restore notification settings from previous run", "err", err)
+		}
+	}
+
+	window.commands = commands.NewRunner(
+		window.ctx,
+		window.log,
+		commandShell,
+		time.Second*time.Duration(window.settings.GetUint(resources.SchemaCommandTimeoutKey)),
+		lastInitialRemainingTime,
+	)
+
+	commandKeys := map[string]commands.Event{
+		resources.SchemaCommandOnStartKey:      commands.EventStart,
+		resources.SchemaCommandOnStopKey:       commands.EventStop,
+		resources.SchemaCommandOnAlarmStartKey: commands.EventAlarmStart,
+		resources.SchemaCommandOnAlarmStopKey:  commands.EventAlarmStop,
+	}
+	for key, event := range commandKeys {
+		window.commands.SetCommand(event, window.settings.GetString(key))
+	}
+
+	window.webhooks = webhooks.NewSender(
+		window.ctx,
+		window.log,
+		&http.Client{Timeout: webhookTimeout},
+		webhookMaxAttempts,
+		webhookInitialBackoff,
+		lastInitialRemainingTime,
 	)
+	window.webhooks.SetURLs(window.settings.GetStrv(resources.SchemaWebhookURLsKey))
+	window.webhooks.SetSecret(window.settings.GetString(resources.SchemaWebhookSecretKey))
+
+	window.apiTracker = api.NewTracker(window.log, lastInitialRemainingTime)
+
+	window.history = history.NewLog(historyPath())
+
+	window.goals = goals.NewTracker(window.ctx, window.log, window.history, &goals.Hooks{
+		OnProgress: func(ctx context.Context, progress goals.Progress) error {
+			window.apiTracker.SetGoalProgress(progress)
+			if window.control != nil {
+				window.control.SetGoalProgress(progress)
+			}
+
+			var fn glib.SourceFunc
+			fn = glib.SourceFunc(func(u uintptr) bool {
+				defer glib.UnrefCallback(&fn)
+
+				window.updateGoalLabel(progress)
+
+				return false
+			})
+			glib.IdleAdd(&fn, 0)
+
+			return nil
+		},
+		OnReached: func(ctx context.Context, progress goals.Progress) error {
+			var fn glib.SourceFunc
+			fn = glib.SourceFunc(func(u uintptr) bool {
+				defer glib.UnrefCallback(&fn)
+
+				n := gio.NewNotification(L("Goal Reached"))
+				n.SetBody(goalProgressText(progress))
+
+				window.app.SendNotification(goalNotificationIdVar, n)
+
+				window.goalLabel.Announce(L("Goal Reached"), gtk.AccessibleAnnouncementPriorityMediumValue)
+
+				return false
+			})
+			glib.IdleAdd(&fn, 0)
+
+			return nil
+		},
+	})
+
+	onSettingsChanged := func(settings gio.Settings, key string) {
+		if event, ok := commandKeys[key]; ok {
+			window.commands.SetCommand(event, window.settings.GetString(key))
+
+			return
+		}
+
+		switch key {
+		case resources.SchemaCommandTimeoutKey:
+			window.commands.SetTimeout(time.Second * time.Duration(window.settings.GetUint(resources.SchemaCommandTimeoutKey)))
+
+		case resources.SchemaWebhookURLsKey:
+			window.webhooks.SetURLs(window.settings.GetStrv(resources.SchemaWebhookURLsKey))
+
+		case resources.SchemaWebhookSecretKey:
+			window.webhooks.SetSecret(window.settings.GetString(resources.SchemaWebhookSecretKey))
+
+		case resources.SchemaAPIEnabledKey, resources.SchemaAPIAddressKey, resources.SchemaAPITokenKey:
+			window.stopAPI()
+			window.startAPI()
+
+		case resources.SchemaTaskFileKey:
+			window.loadTasks()
+
+		case resources.SchemaGoalPeriodKey, resources.SchemaGoalSessionsKey, resources.SchemaGoalFocusedTimeKey:
+			window.goals.SetGoal(window.goal())
+
+		case resources.SchemaBreakDurationKey, resources.SchemaAutoStartBreaksKey, resources.SchemaAutoStartWorkKey, resources.SchemaAutoStartGracePeriodKey, resources.SchemaBreakStrictnessKey:
+			window.phases.SetConfig(window.phaseConfig())
+
+		case resources.SchemaBreakOverlayKey:
+			window.phases.SetConfig(window.phaseConfig())
+			window.updateBreakOverlay(canStopTimer)
+		}
+	}
+	window.callbacks = append(window.callbacks, &onSettingsChanged)
+	window.settings.ConnectChanged(&onSettingsChanged)
+
+	recorder := history.NewRecorder(window.log, window.history)
+	window.recorder = recorder
+	recorder.SetOnRecord(func(history.Session) {
+		window.goals.Refresh()
+	})
+
+	window.phase = phases.PhaseWork
+	window.phases = phases.NewAdvancer(window.ctx, window.log, &mainThreadTimer{window}, lastInitialRemainingTime, &phases.Hooks{
+		OnPhaseChange: func(ctx context.Context, phase phases.Phase) error {
+			// Breaks don't count towards goals, so this needs to happen before the break starts
+			recorder.SetBreak(phase == phases.PhaseBreak)
+
+			var fn glib.SourceFunc
+			fn = glib.SourceFunc(func(u uintptr) bool {
+				defer glib.UnrefCallback(&fn)
+
+				window.phase = phase
+				window.updateSubtitle()
+
+				return false
+			})
+			glib.IdleAdd(&fn, 0)
+
+			return nil
+		},
+		OnAutoStart: func(ctx context.Context, phase phases.Phase) error {
+			var fn glib.SourceFunc
+			fn = glib.SourceFunc(func(u uintptr) bool {
+				defer glib.UnrefCallback(&fn)
+
+				var n *gio.Notification
+				if phase == phases.PhaseBreak {
+					n = gio.NewNotification(L("Break Started"))
+					// TRANSLATORS: Button of the notification shown when a break starts automatically that ends it.
+					n.AddButton(L("Skip Break"), "app.skipPhase")
+				} else {
+					n = gio.NewNotification(window.withTask(L("Session Started")))
+					// TRANSLATORS: Button of the notification shown when a session starts automatically that stops it and starts a break instead.
+					n.AddButton(L("Skip Session"), "app.skipPhase")
+				}
+				n.SetBody(fmt.Sprintf(L("Ends at %v"), time.Now().Add(lastInitialRemainingTime).Format("15:04")))
+
+				window.app.SendNotification(phaseNotificationIdVar, n)
+
+				return false
+			})
+			glib.IdleAdd(&fn, 0)
+
+			return nil
+		},
+	})
+	window.phases.SetConfig(window.phaseConfig())
+
+	// Integrations keep other programs in sync with the timer, after the UI has been updated
+	integrationHooks := []*state.Hooks{
+		window.commands.Hooks(),
+		window.webhooks.Hooks(),
+		window.apiTracker.Hooks(),
+		recorder.Hooks(),
+		window.phases.Hooks(),
+	}
+	if window.mpris != nil {
+		integrationHooks = append(integrationHooks, window.mpris.Hooks())
+	}
+
 	window.s = state.NewStateMachine(
 		window.ctx,
 		lastInitialRemainingTime,
 		window.log,
-		&state.Hooks{
+		state.CombineHooks(append([]*state.Hooks{{
 			OnStartTimer: func(ctx context.Context) error {
 				var fn glib.SourceFunc
 				fn = glib.SourceFunc(func(u uintptr) bool {
@@ -112,7 +625,13 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 
 					window.dialWidget.SetCountingDown(true)
 
-					if !window.held {
+					window.updateTray(tray.StatusActive)
+
+					// Adjusting the time continues the session, so we only ask to run in the background once
+					starting := !window.running
+					window.running = true
+
+					if starting && !window.held {
 						func() {
 							res, err := background.RequestBackground("", &background.RequestOptions{
 								// TRANSLATORS: Reason given when requesting permission to run in the background from the system.
@@ -129,22 +648,35 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 							}
 
 							if err := background.SetStatus(background.StatusOptions{
-								// TRANSLATORS: Message shown in the background apps list next to the app while the app is running in the background.
-								Message: L("Timer Running"),
+								Message: window.backgroundStatus(countingDownBackgroundStatus(window.dialWidget.GetRemainingTime())),
 							},
 							); err != nil {
 								window.log.Error("Could not set app status via background portal", "err", err)
 
 								return
 							}
+							window.backgroundStatusUpdatedAt = time.Now()
 
 							window.app.Hold()
 							window.SetHideOnClose(true)
 
 							window.held = true
 						}()
+					} else {
+						window.updateBackgroundStatus(countingDownBackgroundStatus(window.dialWidget.GetRemainingTime()), true)
 					}
 
+					window.sendRunningNotification(time.Duration(window.dialWidget.GetRemainingTime())*time.Second, false)
+
+					window.inhibitNotifications()
+
+					window.inhibitSuspend()
+
+					window.startIdleMonitor(pauseTimerAction, resumeTimerAction)
+
+					postponeBreakAction.SetEnabled(window.phases.CanPostpone())
+					window.updateBreakOverlay(true)
+
 					return false
 				})
 				glib.IdleAdd(&fn, 0)
@@ -160,6 +692,21 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 
 					window.dialWidget.SetCountingDown(false)
 
+					window.running = false
+
+					window.updateBreakOverlay(false)
+
+					window.updateTray(tray.StatusPassive)
+
+					window.stopIdleMonitor()
+
+					window.app.WithdrawNotification(runningNotificationIdVar)
+
+					window.releaseNotifications()
+
+					// Unlike holding the app, this doesn't depend on the background portal
+					window.uninhibitSuspend()
+
 					if window.held {
 						func() {
 							if err := background.SetStatus(background.StatusOptions{
@@ -185,15 +732,80 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 				return nil
 			},
 
+			OnPauseTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+				var fn glib.SourceFunc
+				fn = glib.SourceFunc(func(u uintptr) bool {
+					defer glib.UnrefCallback(&fn)
+
+					window.dialWidget.SetRemainingTime(int(currentRemainingTime.Seconds()))
+
+					window.dialWidget.SetCountingDown(false)
+
+					window.updateTray("")
+
+					window.updateBackgroundStatus(pausedBackgroundStatus(int(currentRemainingTime.Seconds())), true)
+
+					window.sendRunningNotification(currentRemainingTime, true)
+
+					window.releaseNotifications()
+
+					// A paused timer might be forgotten, e.g. after pausing because we are away, so it
+					// shouldn't keep the system awake
+					window.uninhibitSuspend()
+
+					return false
+				})
+				glib.IdleAdd(&fn, 0)
+
+				return nil
+			},
+			OnResumeTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+				var fn glib.SourceFunc
+				fn = glib.SourceFunc(func(u uintptr) bool {
+					defer glib.UnrefCallback(&fn)
+
+					window.dialWidget.SetRemainingTime(int(currentRemainingTime.Seconds()))
+
+					window.dialWidget.SetCountingDown(true)
+
+					window.updateTray("")
+
+					window.updateBackgroundStatus(countingDownBackgroundStatus(int(currentRemainingTime.Seconds())), true)
+
+					window.sendRunningNotification(currentRemainingTime, false)
+
+					window.inhibitNotifications()
+
+					window.inhibitSuspend()
+
+					window.startIdleMonitor(pauseTimerAction, resumeTimerAction)
+
+					postponeBreakAction.SetEnabled(window.phases.CanPostpone())
+					window.updateBreakOverlay(true)
+
+					return false
+				})
+				glib.IdleAdd(&fn, 0)
+
+				return nil
+			},
+
 			OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
 				lastInitialRemainingTime = initialRemainingTime
 
+				// The duration of breaks is set in the preferences, so we only remember the duration of work
+				remember := window.phases.Phase() == phases.PhaseWork
+
 				var fn glib.SourceFunc
 				fn = glib.SourceFunc(func(u uintptr) bool {
 					defer glib.UnrefCallback(&fn)
 
 					window.dialWidget.SetRemainingTime(int(lastInitialRemainingTime.Seconds()))
-					window.settings.SetInt64(resources.SchemaLastPositionKey, int64(lastInitialRemainingTime.Seconds()))
+					if remember {
+						window.settings.SetInt64(resources.SchemaLastPositionKey, int64(lastInitialRemainingTime.Seconds()))
+					}
+
+					window.updateTray("")
 
 					return false
 				})
@@ -208,6 +820,47 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 
 					window.dialWidget.SetRemainingTime(int(currentRemainingTime.Seconds()))
 
+					window.updateTray("")
+
+					window.updateBackgroundStatus(countingDownBackgroundStatus(int(currentRemainingTime.Seconds())), false)
+
+					return false
+				})
+				glib.IdleAdd(&fn, 0)
+
+				return nil
+			},
+
+			OnLabelChange: func(ctx context.Context, label string) error {
+				var fn glib.SourceFunc
+				fn = glib.SourceFunc(func(u uintptr) bool {
+					defer glib.UnrefCallback(&fn)
+
+					window.task = label
+
+					window.updateSubtitle()
+					if strings.TrimSpace(window.taskEntry.GetText()) != label {
+						window.taskEntry.SetText(label)
+					}
+
+					if window.mpris != nil {
+						if label == "" {
+							window.mpris.SetTitle(L("Session"))
+						} else {
+							window.mpris.SetTitle(label)
+						}
+					}
+
+					// Messages that include the task are only updated when the timer is, so we update them now
+					remainingTime := time.Duration(window.dialWidget.GetRemainingTime()) * time.Second
+					if canStopTimer {
+						window.updateBackgroundStatus(countingDownBackgroundStatus(int(remainingTime.Seconds())), true)
+						window.sendRunningNotification(remainingTime, false)
+					} else if canResumeTimer {
+						window.updateBackgroundStatus(pausedBackgroundStatus(int(remainingTime.Seconds())), true)
+						window.sendRunningNotification(remainingTime, true)
+					}
+
 					return false
 				})
 				glib.IdleAdd(&fn, 0)
@@ -220,8 +873,14 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 				fn = glib.SourceFunc(func(u uintptr) bool {
 					defer glib.UnrefCallback(&fn)
 
+					window.inhibitSuspend()
+
+					window.updateBreakOverlay(false)
+
 					window.dialWidget.SetRemainingTime(0)
 
+					window.alarmStartedAt = time.Now()
+
 					window.dialWidget.SetCountingDown(true)
 
 					if !window.held {
@@ -241,28 +900,50 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 							}
 
 							if err := background.SetStatus(background.StatusOptions{
-								// TRANSLATORS: Message shown in the background apps list next to the app while the app is running in the background and the session has finished.
-								Message: L("Session Finished"),
+								Message: window.backgroundStatus(alarmingBackgroundStatus(0)),
 							},
 							); err != nil {
 								window.log.Error("Could not set app status via background portal", "err", err)
 
 								return
 							}
+							window.backgroundStatusUpdatedAt = time.Now()
 
 							window.app.Hold()
 							window.SetHideOnClose(true)
 
 							window.held = true
 						}()
+					} else {
+						window.updateBackgroundStatus(alarmingBackgroundStatus(0), true)
+					}
+
+					if window.alarmOverrunSource == 0 {
+						window.alarmOverrunSource = glib.TimeoutAdd(uint32(backgroundStatusUpdateInterval.Milliseconds()), &onAlarmOverrun, 0)
 					}
 
 					window.dialWidget.SetSensitive(false)
 
+					window.updateTray(tray.StatusNeedsAttention)
+
 					window.label.AddCssClass("dial__display--alarming")
 
-					n := gio.NewNotification(L("Session Finished"))
-					n.SetBody(L("Time to take a break"))
+					window.countChosenTask()
+
+					window.app.WithdrawNotification(runningNotificationIdVar)
+
+					// We restore notifications before sending ours so that it is shown as a banner
+					window.releaseNotifications()
+
+					var n *gio.Notification
+					// A postponed break starts again once the delay is over
+					if window.phase == phases.PhaseBreak && !window.phases.Postponed() {
+						n = gio.NewNotification(L("Break Finished"))
+						n.SetBody(L("Time to focus again"))
+					} else {
+						n = gio.NewNotification(window.withTask(L("Session Finished")))
+						n.SetBody(L("Time to take a break"))
+					}
 					n.SetPriority(gio.GNotificationPriorityHighValue)
 					// We need to attach to `app`, not `win` since it's possible that no window
 					// is focused when the notification is activated
@@ -290,6 +971,15 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 
 					window.dialWidget.SetCountingDown(false)
 
+					if window.alarmOverrunSource != 0 {
+						glib.SourceRemove(window.alarmOverrunSource)
+
+						window.alarmOverrunSource = 0
+					}
+
+					// Unlike holding the app, this doesn't depend on the background portal
+					window.uninhibitSuspend()
+
 					if window.held {
 						func() {
 							if err := background.SetStatus(background.StatusOptions{
@@ -310,6 +1000,8 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 
 					window.dialWidget.SetSensitive(true)
 
+					window.updateTray(tray.StatusPassive)
+
 					window.label.RemoveCssClass("dial__display--alarming")
 
 					window.alarmClockElapsedFile.SetPlaying(false)
@@ -332,12 +1024,20 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 					toggleTimerAction.SetEnabled(
 						slices.Contains(permittedTriggers, state.TriggerStartTimer) ||
 							slices.Contains(permittedTriggers, state.TriggerStopTimer) ||
+							slices.Contains(permittedTriggers, state.TriggerResumeTimer) ||
 							slices.Contains(permittedTriggers, state.TriggerStopAlarming),
 					)
 					removeTimeAction.SetEnabled(slices.Contains(permittedTriggers, state.TriggerMinusTimer))
 					addTimeAction.SetEnabled(slices.Contains(permittedTriggers, state.TriggerPlusTimer))
 
-					if slices.Contains(permittedTriggers, state.TriggerStartTimer) {
+					stopTimerAction.SetEnabled(slices.Contains(permittedTriggers, state.TriggerStopTimer))
+					pauseTimerAction.SetEnabled(slices.Contains(permittedTriggers, state.TriggerPauseTimer))
+					resumeTimerAction.SetEnabled(slices.Contains(permittedTriggers, state.TriggerResumeTimer))
+					// The timer can only be extended while it is counting down, which is when it can be paused
+					extendTimerAction.SetEnabled(slices.Contains(permittedTriggers, state.TriggerPlusTimer) && slices.Contains(permittedTriggers, state.TriggerPauseTimer))
+
+					canStartTimer = slices.Contains(permittedTriggers, state.TriggerStartTimer)
+					if canStartTimer {
 						window.actionButton.SetIconName("media-playback-start-symbolic")
 						window.actionButton.SetLabel(L("_Start Timer"))
 						window.actionButton.RemoveCssClass("destructive-action")
@@ -352,40 +1052,321 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 						window.actionButton.AddCssClass("destructive-action")
 					}
 
+					// While paused we can also stop the timer, but resuming is what users will want to do most
+					canResumeTimer = slices.Contains(permittedTriggers, state.TriggerResumeTimer)
+					if canResumeTimer {
+						window.actionButton.SetIconName("media-playback-start-symbolic")
+						window.actionButton.SetLabel(L("_Resume"))
+						window.actionButton.RemoveCssClass("destructive-action")
+						window.actionButton.AddCssClass("suggested-action")
+					}
+
+					if window.tray != nil {
+						toggleTimerLabel := L("Start Timer")
+						if canResumeTimer {
+							toggleTimerLabel = L("Resume")
+						} else if canStopTimer || canStopAlarming {
+							toggleTimerLabel = L("Stop")
+						}
+
+						if err := window.tray.SetMenu([]tray.MenuItem{
+							{
+								Label:     toggleTimerLabel,
+								Enabled:   toggleTimerAction.GetEnabled(),
+								OnClicked: activateOnMainThread(toggleTimerAction),
+							},
+							{
+								// TRANSLATORS: Tray icon menu item that adds 30 seconds to the timer.
+								Label:     L("Add 30 Seconds"),
+								Enabled:   addTimeAction.GetEnabled(),
+								OnClicked: activateOnMainThread(addTimeAction),
+							},
+							{
+								// TRANSLATORS: Tray icon menu item that removes 30 seconds from the timer.
+								Label:     L("Remove 30 Seconds"),
+								Enabled:   removeTimeAction.GetEnabled(),
+								OnClicked: activateOnMainThread(removeTimeAction),
+							},
+							{
+								Separator: true,
+							},
+							{
+								Label:     L("Show Window"),
+								Enabled:   true,
+								OnClicked: activateOnMainThread(showWindowAction),
+							},
+						}); err != nil {
+							window.log.Warn("Could not set tray icon menu", "err", err)
+						}
+					}
+
 					return false
 				})
 				glib.IdleAdd(&fn, 0)
 
 				return nil
 			},
-		},
+		}}, integrationHooks...)...),
 	)
 	window.s.FlushPermittedTriggers(window.ctx)
 
-	onDialDragBegin := func() {
-		if err := window.s.StartDragging(window.ctx); err != nil {
-			window.log.Error("Could not start dragging", "err", err)
+	window.goals.SetGoal(window.goal())
 
-			return
+	window.startAPI()
+
+	// Sessions started from the window are for the task that was entered
+	applyTask := func() {
+		if err := window.s.SetLabel(window.ctx, strings.TrimSpace(window.taskEntry.GetText())); err != nil {
+			window.log.Error("Could not set task", "err", err)
 		}
 	}
-	window.callbacks = append(window.callbacks, &onDialDragBegin)
-	dial.ConnectDragBegin(&onDialDragBegin)
 
-	onDialDragEnd := func() {
-		if err := window.s.StopDragging(window.ctx, time.Duration(window.dialWidget.GetRemainingTime())*time.Second); err != nil {
-			window.log.Error("Could not stop dragging", "err", err)
+	onTaskEntryActivate := func(gtk.Entry) {
+		if canStartTimer {
+			toggleTimerAction.Activate(nil)
 
 			return
 		}
+
+		applyTask()
 	}
-	window.callbacks = append(window.callbacks, &onDialDragEnd)
-	dial.ConnectDragEnd(&onDialDragEnd)
+	window.callbacks = append(window.callbacks, &onTaskEntryActivate)
+	window.taskEntry.ConnectActivate(&onTaskEntryActivate)
 
-	onToggleTimer := func(gio.SimpleAction, uintptr) {
-		if canStopTimer {
-			if err := window.s.StopTimer(window.ctx); err != nil {
-				window.log.Error("Could not stop timer", "err", err)
+	taskEntryFocus := gtk.NewEventControllerFocus()
+	onTaskEntryFocusLeave := func(gtk.EventControllerFocus) {
+		applyTask()
+	}
+	window.callbacks = append(window.callbacks, &onTaskEntryFocusLeave)
+	taskEntryFocus.ConnectLeave(&onTaskEntryFocusLeave)
+	window.taskEntry.AddController(&taskEntryFocus.EventController)
+
+	window.taskMenu = gio.NewMenu()
+	window.taskButton.SetMenuModel(&window.taskMenu.MenuModel)
+	window.loadTasks()
+
+	// The task file might have been edited since we last read it, so we read it again when the menu opens
+	onTaskButtonNotify := func(_ gobject.Object, pspec uintptr) {
+		if gobject.ParamSpecNewFromInternalPtr(pspec).GetName() == "active" && window.taskButton.GetActive() {
+			window.loadTasks()
+		}
+	}
+	window.callbacks = append(window.callbacks, &onTaskButtonNotify)
+	window.taskButton.ConnectNotify(&onTaskButtonNotify)
+
+	chooseTaskAction := gio.NewSimpleAction("chooseTask", glib.NewVariantType("i"))
+	onChooseTask := func(_ gio.SimpleAction, parameter uintptr) {
+		i := int((*glib.Variant)(unsafe.Pointer(parameter)).GetInt32())
+		if i < 0 || i >= len(window.openTasks) {
+			return
+		}
+
+		task := window.openTasks[i]
+		window.chosenTask = &task
+
+		window.taskEntry.SetText(task.Title)
+		applyTask()
+	}
+	window.callbacks = append(window.callbacks, &onChooseTask)
+	chooseTaskAction.ConnectActivate(&onChooseTask)
+	window.AddAction(chooseTaskAction)
+
+	onDialDragBegin := func() {
+		applyTask()
+
+		if err := window.s.StartDragging(window.ctx); err != nil {
+			window.log.Error("Could not start dragging", "err", err)
+
+			return
+		}
+	}
+	window.callbacks = append(window.callbacks, &onDialDragBegin)
+	dial.ConnectDragBegin(&onDialDragBegin)
+
+	onDialDragEnd := func() {
+		if err := window.s.StopDragging(window.ctx, time.Duration(window.dialWidget.GetRemainingTime())*time.Second); err != nil {
+			window.log.Error("Could not stop dragging", "err", err)
+
+			return
+		}
+	}
+	window.callbacks = append(window.callbacks, &onDialDragEnd)
+	dial.ConnectDragEnd(&onDialDragEnd)
+
+	// Typing a duration works like dragging the dial: the timer holds while we type, and
+	// counts down from the typed duration once we are done, or continues as it was if we cancel
+	var editingTime bool
+
+	parseEnteredTime := func() (time.Duration, bool) {
+		d, _, err := duration.ParseRaw(window.timeEntry.GetText(), units)
+		if err != nil {
+			return 0, false
+		}
+		d = d.Round(state.RemainingTimerAdjustmentInterval)
+
+		ok, err := window.s.CanStopDragging(window.ctx, d)
+		if err != nil {
+			window.log.Error("Could not check whether entered duration is valid", "err", err)
+
+			return 0, false
+		}
+
+		return d, ok
+	}
+
+	editTimeAction := gio.NewSimpleAction("editTime", nil)
+	onEditTime := func(gio.SimpleAction, uintptr) {
+		if editingTime {
+			window.timeEntry.GrabFocus()
+
+			return
+		}
+
+		// A ringing alarm needs to be stopped before we can drag
+		if canStopAlarming {
+			if err := window.s.StopAlarming(phases.WithoutAdvancing(window.ctx)); err != nil {
+				window.log.Error("Could not stop alarming before entering duration", "err", err)
+
+				return
+			}
+		}
+
+		applyTask()
+
+		if err := window.s.StartDragging(window.ctx); err != nil {
+			window.log.Error("Could not start entering duration", "err", err)
+
+			return
+		}
+
+		editingTime = true
+		window.showTimeEntry()
+	}
+	window.callbacks = append(window.callbacks, &onEditTime)
+	editTimeAction.ConnectActivate(&onEditTime)
+	window.AddAction(editTimeAction)
+
+	onTimerRowActivated := func(gtk.ListBox, uintptr) {
+		editTimeAction.Activate(nil)
+	}
+	window.callbacks = append(window.callbacks, &onTimerRowActivated)
+	window.timerList.ConnectRowActivated(&onTimerRowActivated)
+
+	stopEditingTime := func(d time.Duration) {
+		editingTime = false
+		window.hideTimeEntry()
+
+		if err := window.s.StopDragging(window.ctx, d); err != nil {
+			window.log.Error("Could not stop entering duration", "err", err)
+		}
+	}
+
+	onTimeEntryNotify := func(gobject.Object, uintptr) {
+		if !editingTime {
+			return
+		}
+
+		// Durations that are out of range or can't be parsed are highlighted as we type
+		_, ok := parseEnteredTime()
+		invalid := !ok && strings.TrimSpace(window.timeEntry.GetText()) != ""
+		if invalid && !window.timeEntry.HasCssClass("error") {
+			window.timeEntry.AddCssClass("error")
+		} else if !invalid && window.timeEntry.HasCssClass("error") {
+			window.timeEntry.RemoveCssClass("error")
+		}
+	}
+	window.callbacks = append(window.callbacks, &onTimeEntryNotify)
+	window.timeEntry.ConnectNotify(&onTimeEntryNotify)
+
+	onTimeEntryActivate := func(gtk.Entry) {
+		if !editingTime {
+			return
+		}
+
+		d, ok := parseEnteredTime()
+		if !ok {
+			window.timeEntry.AddCssClass("error")
+			window.timeEntry.ErrorBell()
+
+			return
+		}
+
+		stopEditingTime(d)
+	}
+	window.callbacks = append(window.callbacks, &onTimeEntryActivate)
+	window.timeEntry.ConnectActivate(&onTimeEntryActivate)
+
+	cancelEditingTime := func() {
+		if !editingTime {
+			return
+		}
+
+		editingTime = false
+		window.hideTimeEntry()
+
+		// A stopped or paused timer stays that way, and a running one continues where it was
+		if err := window.s.CancelDragging(window.ctx); err != nil {
+			window.log.Error("Could not cancel entering duration", "err", err)
+		}
+	}
+
+	timeEntryKey := gtk.NewEventControllerKey()
+	onTimeEntryKeyPressed := func(_ gtk.EventControllerKey, keyval uint32, _ uint32, _ gdk.ModifierType) bool {
+		if int32(keyval) != gdk.KEY_Escape {
+			return false
+		}
+
+		cancelEditingTime()
+
+		return true
+	}
+	window.callbacks = append(window.callbacks, &onTimeEntryKeyPressed)
+	timeEntryKey.ConnectKeyPressed(&onTimeEntryKeyPressed)
+	window.timeEntry.AddController(&timeEntryKey.EventController)
+
+	// Clicking elsewhere discards the typed duration
+	timeEntryFocus := gtk.NewEventControllerFocus()
+	onTimeEntryFocusLeave := func(gtk.EventControllerFocus) {
+		cancelEditingTime()
+	}
+	window.callbacks = append(window.callbacks, &onTimeEntryFocusLeave)
+	timeEntryFocus.ConnectLeave(&onTimeEntryFocusLeave)
+	window.timeEntry.AddController(&timeEntryFocus.EventController)
+
+	onDialAdjustTime := func(intervals int) {
+		// A stopped timer starts like after dragging, from the time that the dial previewed
+		if canStartTimer {
+			applyTask()
+
+			if err := window.resumeWithRemainingTime(time.Duration(window.dialWidget.GetRemainingTime()) * time.Second); err != nil {
+				window.log.Error("Could not start timer after scrolling", "err", err)
+			}
+
+			return
+		}
+
+		// A running timer only starts over once, and a paused one stays paused
+		if err := window.s.AdjustTimer(window.ctx, time.Duration(intervals)*state.RemainingTimerAdjustmentInterval); err != nil {
+			window.log.Error("Could not adjust timer", "err", err)
+		}
+	}
+	window.callbacks = append(window.callbacks, &onDialAdjustTime)
+	dial.ConnectAdjustTime(&onDialAdjustTime)
+
+	onToggleTimer := func(gio.SimpleAction, uintptr) {
+		if canResumeTimer {
+			if err := window.s.ResumeTimer(window.ctx); err != nil {
+				window.log.Error("Could not resume timer", "err", err)
+
+				return
+			}
+
+			return
+		}
+
+		if canStopTimer {
+			if err := window.s.StopTimer(window.ctx); err != nil {
+				window.log.Error("Could not stop timer", "err", err)
 
 				return
 			}
@@ -403,7 +1384,7 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 			return
 		}
 
-		if err := window.s.StartTimer(window.ctx); err != nil {
+		if err := window.s.StartTimerWithLabel(window.ctx, strings.TrimSpace(window.taskEntry.GetText())); err != nil {
 			window.log.Error("Could not start timer", "err", err)
 
 			return
@@ -435,6 +1416,14 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 	removeTimeAction.ConnectActivate(&onRemoveTime)
 	window.AddAction(removeTimeAction)
 
+	exportHistoryAction := gio.NewSimpleAction("exportHistory", nil)
+	onExportHistory := func(gio.SimpleAction, uintptr) {
+		window.exportHistory()
+	}
+	window.callbacks = append(window.callbacks, &onExportHistory)
+	exportHistoryAction.ConnectActivate(&onExportHistory)
+	window.AddAction(exportHistoryAction)
+
 	closeWindowAction := gio.NewSimpleAction("closeWindow", nil)
 	onCloseWindow := func(gio.SimpleAction, uintptr) {
 		window.Close()
@@ -457,20 +1446,776 @@ func NewMainWindow(ctx context.Context, app *adw.Application, log *slog.Logger,
 	stopAlarmPlaybackAction.ConnectActivate(&onStopAlarmPlaybackAction)
 	window.app.AddAction(stopAlarmPlaybackAction)
 
+	onStopTimer := func(gio.SimpleAction, uintptr) {
+		if err := window.s.StopTimer(window.ctx); err != nil {
+			window.log.Error("Could not stop timer", "err", err)
+
+			return
+		}
+	}
+	window.callbacks = append(window.callbacks, &onStopTimer)
+	stopTimerAction.ConnectActivate(&onStopTimer)
+	window.app.AddAction(stopTimerAction)
+
+	onPauseTimer := func(gio.SimpleAction, uintptr) {
+		if err := window.s.PauseTimer(window.ctx); err != nil {
+			window.log.Error("Could not pause timer", "err", err)
+
+			return
+		}
+	}
+	window.callbacks = append(window.callbacks, &onPauseTimer)
+	pauseTimerAction.ConnectActivate(&onPauseTimer)
+	window.app.AddAction(pauseTimerAction)
+
+	onResumeTimer := func(gio.SimpleAction, uintptr) {
+		if err := window.s.ResumeTimer(window.ctx); err != nil {
+			window.log.Error("Could not resume timer", "err", err)
+
+			return
+		}
+	}
+	window.callbacks = append(window.callbacks, &onResumeTimer)
+	resumeTimerAction.ConnectActivate(&onResumeTimer)
+	window.app.AddAction(resumeTimerAction)
+
+	onExtendTimer := func(gio.SimpleAction, uintptr) {
+		if err := window.s.ExtendTimer(window.ctx, extendTimerExtension); err != nil {
+			window.log.Error("Could not extend timer", "err", err)
+		}
+	}
+	window.callbacks = append(window.callbacks, &onExtendTimer)
+	extendTimerAction.ConnectActivate(&onExtendTimer)
+	window.app.AddAction(extendTimerAction)
+
+	skipPhaseAction := gio.NewSimpleAction("skipPhase", nil)
+	onSkipPhase := func(gio.SimpleAction, uintptr) {
+		window.app.WithdrawNotification(phaseNotificationIdVar)
+
+		// Skipping controls the timer from the main thread, so it can't run on it
+		go func() {
+			if err := window.phases.Skip(window.ctx); err != nil {
+				window.log.Error("Could not skip phase", "err", err)
+			}
+		}()
+	}
+	window.callbacks = append(window.callbacks, &onSkipPhase)
+	skipPhaseAction.ConnectActivate(&onSkipPhase)
+	window.app.AddAction(skipPhaseAction)
+
+	onPostponeBreak := func(gio.SimpleAction, uintptr) {
+		postponeBreakAction.SetEnabled(false)
+
+		// Postponing controls the timer from the main thread, so it can't run on it
+		go func() {
+			if err := window.phases.Postpone(window.ctx, breakPostponeDelay); err != nil {
+				window.log.Error("Could not postpone break", "err", err)
+
+				return
+			}
+
+			var fn glib.SourceFunc
+			fn = glib.SourceFunc(func(u uintptr) bool {
+				defer glib.UnrefCallback(&fn)
+
+				window.updateSubtitle()
+
+				return false
+			})
+			glib.IdleAdd(&fn, 0)
+		}()
+	}
+	window.callbacks = append(window.callbacks, &onPostponeBreak)
+	postponeBreakAction.ConnectActivate(&onPostponeBreak)
+	postponeBreakAction.SetEnabled(false)
+	window.app.AddAction(postponeBreakAction)
+
+	// The duration is in seconds, since it has already been parsed, e.g. by the command line
+	setDurationAction := gio.NewSimpleAction("setDuration", glib.NewVariantType("x"))
+	onSetDuration := func(_ gio.SimpleAction, parameter uintptr) {
+		d, err := duration.Snap(time.Duration((*glib.Variant)(unsafe.Pointer(parameter)).GetInt64()) * time.Second)
+		if err != nil {
+			window.log.Error("Could not set duration", "err", err)
+
+			return
+		}
+
+		if err := window.setDuration(d); err != nil {
+			window.log.Error("Could not set duration", "err", err)
+
+			return
+		}
+	}
+	window.callbacks = append(window.callbacks, &onSetDuration)
+	setDurationAction.ConnectActivate(&onSetDuration)
+	window.app.AddAction(setDurationAction)
+
 	window.app.SetAccelsForAction("win.closeWindow", []string{`<Primary>w`})
 	window.app.SetAccelsForAction("win.toggleTimer", []string{`<Primary>space`})
 	window.app.SetAccelsForAction("win.addTime", []string{`<Primary>plus`, `<Primary>equal`})
 	window.app.SetAccelsForAction("win.removeTime", []string{`<Primary>minus`})
+	window.app.SetAccelsForAction("win.editTime", []string{`<Primary>l`})
 
 	return v
 }
 
+// localizedUnits returns the unit words that durations can be typed with in the current language,
+// in addition to the English ones
+func localizedUnits() duration.Units {
+	return duration.NewUnits(
+		// TRANSLATORS: Words for seconds that durations can be typed with, e.g. "90 seconds".
+		//              Do NOT translate or localize the semicolons!
+		L("s;sec;second;seconds"),
+		// TRANSLATORS: Words for minutes that durations can be typed with, e.g. "25 min".
+		//              Do NOT translate or localize the semicolons!
+		L("min;minute;minutes"),
+		// TRANSLATORS: Words for hours that durations can be typed with, e.g. "1 hour".
+		//              Do NOT translate or localize the semicolons!
+		L("h;hour;hours"),
+	)
+}
+
+func formatDuration(duration time.Duration) string {
+	minutes, seconds := int(duration/time.Minute), int(duration%time.Minute/time.Second)
+	if seconds == 0 {
+		// TRANSLATORS: A duration in minutes, e.g. "25 min".
+		return fmt.Sprintf(L("%v min"), minutes)
+	}
+
+	// TRANSLATORS: A duration in minutes and seconds, e.g. "2 min 30 s".
+	return fmt.Sprintf(L("%v min %v s"), minutes, seconds)
+}
+
+func formatRemainingTime(seconds int) string {
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+func countingDownBackgroundStatus(remainingSeconds int) string {
+	// TRANSLATORS: Message shown in the background apps list next to the app while the app is running in the background. The placeholder is the remaining time, e.g. "12:30".
+	return fmt.Sprintf(L("%v left"), formatRemainingTime(remainingSeconds))
+}
+
+func pausedBackgroundStatus(remainingSeconds int) string {
+	// TRANSLATORS: Message shown in the background apps list next to the app while the timer is paused in the background. The placeholder is the remaining time, e.g. "12:30".
+	return fmt.Sprintf(L("Paused, %v left"), formatRemainingTime(remainingSeconds))
+}
+
+func alarmingBackgroundStatus(overrunSeconds int) string {
+	// TRANSLATORS: Message shown in the background apps list next to the app while the app is running in the background and the session has finished. The placeholder is the time since the session finished, e.g. "01:15".
+	return fmt.Sprintf(L("Session Finished (+%v)"), formatRemainingTime(overrunSeconds))
+}
+
+// sendRunningNotification shows or replaces the notification that lets users control a
+// running session while the window is hidden, if it is enabled
+func (w *MainWindow) sendRunningNotification(remainingTime time.Duration, paused bool) {
+	if !w.settings.GetBoolean(resources.SchemaShowRunningNotificationKey) {
+		w.app.WithdrawNotification(runningNotificationIdVar)
+
+		return
+	}
+
+	var n *gio.Notification
+	if paused {
+		n = gio.NewNotification(w.withTask(L("Session Paused")))
+		n.SetBody(pausedBackgroundStatus(int(remainingTime.Seconds())))
+	} else {
+		n = gio.NewNotification(w.withTask(L("Session Running")))
+		// TRANSLATORS: Body of the notification shown while the timer is running. The placeholder is the time of day at which the session ends, e.g. "14:35".
+		n.SetBody(fmt.Sprintf(L("Ends at %v"), time.Now().Add(remainingTime).Format("15:04")))
+	}
+	// We don't want the notification to pop up every time it is replaced
+	n.SetPriority(gio.GNotificationPriorityLowValue)
+
+	n.AddButton(L("Stop"), "app.stopTimer")
+	if paused {
+		n.AddButton(L("Resume"), "app.resumeTimer")
+	} else {
+		n.AddButton(L("Pause"), "app.pauseTimer")
+	}
+	// TRANSLATORS: Button of the notification shown while the timer is running that adds five minutes to the timer.
+	n.AddButton(L("+5 min"), "app.extendTimer")
+
+	w.app.SendNotification(runningNotificationIdVar, n)
+}
+
+// startIdleMonitor starts pausing the timer once the user is away, if enabled. The monitor
+// keeps running while paused, so that we know when the user is back.
+func (w *MainWindow) startIdleMonitor(pauseTimerAction, resumeTimerAction *gio.SimpleAction) {
+	if w.idleSource == nil || w.idleMonitor != nil || !w.settings.GetBoolean(resources.SchemaPauseWhenIdleKey) {
+		return
+	}
+
+	w.idleThreshold = time.Minute * time.Duration(w.settings.GetUint(resources.SchemaIdleThresholdKey))
+	w.idleMonitor = idle.NewMonitor(
+		w.ctx,
+		w.idleSource,
+		w.log,
+		w.idleThreshold,
+		&idle.Hooks{
+			OnIdle: func(ctx context.Context, idleSince time.Time) error {
+				var fn glib.SourceFunc
+				fn = glib.SourceFunc(func(u uintptr) bool {
+					defer glib.UnrefCallback(&fn)
+
+					// We only pause while counting down, not while the timer is stopped or alarming
+					if !pauseTimerAction.GetEnabled() {
+						return false
+					}
+
+					if err := w.s.PauseTimer(w.ctx); err != nil {
+						w.log.Error("Could not pause timer after user went away", "err", err)
+
+						return false
+					}
+
+					w.awaySince = idleSince
+
+					return false
+				})
+				glib.IdleAdd(&fn, 0)
+
+				return nil
+			},
+			OnActive: func(ctx context.Context, idleSince time.Time) error {
+				var fn glib.SourceFunc
+				fn = glib.SourceFunc(func(u uintptr) bool {
+					defer glib.UnrefCallback(&fn)
+
+					awaySince := w.awaySince
+					w.awaySince = time.Time{}
+
+					// If the timer was resumed or stopped in the meantime, there is nothing to decide
+					if awaySince.IsZero() || !resumeTimerAction.GetEnabled() {
+						return false
+					}
+
+					w.presentAwayDialog(awaySince)
+
+					return false
+				})
+				glib.IdleAdd(&fn, 0)
+
+				return nil
+			},
+		},
+	)
+
+	if err := w.idleMonitor.Start(); err != nil {
+		w.log.Warn("Could not start idle monitor", "err", err)
+
+		w.idleMonitor = nil
+	}
+}
+
+// stopIdleMonitor stops pausing the timer once the user is away
+func (w *MainWindow) stopIdleMonitor() {
+	w.awaySince = time.Time{}
+
+	if w.idleMonitor == nil {
+		return
+	}
+
+	if err := w.idleMonitor.Stop(); err != nil {
+		w.log.Warn("Could not stop idle monitor", "err", err)
+	}
+
+	w.idleMonitor = nil
+}
+
+// presentAwayDialog asks users who are back after we paused the timer what to do with the time they were away
+func (w *MainWindow) presentAwayDialog(awaySince time.Time) {
+	awayFor := time.Since(awaySince)
+	// The timer counted down until we paused it, which happened after the idle threshold
+	countedWhileAway := w.idleThreshold
+	pausedRemainingTime := time.Duration(w.dialWidget.GetRemainingTime()) * time.Second
+
+	dialog := adw.NewAlertDialog(
+		L("Welcome Back"),
+		// TRANSLATORS: Body of the dialog shown when returning to the computer after the timer was paused because the user was away. The placeholder is the number of minutes, e.g. "7".
+		fmt.Sprintf(L("You were away for %v min. Discard the session, keep the time you were away as part of it, or resume from where you left?"), int(awayFor.Round(time.Minute).Minutes())),
+	)
+	dialog.AddResponse("discard", L("_Discard"))
+	dialog.AddResponse("keep", L("_Keep"))
+	dialog.AddResponse("resume", L("_Resume"))
+	dialog.SetResponseAppearance("discard", adw.ResponseDestructiveValue)
+	dialog.SetResponseAppearance("resume", adw.ResponseSuggestedValue)
+	dialog.SetDefaultResponse("resume")
+
+	onResponse := func(dialog adw.AlertDialog, response string) {
+		var err error
+		switch response {
+		case "discard":
+			err = w.s.StopTimer(w.ctx)
+
+		case "keep":
+			// The timer didn't count while it was paused, so the session's focused time wouldn't include it either
+			if err = w.resumeAfterAway(pausedRemainingTime - (awayFor - countedWhileAway)); err == nil {
+				w.recorder.AddFocused(awayFor - countedWhileAway)
+			}
+
+		case "resume":
+			err = w.resumeAfterAway(pausedRemainingTime + countedWhileAway)
+
+		default:
+			// Closing the dialog keeps the timer paused
+			return
+		}
+
+		if err != nil {
+			w.log.Error("Could not handle response to away dialog", "response", response, "err", err)
+		}
+	}
+	dialog.ConnectResponse(&onResponse)
+
+	var cleanupCallback glib.DestroyNotify
+	cleanupCallback = func(data uintptr) {
+		defer glib.UnrefCallback(&cleanupCallback)
+
+		if err := glib.UnrefCallback(&onResponse); err != nil {
+			w.log.Error("Could not unref callback", "err", err)
+		}
+	}
+	dialog.SetDataFull(dataKeyCallbacks, uintptr(unsafe.Pointer(&onResponse)), &cleanupCallback)
+
+	w.Present()
+	dialog.Present(&w.ApplicationWindow.Widget)
+}
+
+// showTimeEntry replaces the remaining time with an entry for typing a duration
+func (w *MainWindow) showTimeEntry() {
+	w.timeEntry.SetText(w.label.GetLabel())
+	w.timeStack.SetVisibleChildName("entry")
+
+	w.timeEntry.GrabFocus()
+}
+
+// hideTimeEntry shows the remaining time again
+func (w *MainWindow) hideTimeEntry() {
+	w.timeStack.SetVisibleChildName("label")
+	w.timeEntry.RemoveCssClass("error")
+}
+
+// clampRemainingTime rounds and clamps a remaining time to a valid initial remaining time
+func clampRemainingTime(remainingTime time.Duration) time.Duration {
+	return min(
+		max(remainingTime.Round(state.RemainingTimerAdjustmentInterval), state.MinInitialRemainingTime),
+		state.MaxInitialRemainingTime,
+	)
+}
+
+// resumeAfterAway resumes the paused timer with a remaining time that accounts for the time we
+// were away, without changing the duration of the session
+func (w *MainWindow) resumeAfterAway(remainingTime time.Duration) error {
+	// If all of the remaining time was used up while we were away, the timer finishes right away
+	remainingTime = min(max(remainingTime.Truncate(time.Second), time.Second), w.s.InitialRemainingTime())
+
+	return w.s.ResumeTimerWithRemainingTime(w.ctx, remainingTime)
+}
+
+// resumeWithRemainingTime restarts the timer with a new remaining time, rounded and clamped to a valid initial remaining time
+func (w *MainWindow) resumeWithRemainingTime(remainingTime time.Duration) error {
+	remainingTime = clampRemainingTime(remainingTime)
+
+	// Dragging is the only way to set an arbitrary remaining time
+	if err := w.s.StartDragging(w.ctx); err != nil {
+		return err
+	}
+
+	return w.s.StopDragging(w.ctx, remainingTime)
+}
+
+// startAPI serves the local API in the background, if enabled
+func (w *MainWindow) startAPI() {
+	if w.apiServer != nil || !w.settings.GetBoolean(resources.SchemaAPIEnabledKey) {
+		return
+	}
+
+	// An empty token would let every client in
+	token := w.settings.GetString(resources.SchemaAPITokenKey)
+	if token == "" {
+		// This emits a change for the token, which starts the server again
+		w.settings.SetString(resources.SchemaAPITokenKey, rand.Text())
+
+		return
+	}
+
+	address := w.settings.GetString(resources.SchemaAPIAddressKey)
+	listener, err := api.Listen(address)
+	if err != nil {
+		w.log.Warn("Could not listen for local API", "address", address, "err", err)
+
+		return
+	}
+
+	server := &http.Server{
+		Handler:           api.NewServer(w.ctx, w.log, &mainThreadTimer{w}, w.apiTracker, token).Handler(),
+		ReadHeaderTimeout: apiReadHeaderTimeout,
+	}
+	w.apiServer = server
+
+	go func() {
+		w.log.Info("Serving local API", "address", listener.Addr())
+
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			w.log.Warn("Could not serve local API", "err", err)
+		}
+	}()
+}
+
+// stopAPI stops serving the local API and closes all of its connections
+func (w *MainWindow) stopAPI() {
+	if w.apiServer == nil {
+		return
+	}
+
+	if err := w.apiServer.Close(); err != nil {
+		w.log.Warn("Could not stop local API", "err", err)
+	}
+
+	w.apiServer = nil
+}
+
+// inhibitSuspend prevents the system from suspending or going idle, if enabled, since
+// the alarm would only fire after the system resumes otherwise
+func (w *MainWindow) inhibitSuspend() {
+	if w.suspendInhibitCookie != 0 || !w.settings.GetBoolean(resources.SchemaPreventSuspendKey) {
+		return
+	}
+
+	w.suspendInhibitCookie = w.app.Inhibit(
+		&w.ApplicationWindow.Window,
+		gtk.ApplicationInhibitSuspendValue|gtk.ApplicationInhibitIdleValue,
+		// TRANSLATORS: Reason given to the system for preventing it from suspending while the timer is running.
+		L("Timer is running"),
+	)
+	if w.suspendInhibitCookie == 0 {
+		w.log.Warn("Could not prevent system from suspending")
+	}
+}
+
+// uninhibitSuspend allows the system to suspend again
+func (w *MainWindow) uninhibitSuspend() {
+	if w.suspendInhibitCookie == 0 {
+		return
+	}
+
+	w.app.Uninhibit(w.suspendInhibitCookie)
+
+	w.suspendInhibitCookie = 0
+}
+
+// inhibitNotifications hides notification banners from other apps while the timer is
+// running, if do not disturb is enabled
+func (w *MainWindow) inhibitNotifications() {
+	if w.dnd == nil || !w.settings.GetBoolean(resources.SchemaDoNotDisturbKey) {
+		return
+	}
+
+	if err := w.dnd.Inhibit(); err != nil {
+		w.log.Warn("Could not enable do not disturb", "err", err)
+	}
+}
+
+// releaseNotifications restores notification banners if we hid them. It also runs when do not
+// disturb is disabled, since it might have been disabled while the timer was running.
+func (w *MainWindow) releaseNotifications() {
+	if w.dnd == nil {
+		return
+	}
+
+	if err := w.dnd.Release(); err != nil {
+		w.log.Warn("Could not disable do not disturb", "err", err)
+	}
+}
+
+// updateBackgroundStatus sets the message shown in the background apps list while we run
+// in the background. Updates within the update interval of the last one are dropped
+// unless forced.
+func (w *MainWindow) updateBackgroundStatus(message string, force bool) {
+	if !w.held {
+		return
+	}
+
+	if !force && time.Since(w.backgroundStatusUpdatedAt) < backgroundStatusUpdateInterval {
+		return
+	}
+
+	if err := background.SetStatus(background.StatusOptions{
+		Message: w.backgroundStatus(message),
+	},
+	); err != nil {
+		w.log.Error("Could not set app status via background portal", "err", err)
+
+		return
+	}
+
+	w.backgroundStatusUpdatedAt = time.Now()
+}
+
+// phaseConfig returns which phases start automatically from the settings. The break overlay
+// needs to know when breaks are, so work and breaks also alternate if it is enabled.
+func (w *MainWindow) phaseConfig() phases.Config {
+	return phases.Config{
+		BreakDuration:   time.Minute * time.Duration(w.settings.GetUint(resources.SchemaBreakDurationKey)),
+		Alternate:       w.settings.GetBoolean(resources.SchemaBreakOverlayKey),
+		AutoStartWork:   w.settings.GetBoolean(resources.SchemaAutoStartWorkKey),
+		AutoStartBreaks: w.settings.GetBoolean(resources.SchemaAutoStartBreaksKey),
+		GracePeriod:     time.Second * time.Duration(w.settings.GetUint(resources.SchemaAutoStartGracePeriodKey)),
+		Strictness:      phases.Strictness(w.settings.GetString(resources.SchemaBreakStrictnessKey)),
+	}
+}
+
+// updateBreakOverlay shows the break overlay while a break is running, if it is enabled
+func (w *MainWindow) updateBreakOverlay(running bool) {
+	if !running || w.phase != phases.PhaseBreak || w.phases.Postponed() || !w.settings.GetBoolean(resources.SchemaBreakOverlayKey) {
+		if w.breakOverlay != nil {
+			w.breakOverlay.Hide()
+		}
+
+		return
+	}
+
+	if w.breakOverlay == nil {
+		w.breakOverlay = newBreakOverlay(w.app, w.log, w.dialWidget)
+	}
+
+	w.breakOverlay.Show()
+}
+
+// updateSubtitle shows the task in the window title, or that it is a break that wasn't postponed
+func (w *MainWindow) updateSubtitle() {
+	if w.phase == phases.PhaseBreak && !w.phases.Postponed() {
+		w.windowTitle.SetSubtitle(L("Break"))
+
+		return
+	}
+
+	w.windowTitle.SetSubtitle(w.task)
+}
+
+// goal returns the focus goal from the settings
+func (w *MainWindow) goal() goals.Goal {
+	period, err := goals.ParsePeriod(w.settings.GetString(resources.SchemaGoalPeriodKey))
+	if err != nil {
+		w.log.Warn("Could not parse goal period, using daily goal", "err", err)
+
+		period = goals.PeriodDay
+	}
+
+	return goals.Goal{
+		Period:   period,
+		Sessions: int(w.settings.GetUint(resources.SchemaGoalSessionsKey)),
+		Focused:  time.Minute * time.Duration(w.settings.GetUint(resources.SchemaGoalFocusedTimeKey)),
+	}
+}
+
+// updateGoalLabel shows the progress towards the focus goal under the dial, or hides it if there is no goal
+func (w *MainWindow) updateGoalLabel(progress goals.Progress) {
+	if !progress.Goal.Enabled() {
+		w.goalLabel.SetVisible(false)
+
+		return
+	}
+
+	if progress.Reached() {
+		// TRANSLATORS: Shown under the dial once the focus goal is reached. The placeholder is the progress, e.g. "8 of 8 sessions today".
+		w.goalLabel.SetText(fmt.Sprintf(L("Goal reached: %v"), goalProgressText(progress)))
+		w.goalLabel.RemoveCssClass("dim-label")
+		w.goalLabel.AddCssClass("success")
+	} else {
+		w.goalLabel.SetText(goalProgressText(progress))
+		w.goalLabel.RemoveCssClass("success")
+		w.goalLabel.AddCssClass("dim-label")
+	}
+
+	w.goalLabel.SetVisible(true)
+}
+
+// goalProgressText describes the progress towards each target of the focus goal, e.g.
+// "3 of 8 sessions · 1 h 15 min of 4 h focused today"
+func goalProgressText(progress goals.Progress) string {
+	targets := []string{}
+	if progress.Goal.Sessions > 0 {
+		// TRANSLATORS: Progress towards the number of sessions of the focus goal, e.g. "3 of 8 sessions".
+		targets = append(targets, fmt.Sprintf(L("%v of %v sessions"), progress.Sessions, progress.Goal.Sessions))
+	}
+
+	if progress.Goal.Focused > 0 {
+		// TRANSLATORS: Progress towards the focused time of the focus goal, e.g. "1 h 15 min of 4 h focused".
+		targets = append(targets, fmt.Sprintf(L("%v of %v focused"), formatFocusedTime(progress.Focused), formatFocusedTime(progress.Goal.Focused)))
+	}
+
+	text := strings.Join(targets, " · ")
+	if progress.Goal.Period == goals.PeriodWeek {
+		// TRANSLATORS: Progress towards the weekly focus goal, e.g. "3 of 20 sessions this week".
+		return fmt.Sprintf(L("%v this week"), text)
+	}
+
+	// TRANSLATORS: Progress towards the daily focus goal, e.g. "3 of 8 sessions today".
+	return fmt.Sprintf(L("%v today"), text)
+}
+
+func formatFocusedTime(focused time.Duration) string {
+	hours, minutes := int(focused/time.Hour), int(focused%time.Hour/time.Minute)
+	switch {
+	case hours == 0:
+		// TRANSLATORS: A duration in minutes, e.g. "25 min".
+		return fmt.Sprintf(L("%v min"), minutes)
+
+	case minutes == 0:
+		// TRANSLATORS: A duration in hours, e.g. "4 h".
+		return fmt.Sprintf(L("%v h"), hours)
+
+	default:
+		// TRANSLATORS: A duration in hours and minutes, e.g. "1 h 15 min".
+		return fmt.Sprintf(L("%v h %v min"), hours, minutes)
+	}
+}
+
+// loadTasks reads the open tasks from the task file into the task menu, which is hidden if
+// there is no task file
+func (w *MainWindow) loadTasks() {
+	path := w.settings.GetString(resources.SchemaTaskFileKey)
+	if path == "" {
+		w.taskSource = nil
+		w.openTasks = nil
+		w.chosenTask = nil
+		w.taskMenu.RemoveAll()
+		w.taskButton.SetVisible(false)
+
+		return
+	}
+
+	w.taskSource = tasks.NewSource(path)
+	w.taskButton.SetVisible(true)
+
+	openTasks, err := w.taskSource.Tasks()
+	if err != nil {
+		w.log.Warn("Could not read tasks from task file", "path", path, "err", err)
+
+		openTasks = nil
+	}
+	w.openTasks = openTasks
+
+	// The chosen task changes in the file when a session for it is counted
+	if w.chosenTask != nil {
+		title := w.chosenTask.Title
+
+		w.chosenTask = nil
+		for _, task := range openTasks {
+			if task.Title == title {
+				w.chosenTask = &task
+
+				break
+			}
+		}
+	}
+
+	w.taskMenu.RemoveAll()
+	for i, task := range openTasks {
+		label := task.Title
+		if task.Pomodoros > 0 {
+			// TRANSLATORS: A task in the task menu followed by the number of sessions that were completed for it, e.g. "Write report (3)".
+			label = fmt.Sprintf(L("%v (%v)"), task.Title, task.Pomodoros)
+		}
+
+		item := gio.NewMenuItem(label, "")
+		item.SetActionAndTargetValue("win.chooseTask", glib.NewVariantInt32(int32(i)))
+		w.taskMenu.AppendItem(item)
+	}
+
+	// Items without an action can't be activated, so they are shown as a message
+	if err != nil {
+		w.taskMenu.Append(L("Could not read the task file"), "")
+	} else if len(openTasks) == 0 {
+		w.taskMenu.Append(L("No open tasks"), "")
+	}
+}
+
+// countChosenTask counts a completed session for the task that was chosen from the task file,
+// if the session was for it
+func (w *MainWindow) countChosenTask() {
+	if w.taskSource == nil || w.chosenTask == nil || w.chosenTask.Title != w.task {
+		return
+	}
+
+	if err := w.taskSource.AddPomodoro(*w.chosenTask); err != nil {
+		w.log.Warn("Could not count session in task file", "err", err)
+
+		return
+	}
+
+	w.loadTasks()
+}
+
+// withTask adds the task of the current session to a message, if there is one
+func (w *MainWindow) withTask(message string) string {
+	if w.task == "" {
+		return message
+	}
+
+	// TRANSLATORS: A message followed by the task that the session is for, e.g. "Session Running – Write report".
+	return fmt.Sprintf(L("%v – %v"), message, w.task)
+}
+
+// backgroundStatus adds the task of the current session to a background status message,
+// shortened to what the background portal accepts
+func (w *MainWindow) backgroundStatus(message string) string {
+	status := []rune(w.withTask(message))
+	if len(status) > maxBackgroundStatusLength {
+		status = append(status[:maxBackgroundStatusLength-1], '…')
+	}
+
+	return string(status)
+}
+
+// updateTray renders the dial into the tray icon and updates the tool tip. The status is
+// only changed if it is not empty.
+func (w *MainWindow) updateTray(status tray.Status) {
+	if w.tray == nil {
+		return
+	}
+
+	if status != "" {
+		if err := w.tray.SetStatus(status); err != nil {
+			w.log.Warn("Could not set tray icon status", "err", err)
+		}
+	}
+
+	styleManager := w.app.GetStyleManager()
+
+	ops := render.Scene(render.Options{
+		Width:         trayIconSize,
+		Height:        trayIconSize,
+		RemainingTime: time.Duration(w.dialWidget.GetRemainingTime()) * time.Second,
+		CountingDown:  w.dialWidget.GetCountingDown(),
+		Disabled:      !w.dialWidget.Widget.IsSensitive(),
+		HighContrast:  styleManager.GetHighContrast(),
+		Palette:       lookupPalette(&w.dialWidget.Widget, styleManager.GetDark()),
+	})
+
+	if err := w.tray.SetIcon([]tray.Pixmap{tray.NewPixmap(render.Rasterize(trayIconSize, trayIconSize, ops))}); err != nil {
+		w.log.Warn("Could not set tray icon", "err", err)
+	}
+
+	if err := w.tray.SetToolTip(L("Sessions"), formatRemainingTime(w.dialWidget.GetRemainingTime())); err != nil {
+		w.log.Warn("Could not set tray icon tool tip", "err", err)
+	}
+}
+
 func init() {
 	var windowClassInit gobject.ClassInitFunc = func(tc *gobject.TypeClass, u uintptr) {
 		typeClass := (*gtk.WidgetClass)(unsafe.Pointer(tc))
 		typeClass.SetTemplateFromResource(resources.ResourceWindowUIPath)
 
+		typeClass.BindTemplateChildFull("window_title", false, 0)
+		typeClass.BindTemplateChildFull("timer_list", false, 0)
+		typeClass.BindTemplateChildFull("time_stack", false, 0)
 		typeClass.BindTemplateChildFull("analog_time_label", false, 0)
+		typeClass.BindTemplateChildFull("analog_time_entry", false, 0)
+		typeClass.BindTemplateChildFull("goal_label", false, 0)
+		typeClass.BindTemplateChildFull("task_entry", false, 0)
+		typeClass.BindTemplateChildFull("task_button", false, 0)
 		typeClass.BindTemplateChildFull("action_button", false, 0)
 		typeClass.BindTemplateChildFull("plus_button", false, 0)
 		typeClass.BindTemplateChildFull("minus_button", false, 0)
@@ -488,16 +2233,51 @@ func init() {
 			parent.InitTemplate()
 
 			var (
+				windowTitle  adw.WindowTitle
+				timerList    gtk.ListBox
+				timeStack    gtk.Stack
 				label        gtk.Label
+				timeEntry    gtk.Entry
+				goalLabel    gtk.Label
+				taskEntry    gtk.Entry
+				taskButton   gtk.MenuButton
 				actionButton gtk.Button
 				plusButton   gtk.Button
 				minusButton  gtk.Button
 				dialArea     gtk.Box
 			)
+			parent.Widget.GetTemplateChild(
+				gTypeMainWindow,
+				"window_title",
+			).Cast(&windowTitle)
+			parent.Widget.GetTemplateChild(
+				gTypeMainWindow,
+				"timer_list",
+			).Cast(&timerList)
+			parent.Widget.GetTemplateChild(
+				gTypeMainWindow,
+				"time_stack",
+			).Cast(&timeStack)
 			parent.Widget.GetTemplateChild(
 				gTypeMainWindow,
 				"analog_time_label",
 			).Cast(&label)
+			parent.Widget.GetTemplateChild(
+				gTypeMainWindow,
+				"analog_time_entry",
+			).Cast(&timeEntry)
+			parent.Widget.GetTemplateChild(
+				gTypeMainWindow,
+				"goal_label",
+			).Cast(&goalLabel)
+			parent.Widget.GetTemplateChild(
+				gTypeMainWindow,
+				"task_entry",
+			).Cast(&taskEntry)
+			parent.Widget.GetTemplateChild(
+				gTypeMainWindow,
+				"task_button",
+			).Cast(&taskButton)
 			parent.Widget.GetTemplateChild(
 				gTypeMainWindow,
 				"action_button",
@@ -518,8 +2298,15 @@ func init() {
 			w := &MainWindow{
 				ApplicationWindow: parent,
 
+				windowTitle:  &windowTitle,
 				dialArea:     dialArea,
+				timerList:    &timerList,
+				timeStack:    &timeStack,
 				label:        &label,
+				timeEntry:    &timeEntry,
+				goalLabel:    &goalLabel,
+				taskEntry:    &taskEntry,
+				taskButton:   &taskButton,
 				actionButton: &actionButton,
 				plusButton:   &plusButton,
 				minusButton:  &minusButton,
@@ -0,0 +1,52 @@
+// Package dbustest provides private message buses for tests of D-Bus services
+package dbustest
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// StartBus starts a private message bus and returns its address. The test is skipped if
+// dbus-daemon is not installed.
+func StartBus(t *testing.T) string {
+	t.Helper()
+
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	cmd := exec.Command(daemon, "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+
+	return strings.TrimSpace(address)
+}
+
+// Connect connects to the bus at address and closes the connection once the test is done
+func Connect(t *testing.T, address string) *dbus.Conn {
+	t.Helper()
+
+	conn, err := dbus.Connect(address)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
@@ -37,6 +37,10 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == components.ExportCommand {
+		os.Exit(components.RunExport(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
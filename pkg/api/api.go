@@ -0,0 +1,321 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/state"
+)
+
+const (
+	// Prefix of addresses that refer to a Unix socket instead of a TCP address
+	UnixAddressPrefix = "unix:"
+
+	// Query parameter that can be used instead of the authorization header, since browsers
+	// can't set headers for event streams
+	TokenQueryParameter = "token"
+
+	// Interval at which comments are sent on event streams so that proxies don't close them
+	keepaliveInterval = time.Second * 15
+
+	// Number of events that are buffered for slow event stream clients before they miss events
+	subscriberBufferSize = 16
+)
+
+var (
+	ErrNotLoopback = errors.New("address is not a loopback address")
+	ErrNotSocket   = errors.New("path exists but is not a socket")
+)
+
+// Timer is the part of the state machine that the API controls
+type Timer interface {
+	StartTimer(ctx context.Context) error
+	StartTimerWithLabel(ctx context.Context, label string) error
+	StopTimer(ctx context.Context) error
+	StopAlarming(ctx context.Context) error
+	PlusTimer(ctx context.Context) error
+	MinusTimer(ctx context.Context) error
+	// SetDuration sets the duration of the next session while the timer is stopped, and
+	// restarts the current session with it otherwise
+	SetDuration(ctx context.Context, duration time.Duration) error
+}
+
+// State is the state of the timer as seen by API clients
+type State string
+
+const (
+	StateStopped  State = "stopped"
+	StateRunning  State = "running"
+	StatePaused   State = "paused"
+	StateAlarming State = "alarming"
+)
+
+// Status is the JSON representation of the timer. Durations are in seconds.
+type Status struct {
+	State     State        `json:"state"`
+	Duration  int64        `json:"duration"`
+	Remaining int64        `json:"remaining"`
+	Label     string       `json:"label"`
+	Goal      GoalProgress `json:"goal"`
+}
+
+// GoalProgress is the JSON representation of the progress towards the focus goal. Durations
+// are in seconds, and targets that aren't part of the goal are zero.
+type GoalProgress struct {
+	Period         string `json:"period"`
+	Sessions       int    `json:"sessions"`
+	TargetSessions int    `json:"targetSessions"`
+	Focused        int64  `json:"focused"`
+	TargetFocused  int64  `json:"targetFocused"`
+	Reached        bool   `json:"reached"`
+}
+
+// StartRequest is the optional JSON body of a request that starts the timer. The label of the
+// session, e.g. the task it is for, is kept if it is omitted.
+type StartRequest struct {
+	Label *string `json:"label"`
+}
+
+// DurationRequest is the JSON body of a request that sets the duration of the timer in seconds
+type DurationRequest struct {
+	Duration int64 `json:"duration"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server serves the local API
+type Server struct {
+	ctx     context.Context
+	log     *slog.Logger
+	timer   Timer
+	tracker *Tracker
+	token   string
+}
+
+// NewServer creates a new server. Requests need to pass the token as a bearer token.
+func NewServer(ctx context.Context, log *slog.Logger, timer Timer, tracker *Tracker, token string) *Server {
+	return &Server{
+		ctx:     ctx,
+		log:     log,
+		timer:   timer,
+		tracker: tracker,
+		token:   token,
+	}
+}
+
+// Listen listens on a loopback TCP address or, if the address starts with UnixAddressPrefix, on a Unix socket
+func Listen(address string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(address, UnixAddressPrefix); ok {
+		// Sockets of previous runs that didn't exit cleanly would prevent us from listening,
+		// but anything else at the path is most likely a typo we must not delete
+		if info, err := os.Lstat(path); err == nil {
+			if info.Mode()&os.ModeSocket == 0 {
+				return nil, fmt.Errorf("%w: %v", ErrNotSocket, path)
+			}
+
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		return net.Listen("unix", path)
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("%w: %v", ErrNotLoopback, address)
+	}
+
+	return net.Listen("tcp", address)
+}
+
+// Handler returns the HTTP handler of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /events", s.handleEvents)
+
+	mux.HandleFunc("POST /start", s.handleStart)
+	mux.HandleFunc("POST /stop", s.handleAction(s.stop))
+	mux.HandleFunc("POST /add", s.handleAction(s.timer.PlusTimer))
+	mux.HandleFunc("POST /remove", s.handleAction(s.timer.MinusTimer))
+
+	mux.HandleFunc("PUT /duration", s.handleDuration)
+
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			token = r.URL.Query().Get(TokenQueryParameter)
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			s.writeJSON(w, http.StatusUnauthorized, errorResponse{"invalid token"})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		s.log.Debug("Could not write API response", "err", err)
+	}
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.tracker.Status())
+}
+
+// stop stops the alarm if it is ringing and the timer otherwise, the same as the action button does
+func (s *Server) stop(ctx context.Context) error {
+	if s.tracker.Status().State == StateAlarming {
+		return s.timer.StopAlarming(ctx)
+	}
+
+	return s.timer.StopTimer(ctx)
+}
+
+func (s *Server) handleAction(action func(ctx context.Context) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// The state machine outlives the request, so the transition must not be canceled with it
+		if err := action(s.ctx); err != nil {
+			s.writeJSON(w, http.StatusConflict, errorResponse{err.Error()})
+
+			return
+		}
+
+		s.writeJSON(w, http.StatusOK, s.tracker.Status())
+	}
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req StartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+
+		return
+	}
+
+	if req.Label == nil {
+		s.handleAction(s.timer.StartTimer)(w, r)
+
+		return
+	}
+
+	label := strings.TrimSpace(*req.Label)
+	s.handleAction(func(ctx context.Context) error {
+		return s.timer.StartTimerWithLabel(ctx, label)
+	})(w, r)
+}
+
+func (s *Server) handleDuration(w http.ResponseWriter, r *http.Request) {
+	var req DurationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+
+		return
+	}
+
+	duration := time.Duration(req.Duration) * time.Second
+	if duration < state.MinInitialRemainingTime ||
+		duration > state.MaxInitialRemainingTime ||
+		duration%state.RemainingTimerAdjustmentInterval != 0 {
+		s.writeJSON(w, http.StatusBadRequest, errorResponse{fmt.Sprintf(
+			"duration must be between %v and %v seconds and a multiple of %v seconds",
+			state.MinInitialRemainingTime.Seconds(),
+			state.MaxInitialRemainingTime.Seconds(),
+			state.RemainingTimerAdjustmentInterval.Seconds(),
+		)})
+
+		return
+	}
+
+	s.handleAction(func(ctx context.Context) error {
+		return s.timer.SetDuration(ctx, duration)
+	})(w, r)
+}
+
+func writeEvent(w http.ResponseWriter, event Event) error {
+	data, err := json.Marshal(event.Status)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %v\ndata: %s\n\n", event.Name, data)
+
+	return err
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	events := s.tracker.Subscribe()
+	defer s.tracker.Unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+
+	// Clients need to know the status before they get the first change
+	if err := writeEvent(w, Event{"status", s.tracker.Status()}); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-s.ctx.Done():
+			return
+
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+
+		case event := <-events:
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
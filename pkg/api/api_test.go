@@ -0,0 +1,429 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neilotoole/slogt"
+	"github.com/pojntfx/sessions/pkg/goals"
+	"github.com/pojntfx/sessions/pkg/state"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testToken = "token"
+)
+
+func startServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	log := slogt.New(t)
+
+	tracker := NewTracker(log, state.DefaultInitialRemainingTime)
+	s := state.NewStateMachine(t.Context(), state.DefaultInitialRemainingTime, log, state.CombineHooks(tracker.Hooks()))
+	t.Cleanup(func() {
+		_ = s.StopTimer(context.Background())
+	})
+
+	server := httptest.NewServer(NewServer(t.Context(), log, testTimer{s}, tracker, testToken).Handler())
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// testTimer sets durations like the app does, which restarts running sessions with them
+type testTimer struct {
+	*state.StateMachine
+}
+
+func (t testTimer) SetDuration(ctx context.Context, duration time.Duration) error {
+	ok, err := t.CanSetInitialRemainingTime(ctx, duration)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		return t.SetInitialRemainingTime(ctx, duration)
+	}
+
+	if err := t.StartDragging(ctx); err != nil {
+		return err
+	}
+
+	return t.StopDragging(ctx, duration)
+}
+
+func request(t *testing.T, server *httptest.Server, method, path, token, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), method, server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	content, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	return res.StatusCode, string(content)
+}
+
+func decodeStatus(t *testing.T, body string) Status {
+	t.Helper()
+
+	var status Status
+	require.NoError(t, json.Unmarshal([]byte(body), &status))
+
+	return status
+}
+
+func TestAuthentication(t *testing.T) {
+	server := startServer(t)
+
+	code, _ := request(t, server, http.MethodGet, "/status", "", "")
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = request(t, server, http.MethodGet, "/status", "wrong", "")
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = request(t, server, http.MethodGet, "/status", testToken, "")
+	require.Equal(t, http.StatusOK, code)
+
+	code, _ = request(t, server, http.MethodGet, "/status?"+TokenQueryParameter+"="+testToken, "", "")
+	require.Equal(t, http.StatusOK, code)
+}
+
+var requestTests = []struct {
+	name   string
+	method string
+	path   string
+	body   string
+	code   int
+	status Status
+}{
+	{
+		name:   "status",
+		method: http.MethodGet,
+		path:   "/status",
+		code:   http.StatusOK,
+		status: Status{State: StateStopped, Duration: 300, Remaining: 300},
+	},
+	{
+		name:   "add",
+		method: http.MethodPost,
+		path:   "/add",
+		code:   http.StatusOK,
+		status: Status{State: StateStopped, Duration: 330, Remaining: 330},
+	},
+	{
+		name:   "remove",
+		method: http.MethodPost,
+		path:   "/remove",
+		code:   http.StatusOK,
+		status: Status{State: StateStopped, Duration: 300, Remaining: 300},
+	},
+	{
+		name:   "set duration",
+		method: http.MethodPut,
+		path:   "/duration",
+		body:   `{"duration": 1500}`,
+		code:   http.StatusOK,
+		status: Status{State: StateStopped, Duration: 1500, Remaining: 1500},
+	},
+	{
+		name:   "set invalid duration",
+		method: http.MethodPut,
+		path:   "/duration",
+		body:   `{"duration": 1501}`,
+		code:   http.StatusBadRequest,
+	},
+	{
+		name:   "set malformed duration",
+		method: http.MethodPut,
+		path:   "/duration",
+		body:   `{"duration": "25m"}`,
+		code:   http.StatusBadRequest,
+	},
+	{
+		name:   "start",
+		method: http.MethodPost,
+		path:   "/start",
+		code:   http.StatusOK,
+		status: Status{State: StateRunning, Duration: 1500, Remaining: 1500},
+	},
+	{
+		name:   "start while running",
+		method: http.MethodPost,
+		path:   "/start",
+		code:   http.StatusConflict,
+	},
+	{
+		name:   "set duration while running",
+		method: http.MethodPut,
+		path:   "/duration",
+		body:   `{"duration": 600}`,
+		code:   http.StatusOK,
+		status: Status{State: StateRunning, Duration: 600, Remaining: 600},
+	},
+	{
+		name:   "stop",
+		method: http.MethodPost,
+		path:   "/stop",
+		code:   http.StatusOK,
+		status: Status{State: StateStopped, Duration: 600, Remaining: 600},
+	},
+	{
+		name:   "reset duration",
+		method: http.MethodPut,
+		path:   "/duration",
+		body:   `{"duration": 1500}`,
+		code:   http.StatusOK,
+		status: Status{State: StateStopped, Duration: 1500, Remaining: 1500},
+	},
+	{
+		name:   "wrong method",
+		method: http.MethodGet,
+		path:   "/start",
+		code:   http.StatusMethodNotAllowed,
+	},
+	{
+		name:   "start with malformed label",
+		method: http.MethodPost,
+		path:   "/start",
+		body:   `{"label": 1}`,
+		code:   http.StatusBadRequest,
+	},
+	{
+		name:   "start with label",
+		method: http.MethodPost,
+		path:   "/start",
+		body:   `{"label": " Write docs "}`,
+		code:   http.StatusOK,
+		status: Status{State: StateRunning, Duration: 1500, Remaining: 1500, Label: "Write docs"},
+	},
+	{
+		name:   "stop keeps label",
+		method: http.MethodPost,
+		path:   "/stop",
+		code:   http.StatusOK,
+		status: Status{State: StateStopped, Duration: 1500, Remaining: 1500, Label: "Write docs"},
+	},
+	{
+		name:   "start without label keeps label",
+		method: http.MethodPost,
+		path:   "/start",
+		code:   http.StatusOK,
+		status: Status{State: StateRunning, Duration: 1500, Remaining: 1500, Label: "Write docs"},
+	},
+}
+
+func TestRequests(t *testing.T) {
+	server := startServer(t)
+
+	// The requests build on each other, so they share a server
+	for _, tt := range requestTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				code, body := request(t, server, tt.method, tt.path, testToken, tt.body)
+				require.Equal(t, tt.code, code, body)
+
+				if tt.code == http.StatusOK {
+					require.Equal(t, tt.status, decodeStatus(t, body))
+				}
+			},
+		)
+	}
+}
+
+type sseEvent struct {
+	name   string
+	status Status
+}
+
+func readEvent(t *testing.T, reader *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var event sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return event
+
+		case strings.HasPrefix(line, "event: "):
+			event.name = strings.TrimPrefix(line, "event: ")
+
+		case strings.HasPrefix(line, "data: "):
+			event.status = decodeStatus(t, strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	server := startServer(t)
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second*10)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events?"+TokenQueryParameter+"="+testToken, nil)
+	require.NoError(t, err)
+
+	res, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(res.Body)
+
+	require.Equal(t, sseEvent{"status", Status{StateStopped, 300, 300, "", GoalProgress{}}}, readEvent(t, reader))
+
+	code, _ := request(t, server, http.MethodPost, "/start", testToken, "")
+	require.Equal(t, http.StatusOK, code)
+
+	require.Equal(t, sseEvent{"start", Status{StateRunning, 300, 300, "", GoalProgress{}}}, readEvent(t, reader))
+
+	// The timer ticks every second
+	tick := readEvent(t, reader)
+	require.Equal(t, "tick", tick.name)
+	require.Equal(t, StateRunning, tick.status.State)
+	require.Less(t, tick.status.Remaining, int64(300))
+
+	code, _ = request(t, server, http.MethodPost, "/stop", testToken, "")
+	require.Equal(t, http.StatusOK, code)
+
+	for {
+		if event := readEvent(t, reader); event.name != "tick" {
+			require.Equal(t, sseEvent{"stop", Status{StateStopped, 300, 300, "", GoalProgress{}}}, event)
+
+			break
+		}
+	}
+}
+
+func TestGoalProgress(t *testing.T) {
+	tracker := NewTracker(slogt.New(t), state.DefaultInitialRemainingTime)
+
+	events := tracker.Subscribe()
+	defer tracker.Unsubscribe(events)
+
+	tracker.SetGoalProgress(goals.Progress{
+		Goal: goals.Goal{
+			Period:   goals.PeriodWeek,
+			Sessions: 20,
+		},
+		Sessions: 20,
+		Focused:  time.Hour * 9,
+	})
+
+	expected := GoalProgress{
+		Period:         "week",
+		Sessions:       20,
+		TargetSessions: 20,
+		Focused:        32400,
+		Reached:        true,
+	}
+
+	event := <-events
+	require.Equal(t, "goal", event.Name)
+	require.Equal(t, expected, event.Status.Goal)
+	require.Equal(t, expected, tracker.Status().Goal)
+}
+
+func TestListen(t *testing.T) {
+	var listenTests = []struct {
+		name      string
+		address   string
+		expectErr error
+	}{
+		{
+			name:    "loopback address",
+			address: "127.0.0.1:0",
+		},
+		{
+			name:    "localhost",
+			address: "localhost:0",
+		},
+		{
+			name:      "non-loopback address",
+			address:   "0.0.0.0:0",
+			expectErr: ErrNotLoopback,
+		},
+		{
+			name:      "hostname",
+			address:   "example.com:0",
+			expectErr: ErrNotLoopback,
+		},
+		{
+			name:    "unix socket",
+			address: UnixAddressPrefix + filepath.Join(t.TempDir(), "api.sock"),
+		},
+	}
+	for _, tt := range listenTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				listener, err := Listen(tt.address)
+				if tt.expectErr != nil {
+					require.ErrorIs(t, err, tt.expectErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+				t.Cleanup(func() {
+					_ = listener.Close()
+				})
+
+				_, isUnix := listener.Addr().(*net.UnixAddr)
+				require.Equal(t, strings.HasPrefix(tt.address, UnixAddressPrefix), isUnix)
+			},
+		)
+	}
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	require.NoError(t, err)
+
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	listener, err := Listen(UnixAddressPrefix + path)
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+}
+
+func TestListenKeepsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	require.NoError(t, os.WriteFile(path, []byte("Write docs\n"), 0o600))
+
+	_, err := Listen(UnixAddressPrefix + path)
+	require.ErrorIs(t, err, ErrNotSocket)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "Write docs\n", string(content))
+}
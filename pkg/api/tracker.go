@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/goals"
+	"github.com/pojntfx/sessions/pkg/state"
+)
+
+// Event is sent to event stream clients whenever the status changes
+type Event struct {
+	Name   string
+	Status Status
+}
+
+// Tracker keeps track of the status through its hooks and sends changes to subscribers.
+// It is separate from the server since it needs to track the status while the server isn't running.
+type Tracker struct {
+	log *slog.Logger
+
+	lock        sync.Mutex
+	status      Status
+	subscribers map[chan Event]struct{}
+}
+
+func NewTracker(log *slog.Logger, initialRemainingTime time.Duration) *Tracker {
+	return &Tracker{
+		log: log,
+
+		status: Status{
+			State:     StateStopped,
+			Duration:  int64(initialRemainingTime.Seconds()),
+			Remaining: int64(initialRemainingTime.Seconds()),
+		},
+		subscribers: map[chan Event]struct{}{},
+	}
+}
+
+// Status returns the current status
+func (t *Tracker) Status() Status {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.status
+}
+
+// Subscribe returns a channel that receives all status changes until it is unsubscribed
+func (t *Tracker) Subscribe() chan Event {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	events := make(chan Event, subscriberBufferSize)
+	t.subscribers[events] = struct{}{}
+
+	return events
+}
+
+func (t *Tracker) Unsubscribe(events chan Event) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.subscribers, events)
+}
+
+// update changes the status and sends it to all event stream clients
+func (t *Tracker) update(name string, change func(status *Status)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	change(&t.status)
+
+	for events := range t.subscribers {
+		// We never block the state machine on slow clients
+		select {
+		case events <- Event{name, t.status}:
+		default:
+			t.log.Debug("Dropping API event for slow client", "event", name)
+		}
+	}
+}
+
+// SetGoalProgress updates the progress towards the focus goal
+func (t *Tracker) SetGoalProgress(progress goals.Progress) {
+	t.update("goal", func(status *Status) {
+		status.Goal = GoalProgress{
+			Period:         string(progress.Goal.Period),
+			Sessions:       progress.Sessions,
+			TargetSessions: progress.Goal.Sessions,
+			Focused:        int64(progress.Focused.Seconds()),
+			TargetFocused:  int64(progress.Goal.Focused.Seconds()),
+			Reached:        progress.Reached(),
+		}
+	})
+}
+
+// Hooks returns state machine hooks that keep the status up to date, which can be combined with other hooks
+func (t *Tracker) Hooks() *state.Hooks {
+	return &state.Hooks{
+		OnStartTimer: func(ctx context.Context) error {
+			t.update("start", func(status *Status) {
+				status.State = StateRunning
+				status.Remaining = status.Duration
+			})
+
+			return nil
+		},
+		OnStopTimer: func(ctx context.Context) error {
+			t.update("stop", func(status *Status) {
+				status.State = StateStopped
+				status.Remaining = status.Duration
+			})
+
+			return nil
+		},
+
+		OnPauseTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			t.update("pause", func(status *Status) {
+				status.State = StatePaused
+				status.Remaining = int64(currentRemainingTime.Seconds())
+			})
+
+			return nil
+		},
+		OnResumeTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			t.update("resume", func(status *Status) {
+				status.State = StateRunning
+				status.Remaining = int64(currentRemainingTime.Seconds())
+			})
+
+			return nil
+		},
+
+		OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
+			t.update("duration", func(status *Status) {
+				status.Duration = int64(initialRemainingTime.Seconds())
+				if status.State == StateStopped {
+					status.Remaining = status.Duration
+				}
+			})
+
+			return nil
+		},
+		OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			t.update("tick", func(status *Status) {
+				status.Remaining = int64(currentRemainingTime.Seconds())
+			})
+
+			return nil
+		},
+
+		OnLabelChange: func(ctx context.Context, label string) error {
+			t.update("label", func(status *Status) {
+				status.Label = label
+			})
+
+			return nil
+		},
+
+		OnStartAlarm: func(ctx context.Context) error {
+			t.update("alarmStart", func(status *Status) {
+				status.State = StateAlarming
+				status.Remaining = 0
+			})
+
+			return nil
+		},
+		OnStopAlarm: func(ctx context.Context) error {
+			t.update("alarmStop", func(status *Status) {
+				status.State = StateStopped
+				status.Remaining = status.Duration
+			})
+
+			return nil
+		},
+	}
+}
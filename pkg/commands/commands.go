@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/state"
+)
+
+// Event is a timer lifecycle event that commands can be run for
+type Event string
+
+const (
+	EventStart      Event = "start"
+	EventStop       Event = "stop"
+	EventAlarmStart Event = "alarm-start"
+	EventAlarmStop  Event = "alarm-stop"
+)
+
+const (
+	EnvEvent     = "SESSIONS_EVENT"
+	EnvDuration  = "SESSIONS_DURATION"
+	EnvRemaining = "SESSIONS_REMAINING"
+
+	// Time to wait for the output of a command to be closed after it was killed
+	waitDelay = time.Second
+)
+
+// Runner runs shell commands for timer lifecycle events. Commands run in the background
+// so that they can't block the timer.
+type Runner struct {
+	ctx   context.Context
+	log   *slog.Logger
+	shell string
+
+	lock      sync.Mutex
+	commands  map[Event]string
+	timeout   time.Duration
+	duration  time.Duration
+	remaining time.Duration
+	// Whether a session is running, since the timer also starts again after its time was adjusted
+	running bool
+
+	wg sync.WaitGroup
+}
+
+// NewRunner creates a runner that runs commands with shell, which must support `-c`
+func NewRunner(ctx context.Context, log *slog.Logger, shell string, timeout, initialRemainingTime time.Duration) *Runner {
+	return &Runner{
+		ctx:   ctx,
+		log:   log,
+		shell: shell,
+
+		commands:  map[Event]string{},
+		timeout:   timeout,
+		duration:  initialRemainingTime,
+		remaining: initialRemainingTime,
+	}
+}
+
+// SetCommand sets the command that runs for an event. Empty commands aren't run.
+func (r *Runner) SetCommand(event Event, command string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.commands[event] = command
+}
+
+// SetTimeout sets the time after which commands are killed
+func (r *Runner) SetTimeout(timeout time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.timeout = timeout
+}
+
+// Run runs the command for an event in the background, if one is set
+func (r *Runner) Run(event Event) {
+	r.lock.Lock()
+	command, timeout, duration, remaining := strings.TrimSpace(r.commands[event]), r.timeout, r.duration, r.remaining
+	r.lock.Unlock()
+
+	if command == "" {
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ctx, cancel := context.WithTimeout(r.ctx, timeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, r.shell, "-c", command)
+		cmd.Env = append(
+			os.Environ(),
+			EnvEvent+"="+string(event),
+			fmt.Sprintf("%v=%d", EnvDuration, int(duration.Seconds())),
+			fmt.Sprintf("%v=%d", EnvRemaining, int(remaining.Seconds())),
+		)
+		cmd.WaitDelay = waitDelay
+
+		r.log.Debug("Running command", "event", event, "command", command)
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				r.log.Warn("Command timed out", "event", event, "command", command, "timeout", timeout, "output", string(output))
+
+				return
+			}
+
+			r.log.Warn("Command failed", "event", event, "command", command, "err", err, "output", string(output))
+
+			return
+		}
+
+		r.log.Info("Command finished", "event", event, "command", command, "output", string(output))
+	}()
+}
+
+// Wait waits for all running commands to finish
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+// Hooks returns state machine hooks that run the commands, which can be combined with
+// other hooks. The stop command also runs when the timer finishes, before the alarm
+// start command.
+func (r *Runner) Hooks() *state.Hooks {
+	return &state.Hooks{
+		OnStartTimer: func(ctx context.Context) error {
+			r.lock.Lock()
+			r.remaining = r.duration
+			running := r.running
+			r.running = true
+			r.lock.Unlock()
+
+			// Adjusting the time continues the session
+			if !running {
+				r.Run(EventStart)
+			}
+
+			return nil
+		},
+		OnStopTimer: func(ctx context.Context) error {
+			r.lock.Lock()
+			r.running = false
+			r.lock.Unlock()
+
+			r.Run(EventStop)
+
+			return nil
+		},
+
+		OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+
+			r.duration = initialRemainingTime
+
+			return nil
+		},
+		OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+
+			r.remaining = currentRemainingTime
+
+			return nil
+		},
+
+		OnStartAlarm: func(ctx context.Context) error {
+			// The alarm only starts once the timer ran out, even if we missed the last tick
+			r.lock.Lock()
+			r.remaining = 0
+			r.lock.Unlock()
+
+			r.Run(EventAlarmStart)
+
+			return nil
+		},
+		OnStopAlarm: func(ctx context.Context) error {
+			r.Run(EventAlarmStop)
+
+			return nil
+		},
+	}
+}
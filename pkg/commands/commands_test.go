@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/neilotoole/slogt"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testShell   = "sh"
+	testTimeout = time.Second * 5
+)
+
+func newTestRunner(t *testing.T, timeout time.Duration) *Runner {
+	t.Helper()
+
+	if _, err := exec.LookPath(testShell); err != nil {
+		t.Skip("sh is not installed")
+	}
+
+	r := NewRunner(t.Context(), slogt.New(t), testShell, timeout, time.Minute*25)
+	t.Cleanup(r.Wait)
+
+	return r
+}
+
+// recordEnvironment returns a command that appends the variables passed to it to a file
+func recordEnvironment(path string) string {
+	return `echo "$SESSIONS_EVENT $SESSIONS_DURATION $SESSIONS_REMAINING" >> ` + path
+}
+
+func readLines(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ""
+	}
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+var hooksTests = []struct {
+	name     string
+	commands map[Event]bool
+	run      func(t *testing.T, r *Runner)
+	expected string
+}{
+	{
+		name:     "start passes the duration",
+		commands: map[Event]bool{EventStart: true},
+		run: func(t *testing.T, r *Runner) {
+			require.NoError(t, r.Hooks().OnStartTimer(t.Context()))
+		},
+		expected: "start 1500 1500\n",
+	},
+	{
+		name:     "stop passes the remaining time",
+		commands: map[Event]bool{EventStart: true, EventStop: true},
+		run: func(t *testing.T, r *Runner) {
+			h := r.Hooks()
+
+			require.NoError(t, h.OnInitialRemainingTimeChange(t.Context(), time.Minute*10))
+			require.NoError(t, h.OnStartTimer(t.Context()))
+			r.Wait()
+
+			require.NoError(t, h.OnCurrentRemainingTimeTick(t.Context(), time.Minute*4))
+			require.NoError(t, h.OnStopTimer(t.Context()))
+		},
+		expected: "start 600 600\nstop 600 240\n",
+	},
+	{
+		name:     "adjusting the time doesn't start the session again",
+		commands: map[Event]bool{EventStart: true, EventStop: true},
+		run: func(t *testing.T, r *Runner) {
+			h := r.Hooks()
+
+			require.NoError(t, h.OnStartTimer(t.Context()))
+			r.Wait()
+
+			// The timer starts again with the adjusted time
+			require.NoError(t, h.OnInitialRemainingTimeChange(t.Context(), time.Minute*30))
+			require.NoError(t, h.OnStartTimer(t.Context()))
+
+			require.NoError(t, h.OnStopTimer(t.Context()))
+			r.Wait()
+
+			require.NoError(t, h.OnStartTimer(t.Context()))
+		},
+		expected: "start 1500 1500\nstop 1800 1800\nstart 1800 1800\n",
+	},
+	{
+		name:     "alarm start and stop have no remaining time",
+		commands: map[Event]bool{EventAlarmStart: true, EventAlarmStop: true},
+		run: func(t *testing.T, r *Runner) {
+			h := r.Hooks()
+
+			require.NoError(t, h.OnCurrentRemainingTimeTick(t.Context(), time.Second))
+			require.NoError(t, h.OnStartAlarm(t.Context()))
+			r.Wait()
+
+			require.NoError(t, h.OnStopAlarm(t.Context()))
+		},
+		expected: "alarm-start 1500 0\nalarm-stop 1500 0\n",
+	},
+	{
+		name:     "events without commands are skipped",
+		commands: map[Event]bool{EventStop: true},
+		run: func(t *testing.T, r *Runner) {
+			h := r.Hooks()
+
+			require.NoError(t, h.OnStartTimer(t.Context()))
+			require.NoError(t, h.OnStartAlarm(t.Context()))
+		},
+		expected: "",
+	},
+}
+
+func TestHooks(t *testing.T) {
+	for _, tt := range hooksTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				r := newTestRunner(t, testTimeout)
+
+				output := filepath.Join(t.TempDir(), "output")
+				for event, enabled := range tt.commands {
+					if enabled {
+						r.SetCommand(event, recordEnvironment(output))
+					}
+				}
+
+				tt.run(t, r)
+				r.Wait()
+
+				require.Equal(t, tt.expected, readLines(t, output))
+			},
+		)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	r := newTestRunner(t, testTimeout)
+
+	output := filepath.Join(t.TempDir(), "output")
+	r.SetCommand(EventStart, "exit 1")
+	r.SetCommand(EventStop, recordEnvironment(output))
+
+	// A failing command doesn't affect other commands
+	r.Run(EventStart)
+	r.Run(EventStop)
+	r.Wait()
+
+	require.Equal(t, "stop 1500 1500\n", readLines(t, output))
+}
+
+func TestRunTimeout(t *testing.T) {
+	r := newTestRunner(t, time.Millisecond*100)
+
+	output := filepath.Join(t.TempDir(), "output")
+	r.SetCommand(EventStart, "sleep 10 && "+recordEnvironment(output))
+
+	start := time.Now()
+	r.Run(EventStart)
+	r.Wait()
+
+	require.Less(t, time.Since(start), time.Second*5)
+	require.Empty(t, readLines(t, output))
+
+	r.SetTimeout(testTimeout)
+	r.SetCommand(EventStart, recordEnvironment(output))
+
+	r.Run(EventStart)
+	r.Wait()
+
+	require.Equal(t, "start 1500 1500\n", readLines(t, output))
+}
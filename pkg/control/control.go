@@ -0,0 +1,200 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/pojntfx/sessions/pkg/duration"
+	"github.com/pojntfx/sessions/pkg/goals"
+)
+
+const (
+	Interface = "com.pojtinger.felicitas.Sessions.Control"
+
+	invalidArgsError = "org.freedesktop.DBus.Error.InvalidArgs"
+
+	goalProgressChangedSignal = "GoalProgressChanged"
+)
+
+var (
+	ErrAlreadyOpen  = errors.New("control service is already open")
+	ErrNameNotOwned = errors.New("could not become primary owner of control service name")
+)
+
+type Hooks struct {
+	// OnSetDuration is called with a valid duration when a client sets the duration of the timer
+	OnSetDuration func(ctx context.Context, duration time.Duration) error
+}
+
+// Service lets other programs and scripts control the timer over D-Bus
+type Service struct {
+	ctx   context.Context
+	conn  *dbus.Conn
+	log   *slog.Logger
+	hooks *Hooks
+
+	name  string
+	path  dbus.ObjectPath
+	units duration.Units
+
+	lock         sync.Mutex
+	open         bool
+	goalProgress goals.Progress
+}
+
+// NewService creates a new control service on a connection. The service is only exported and
+// owns its name once it is opened.
+func NewService(
+	ctx context.Context,
+	conn *dbus.Conn,
+	log *slog.Logger,
+	name string,
+	path dbus.ObjectPath,
+	units duration.Units,
+	hooks *Hooks,
+) *Service {
+	return &Service{
+		ctx:   ctx,
+		conn:  conn,
+		log:   log,
+		hooks: hooks,
+
+		name:  name,
+		path:  path,
+		units: units,
+	}
+}
+
+// Open exports the service and requests its name
+func (s *Service) Open() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.open {
+		return ErrAlreadyOpen
+	}
+
+	if err := s.conn.Export(s, s.path, Interface); err != nil {
+		return err
+	}
+
+	if err := s.conn.Export(introspect.NewIntrospectable(&introspect.Node{
+		Name: string(s.path),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			{
+				Name:    Interface,
+				Methods: introspect.Methods(s),
+				Signals: []introspect.Signal{
+					{
+						Name: goalProgressChangedSignal,
+						Args: []introspect.Arg{
+							{Name: "progress", Type: "a{sv}"},
+						},
+					},
+				},
+			},
+		},
+	}), s.path, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return err
+	}
+
+	reply, err := s.conn.RequestName(s.name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return err
+	}
+
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return ErrNameNotOwned
+	}
+
+	s.open = true
+
+	return nil
+}
+
+// Close releases the service's name and unexports it
+func (s *Service) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.open {
+		return nil
+	}
+
+	if _, err := s.conn.ReleaseName(s.name); err != nil {
+		return err
+	}
+
+	for _, iface := range []string{Interface, "org.freedesktop.DBus.Introspectable"} {
+		if err := s.conn.Export(nil, s.path, iface); err != nil {
+			return err
+		}
+	}
+
+	s.open = false
+
+	return nil
+}
+
+// SetDuration sets the duration of the timer from a duration such as "25", "1h30" or "17:30".
+// Durations that can't be parsed or are out of range are rejected as invalid arguments.
+func (s *Service) SetDuration(value string) *dbus.Error {
+	d, err := duration.Parse(value, s.units)
+	if err != nil {
+		return dbus.NewError(invalidArgsError, []any{err.Error()})
+	}
+
+	if s.hooks.OnSetDuration == nil {
+		return nil
+	}
+
+	if err := s.hooks.OnSetDuration(s.ctx, d); err != nil {
+		s.log.Warn("Could not set duration", "duration", d, "err", err)
+
+		return dbus.MakeFailedError(err)
+	}
+
+	return nil
+}
+
+// GetGoalProgress returns the progress towards the focus goal. Durations are in seconds, and
+// targets that aren't part of the goal are zero.
+func (s *Service) GetGoalProgress() (map[string]dbus.Variant, *dbus.Error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return goalProgressToVariants(s.goalProgress), nil
+}
+
+// SetGoalProgress updates the progress towards the focus goal and notifies clients about it
+func (s *Service) SetGoalProgress(progress goals.Progress) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.goalProgress = progress
+
+	if !s.open {
+		return
+	}
+
+	if err := s.conn.Emit(s.path, Interface+"."+goalProgressChangedSignal, goalProgressToVariants(progress)); err != nil {
+		s.log.Warn("Could not emit goal progress", "err", err)
+	}
+}
+
+func goalProgressToVariants(progress goals.Progress) map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"period":         dbus.MakeVariant(string(progress.Goal.Period)),
+		"sessions":       dbus.MakeVariant(uint32(progress.Sessions)),
+		"targetSessions": dbus.MakeVariant(uint32(progress.Goal.Sessions)),
+		"focused":        dbus.MakeVariant(uint64(progress.Focused.Seconds())),
+		"targetFocused":  dbus.MakeVariant(uint64(progress.Goal.Focused.Seconds())),
+		"reached":        dbus.MakeVariant(progress.Reached()),
+	}
+}
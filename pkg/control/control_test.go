@@ -0,0 +1,173 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/neilotoole/slogt"
+	"github.com/pojntfx/sessions/internal/dbustest"
+	"github.com/pojntfx/sessions/pkg/duration"
+	"github.com/pojntfx/sessions/pkg/goals"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testName = "com.example.Test.Control"
+	testPath = dbus.ObjectPath("/com/example/Test/Control")
+)
+
+func openService(t *testing.T, conn *dbus.Conn, hooks *Hooks) *Service {
+	t.Helper()
+
+	s := NewService(t.Context(), conn, slogt.New(t), testName, testPath, duration.NewUnits("s", "min;Minuten", "h"), hooks)
+	require.NoError(t, s.Open())
+
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+	})
+
+	return s
+}
+
+var setDurationTests = []struct {
+	name      string
+	value     string
+	expected  time.Duration
+	expectErr string
+}{
+	{
+		name:     "number",
+		value:    "25",
+		expected: time.Minute * 25,
+	},
+	{
+		name:     "clock time",
+		value:    "17:30",
+		expected: time.Minute*17 + time.Second*30,
+	},
+	{
+		name:     "localized unit",
+		value:    "10 Minuten",
+		expected: time.Minute * 10,
+	},
+	{
+		name:     "duration is snapped",
+		value:    "10 min 10 s",
+		expected: time.Minute * 10,
+	},
+	{
+		name:      "duration above the maximum",
+		value:     "2h",
+		expectErr: invalidArgsError,
+	},
+	{
+		name:      "invalid duration",
+		value:     "soon",
+		expectErr: invalidArgsError,
+	},
+}
+
+func TestSetDuration(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	durations := make(chan time.Duration, 1)
+	openService(t, dbustest.Connect(t, address), &Hooks{
+		OnSetDuration: func(ctx context.Context, duration time.Duration) error {
+			durations <- duration
+
+			return nil
+		},
+	})
+
+	service := dbustest.Connect(t, address).Object(testName, testPath)
+
+	for _, tt := range setDurationTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				err := service.Call(Interface+".SetDuration", 0, tt.value).Err
+				if tt.expectErr != "" {
+					var dbusErr dbus.Error
+					require.ErrorAs(t, err, &dbusErr)
+					require.Equal(t, tt.expectErr, dbusErr.Name)
+					require.Empty(t, durations)
+
+					return
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, <-durations)
+			},
+		)
+	}
+}
+
+func TestSetDurationFails(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	openService(t, dbustest.Connect(t, address), &Hooks{
+		OnSetDuration: func(ctx context.Context, duration time.Duration) error {
+			return errors.New("timer is alarming")
+		},
+	})
+
+	require.Error(t, dbustest.Connect(t, address).Object(testName, testPath).Call(Interface+".SetDuration", 0, "25").Err)
+}
+
+func TestGoalProgress(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	s := openService(t, dbustest.Connect(t, address), &Hooks{})
+
+	client := dbustest.Connect(t, address)
+	require.NoError(t, client.AddMatchSignal(dbus.WithMatchInterface(Interface), dbus.WithMatchMember(goalProgressChangedSignal)))
+
+	signals := make(chan *dbus.Signal, 1)
+	client.Signal(signals)
+
+	s.SetGoalProgress(goals.Progress{
+		Goal: goals.Goal{
+			Period:   goals.PeriodDay,
+			Sessions: 8,
+			Focused:  time.Hour * 4,
+		},
+		Sessions: 3,
+		Focused:  time.Minute * 75,
+	})
+
+	expected := map[string]dbus.Variant{
+		"period":         dbus.MakeVariant("day"),
+		"sessions":       dbus.MakeVariant(uint32(3)),
+		"targetSessions": dbus.MakeVariant(uint32(8)),
+		"focused":        dbus.MakeVariant(uint64(4500)),
+		"targetFocused":  dbus.MakeVariant(uint64(14400)),
+		"reached":        dbus.MakeVariant(false),
+	}
+
+	select {
+	case signal := <-signals:
+		require.Equal(t, Interface+"."+goalProgressChangedSignal, signal.Name)
+		require.Equal(t, []any{expected}, signal.Body)
+
+	case <-time.After(time.Second * 5):
+		require.FailNow(t, "goal progress signal was not emitted")
+	}
+
+	var progress map[string]dbus.Variant
+	require.NoError(t, client.Object(testName, testPath).Call(Interface+".GetGoalProgress", 0).Store(&progress))
+	require.Equal(t, expected, progress)
+}
+
+func TestOpen(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	s := openService(t, dbustest.Connect(t, address), &Hooks{})
+	require.ErrorIs(t, s.Open(), ErrAlreadyOpen)
+
+	// Only one service can own the name
+	other := NewService(t.Context(), dbustest.Connect(t, address), slogt.New(t), testName, testPath, duration.Units{}, &Hooks{})
+	require.ErrorIs(t, other.Open(), ErrNameNotOwned)
+}
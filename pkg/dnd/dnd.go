@@ -0,0 +1,104 @@
+package dnd
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Backend reads and writes whether the desktop shows notification banners
+type Backend interface {
+	ShowBanners() (bool, error)
+	SetShowBanners(showBanners bool) error
+}
+
+// Store persists the value that was set before do not disturb was enabled, so that it
+// can still be restored if the app crashes while do not disturb is enabled
+type Store interface {
+	// Load returns the persisted value, and whether there is one
+	Load() (showBanners bool, ok bool, err error)
+	Save(showBanners bool) error
+	Clear() error
+}
+
+// Inhibitor suppresses notification banners while enabled and restores the previous
+// setting once released
+type Inhibitor struct {
+	backend Backend
+	store   Store
+	log     *slog.Logger
+
+	lock sync.Mutex
+}
+
+func NewInhibitor(backend Backend, store Store, log *slog.Logger) *Inhibitor {
+	return &Inhibitor{
+		backend: backend,
+		store:   store,
+		log:     log,
+	}
+}
+
+// Inhibit hides notification banners. Calling it while already inhibited keeps the
+// originally persisted value, so that it isn't overwritten with our own.
+func (i *Inhibitor) Inhibit() error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	_, inhibited, err := i.store.Load()
+	if err != nil {
+		return err
+	}
+
+	if !inhibited {
+		showBanners, err := i.backend.ShowBanners()
+		if err != nil {
+			return err
+		}
+
+		// We need to persist the previous value before changing it, otherwise a crash
+		// in between would lose it
+		if err := i.store.Save(showBanners); err != nil {
+			return err
+		}
+	}
+
+	i.log.Debug("Inhibiting notification banners")
+
+	return i.backend.SetShowBanners(false)
+}
+
+// Release restores the value that was set before Inhibit was called. It is a no-op if
+// banners aren't inhibited.
+func (i *Inhibitor) Release() error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	showBanners, inhibited, err := i.store.Load()
+	if err != nil {
+		return err
+	}
+
+	if !inhibited {
+		return nil
+	}
+
+	i.log.Debug("Restoring notification banners", "showBanners", showBanners)
+
+	if err := i.backend.SetShowBanners(showBanners); err != nil {
+		return err
+	}
+
+	return i.store.Clear()
+}
+
+// Recover restores the previous value if a previous run exited while banners were
+// inhibited. It should be called once at startup.
+func (i *Inhibitor) Recover() error {
+	if _, inhibited, err := i.store.Load(); err != nil || !inhibited {
+		return err
+	}
+
+	i.log.Info("Restoring notification banners that were inhibited when the app last exited")
+
+	return i.Release()
+}
@@ -0,0 +1,182 @@
+package dnd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/neilotoole/slogt"
+	"github.com/stretchr/testify/require"
+)
+
+var errBackend = errors.New("backend error")
+
+type fakeBackend struct {
+	showBanners bool
+	failSet     bool
+	sets        []bool
+}
+
+func (b *fakeBackend) ShowBanners() (bool, error) {
+	return b.showBanners, nil
+}
+
+func (b *fakeBackend) SetShowBanners(showBanners bool) error {
+	if b.failSet {
+		return errBackend
+	}
+
+	b.showBanners = showBanners
+	b.sets = append(b.sets, showBanners)
+
+	return nil
+}
+
+func newTestInhibitor(t *testing.T, backend Backend) (*Inhibitor, *FileStore) {
+	t.Helper()
+
+	store := NewFileStore(filepath.Join(t.TempDir(), "state", "dnd.json"))
+
+	return NewInhibitor(backend, store, slogt.New(t)), store
+}
+
+var inhibitorTests = []struct {
+	name        string
+	showBanners bool
+	run         func(t *testing.T, i *Inhibitor)
+	sets        []bool
+}{
+	{
+		name:        "inhibit and release restores enabled banners",
+		showBanners: true,
+		run: func(t *testing.T, i *Inhibitor) {
+			require.NoError(t, i.Inhibit())
+			require.NoError(t, i.Release())
+		},
+		sets: []bool{false, true},
+	},
+	{
+		name:        "inhibit and release restores disabled banners",
+		showBanners: false,
+		run: func(t *testing.T, i *Inhibitor) {
+			require.NoError(t, i.Inhibit())
+			require.NoError(t, i.Release())
+		},
+		sets: []bool{false, false},
+	},
+	{
+		name:        "inhibiting twice keeps the original value",
+		showBanners: true,
+		run: func(t *testing.T, i *Inhibitor) {
+			require.NoError(t, i.Inhibit())
+			require.NoError(t, i.Inhibit())
+			require.NoError(t, i.Release())
+		},
+		sets: []bool{false, false, true},
+	},
+	{
+		name:        "release without inhibit does nothing",
+		showBanners: true,
+		run: func(t *testing.T, i *Inhibitor) {
+			require.NoError(t, i.Release())
+		},
+		sets: nil,
+	},
+	{
+		name:        "releasing twice only restores once",
+		showBanners: true,
+		run: func(t *testing.T, i *Inhibitor) {
+			require.NoError(t, i.Inhibit())
+			require.NoError(t, i.Release())
+			require.NoError(t, i.Release())
+		},
+		sets: []bool{false, true},
+	},
+	{
+		name:        "recover without inhibit does nothing",
+		showBanners: false,
+		run: func(t *testing.T, i *Inhibitor) {
+			require.NoError(t, i.Recover())
+		},
+		sets: nil,
+	},
+}
+
+func TestInhibitor(t *testing.T) {
+	for _, tt := range inhibitorTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				backend := &fakeBackend{showBanners: tt.showBanners}
+				i, _ := newTestInhibitor(t, backend)
+
+				tt.run(t, i)
+
+				require.Equal(t, tt.sets, backend.sets)
+				require.Equal(t, tt.showBanners, backend.showBanners)
+			},
+		)
+	}
+}
+
+func TestRecoverAfterCrash(t *testing.T) {
+	backend := &fakeBackend{showBanners: true}
+	i, store := newTestInhibitor(t, backend)
+
+	require.NoError(t, i.Inhibit())
+	require.False(t, backend.showBanners)
+
+	// A new inhibitor that shares the store is what the next run after a crash sees
+	recovered := NewInhibitor(backend, store, slogt.New(t))
+	require.NoError(t, recovered.Recover())
+	require.True(t, backend.showBanners)
+
+	_, inhibited, err := store.Load()
+	require.NoError(t, err)
+	require.False(t, inhibited)
+}
+
+func TestInhibitFailure(t *testing.T) {
+	backend := &fakeBackend{showBanners: true, failSet: true}
+	i, store := newTestInhibitor(t, backend)
+
+	require.ErrorIs(t, i.Inhibit(), errBackend)
+
+	// The previous value is persisted before it is changed, so it can be restored later
+	showBanners, inhibited, err := store.Load()
+	require.NoError(t, err)
+	require.True(t, inhibited)
+	require.True(t, showBanners)
+
+	backend.failSet = false
+	require.NoError(t, i.Release())
+	require.True(t, backend.showBanners)
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "dnd.json")
+	store := NewFileStore(path)
+
+	_, ok, err := store.Load()
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, store.Save(true))
+
+	showBanners, ok, err := store.Load()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.True(t, showBanners)
+
+	require.NoError(t, store.Clear())
+	require.NoError(t, store.Clear())
+
+	_, ok, err = store.Load()
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, _, err = store.Load()
+	require.Error(t, err)
+}
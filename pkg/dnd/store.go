@@ -0,0 +1,67 @@
+package dnd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+type persistedState struct {
+	ShowBanners bool `json:"showBanners"`
+}
+
+// FileStore is a store that persists the previous value in a JSON file
+type FileStore struct {
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path}
+}
+
+func (s *FileStore) Load() (bool, bool, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, false, nil
+		}
+
+		return false, false, err
+	}
+
+	var state persistedState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return false, false, err
+	}
+
+	return state.ShowBanners, true, nil
+}
+
+func (s *FileStore) Save(showBanners bool) error {
+	content, err := json.Marshal(persistedState{showBanners})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	// Writing to a temporary file and renaming it makes sure that we never leave a
+	// partially written file behind
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+func (s *FileStore) Clear() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package duration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pojntfx/sessions/pkg/state"
+)
+
+var (
+	ErrEmpty         = errors.New("empty duration")
+	ErrInvalidNumber = errors.New("invalid number in duration")
+	ErrUnknownUnit   = errors.New("unknown unit in duration")
+	ErrMissingNumber = errors.New("missing number in duration")
+	ErrInvalidClock  = errors.New("invalid clock time in duration")
+	ErrTooShort      = errors.New("duration is too short")
+	ErrTooLong       = errors.New("duration is too long")
+)
+
+// Units maps unit words to the units they stand for
+type Units map[string]time.Duration
+
+// DefaultUnits are the English unit words, which are always accepted
+var DefaultUnits = Units{
+	"s":       time.Second,
+	"sec":     time.Second,
+	"secs":    time.Second,
+	"second":  time.Second,
+	"seconds": time.Second,
+
+	"m":       time.Minute,
+	"min":     time.Minute,
+	"mins":    time.Minute,
+	"minute":  time.Minute,
+	"minutes": time.Minute,
+
+	"h":     time.Hour,
+	"hr":    time.Hour,
+	"hrs":   time.Hour,
+	"hour":  time.Hour,
+	"hours": time.Hour,
+}
+
+// NewUnits creates units from lists of words separated by semicolons, e.g. "min;minute;minutes",
+// which is the format that translations of unit words use
+func NewUnits(seconds, minutes, hours string) Units {
+	units := Units{}
+	for unit, words := range map[time.Duration]string{
+		time.Second: seconds,
+		time.Minute: minutes,
+		time.Hour:   hours,
+	} {
+		for word := range strings.SplitSeq(words, ";") {
+			if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+				units[word] = unit
+			}
+		}
+	}
+
+	return units
+}
+
+func (u Units) lookup(word string) (time.Duration, bool) {
+	if unit, ok := u[word]; ok {
+		return unit, true
+	}
+
+	unit, ok := DefaultUnits[word]
+
+	return unit, ok
+}
+
+// smallerUnit returns the unit that a number without a unit has if it follows a number with the given unit,
+// so that "1h30" is one hour and thirty minutes
+func smallerUnit(unit time.Duration) time.Duration {
+	switch unit {
+	case time.Hour:
+		return time.Minute
+
+	default:
+		return time.Second
+	}
+}
+
+// splitFunc splits off the longest prefix whose runes all satisfy f
+func splitFunc(s []rune, f func(rune) bool) ([]rune, []rune) {
+	i := 0
+	for i < len(s) && f(s[i]) {
+		i++
+	}
+
+	return s[:i], s[i:]
+}
+
+// parseClock parses durations in the "MM:SS" and "HH:MM:SS" formats
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, ErrInvalidClock
+	}
+
+	var duration time.Duration
+	for i, part := range parts {
+		value, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return 0, errors.Join(ErrInvalidClock, err)
+		}
+
+		// Only the first part can overflow, e.g. "90:00" is ninety minutes
+		if i > 0 && value >= 60 {
+			return 0, ErrInvalidClock
+		}
+
+		duration = duration*60 + time.Duration(value)
+	}
+
+	if duration > math.MaxInt64/time.Second {
+		return 0, ErrTooLong
+	}
+
+	return duration * time.Second, nil
+}
+
+// ParseRaw parses a duration without snapping it or checking its range and returns whether
+// any of its numbers had an explicit unit. It only fails for durations that are too long to
+// represent. It accepts numbers with units such as "25m", "90 seconds"
+// or "1h30", and clock times such as "17:30" or "1:30:00". Numbers without a unit are minutes,
+// unless they follow a number with a unit, in which case they are of the next smaller unit.
+func ParseRaw(s string, units Units) (time.Duration, bool, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, false, ErrEmpty
+	}
+
+	if strings.Contains(s, ":") {
+		duration, err := parseClock(s)
+
+		return duration, false, err
+	}
+
+	var (
+		duration    time.Duration
+		hasUnit     bool
+		defaultUnit = time.Minute
+	)
+
+	rest := []rune(s)
+	for len(rest) > 0 {
+		// Some languages use a decimal comma
+		number, after := splitFunc(rest, func(r rune) bool { return unicode.IsDigit(r) || r == '.' || r == ',' })
+		if len(number) == 0 {
+			return 0, false, ErrMissingNumber
+		}
+
+		value, err := strconv.ParseFloat(strings.ReplaceAll(string(number), ",", "."), 64)
+		if err != nil {
+			return 0, false, errors.Join(ErrInvalidNumber, err)
+		}
+
+		_, after = splitFunc(after, unicode.IsSpace)
+		word, after := splitFunc(after, unicode.IsLetter)
+
+		unit := defaultUnit
+		if len(word) > 0 {
+			var ok bool
+			if unit, ok = units.lookup(string(word)); !ok {
+				return 0, false, fmt.Errorf("%w: %v", ErrUnknownUnit, string(word))
+			}
+
+			hasUnit = true
+		}
+
+		// Converting values that don't fit into a duration would overflow
+		nanoseconds := value * float64(unit)
+		if nanoseconds >= float64(math.MaxInt64-duration) {
+			return 0, false, ErrTooLong
+		}
+
+		duration += time.Duration(nanoseconds)
+		defaultUnit = smallerUnit(unit)
+
+		_, rest = splitFunc(after, unicode.IsSpace)
+	}
+
+	return duration, hasUnit, nil
+}
+
+// Parse parses a duration that a timer can be set to. The duration is snapped to the closest
+// multiple of the adjustment interval, and an error is returned if it is out of range afterwards.
+func Parse(s string, units Units) (time.Duration, error) {
+	duration, _, err := ParseRaw(s, units)
+	if err != nil {
+		return 0, err
+	}
+
+	return Snap(duration)
+}
+
+// Snap snaps a duration to the closest multiple of the adjustment interval and checks whether
+// a timer can be set to it
+func Snap(duration time.Duration) (time.Duration, error) {
+	duration = duration.Round(state.RemainingTimerAdjustmentInterval)
+
+	if duration < state.MinInitialRemainingTime {
+		return 0, fmt.Errorf("%w: must be at least %v", ErrTooShort, state.MinInitialRemainingTime)
+	}
+
+	if duration > state.MaxInitialRemainingTime {
+		return 0, fmt.Errorf("%w: must be at most %v", ErrTooLong, state.MaxInitialRemainingTime)
+	}
+
+	return duration, nil
+}
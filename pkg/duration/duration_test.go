@@ -0,0 +1,256 @@
+package duration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testUnits = NewUnits("s;Sekunde;Sekunden", "min;Minute;Minuten", "std;Stunde;Stunden")
+
+var parseRawTests = []struct {
+	name      string
+	input     string
+	expected  time.Duration
+	hasUnit   bool
+	expectErr error
+}{
+	{
+		name:     "number without unit is minutes",
+		input:    "25",
+		expected: time.Minute * 25,
+	},
+	{
+		name:     "short unit",
+		input:    "25m",
+		expected: time.Minute * 25,
+		hasUnit:  true,
+	},
+	{
+		name:     "unit separated by a space",
+		input:    "25 min",
+		expected: time.Minute * 25,
+		hasUnit:  true,
+	},
+	{
+		name:     "long unit",
+		input:    "90 seconds",
+		expected: time.Second * 90,
+		hasUnit:  true,
+	},
+	{
+		name:     "fractional number",
+		input:    "1.5h",
+		expected: time.Minute * 90,
+		hasUnit:  true,
+	},
+	{
+		name:     "decimal comma",
+		input:    "2,5 min",
+		expected: time.Second * 150,
+		hasUnit:  true,
+	},
+	{
+		name:     "multiple numbers with units",
+		input:    "1h 30m",
+		expected: time.Minute * 90,
+		hasUnit:  true,
+	},
+	{
+		name:     "number after hours is minutes",
+		input:    "1h30",
+		expected: time.Minute * 90,
+		hasUnit:  true,
+	},
+	{
+		name:     "number after minutes is seconds",
+		input:    "5 min 30",
+		expected: time.Minute*5 + time.Second*30,
+		hasUnit:  true,
+	},
+	{
+		name:     "localized unit",
+		input:    "1 Stunde 15 Minuten",
+		expected: time.Minute * 75,
+		hasUnit:  true,
+	},
+	{
+		name:     "english units are accepted with localized units",
+		input:    "20 minutes",
+		expected: time.Minute * 20,
+		hasUnit:  true,
+	},
+	{
+		name:     "minutes and seconds",
+		input:    "17:30",
+		expected: time.Minute*17 + time.Second*30,
+	},
+	{
+		name:     "hours, minutes and seconds",
+		input:    "1:30:00",
+		expected: time.Minute * 90,
+	},
+	{
+		name:     "first part of a clock time can overflow",
+		input:    "90:00",
+		expected: time.Minute * 90,
+	},
+	{
+		name:     "case and surrounding spaces are ignored",
+		input:    "  10 MIN ",
+		expected: time.Minute * 10,
+		hasUnit:  true,
+	},
+	{
+		name:      "empty",
+		input:     " ",
+		expectErr: ErrEmpty,
+	},
+	{
+		name:      "unknown unit",
+		input:     "25 days",
+		expectErr: ErrUnknownUnit,
+	},
+	{
+		name:      "missing number",
+		input:     "min",
+		expectErr: ErrMissingNumber,
+	},
+	{
+		name:      "invalid number",
+		input:     "1.2.3",
+		expectErr: ErrInvalidNumber,
+	},
+	{
+		name:      "number too large for a duration",
+		input:     "99999999999999999999 hours",
+		expectErr: ErrTooLong,
+	},
+	{
+		name:      "sum too large for a duration",
+		input:     "2562047h 2562047h",
+		expectErr: ErrTooLong,
+	},
+	{
+		name:      "clock time too large for a duration",
+		input:     "4294967295:00:00",
+		expectErr: ErrTooLong,
+	},
+	{
+		name:      "clock time with too many parts",
+		input:     "1:00:00:00",
+		expectErr: ErrInvalidClock,
+	},
+	{
+		name:      "clock time with overflowing seconds",
+		input:     "10:75",
+		expectErr: ErrInvalidClock,
+	},
+	{
+		name:      "clock time with unit",
+		input:     "10:00 min",
+		expectErr: ErrInvalidClock,
+	},
+}
+
+func TestParseRaw(t *testing.T) {
+	for _, tt := range parseRawTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				duration, hasUnit, err := ParseRaw(tt.input, testUnits)
+				if tt.expectErr != nil {
+					require.ErrorIs(t, err, tt.expectErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, duration)
+				require.Equal(t, tt.hasUnit, hasUnit)
+			},
+		)
+	}
+}
+
+var parseTests = []struct {
+	name      string
+	input     string
+	expected  time.Duration
+	expectErr error
+}{
+	{
+		name:     "valid duration",
+		input:    "25",
+		expected: time.Minute * 25,
+	},
+	{
+		name:     "duration is snapped down to the adjustment interval",
+		input:    "10m 10s",
+		expected: time.Minute * 10,
+	},
+	{
+		name:     "duration is snapped up to the adjustment interval",
+		input:    "10m 20s",
+		expected: time.Minute*10 + time.Second*30,
+	},
+	{
+		name:     "duration that is snapped to the minimum",
+		input:    "20 seconds",
+		expected: time.Second * 30,
+	},
+	{
+		name:     "maximum duration",
+		input:    "1h",
+		expected: time.Hour,
+	},
+	{
+		name:      "duration below the minimum",
+		input:     "10s",
+		expectErr: ErrTooShort,
+	},
+	{
+		name:      "duration above the maximum",
+		input:     "1:30:00",
+		expectErr: ErrTooLong,
+	},
+	{
+		name:      "duration too large to represent",
+		input:     "99999999999999999999 hours",
+		expectErr: ErrTooLong,
+	},
+	{
+		name:      "invalid duration",
+		input:     "soon",
+		expectErr: ErrMissingNumber,
+	},
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range parseTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				duration, err := Parse(tt.input, testUnits)
+				if tt.expectErr != nil {
+					require.ErrorIs(t, err, tt.expectErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, duration)
+			},
+		)
+	}
+}
+
+func TestNewUnits(t *testing.T) {
+	require.Equal(t, Units{
+		"s":      time.Second,
+		"min":    time.Minute,
+		"minute": time.Minute,
+		"h":      time.Hour,
+	}, NewUnits("s", " Min ; minute;", "h"))
+}
@@ -0,0 +1,204 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/history"
+)
+
+type Format string
+
+const (
+	// FormatCSV is a table for spreadsheets with one session per row
+	FormatCSV Format = "csv"
+	// FormatJSON is an array of sessions for other programs
+	FormatJSON Format = "json"
+	// FormatICS is an iCalendar file with one event per session for calendars
+	FormatICS Format = "ics"
+
+	calendarProductID = "-//Felicitas Pojtinger//Sessions//EN"
+	calendarUIDSuffix = "@com.pojtinger.felicitas.Sessions"
+
+	// Lines in iCalendar files should not be longer than this many bytes, without the line break
+	calendarMaxLineLength = 75
+	calendarTimeLayout    = "20060102T150405Z"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown export format")
+
+	Formats = []Format{FormatCSV, FormatJSON, FormatICS}
+
+	csvHeader = []string{"start", "end", "focused", "label", "completed", "break"}
+)
+
+// ParseFormat returns the format with a name, e.g. "ics"
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %v", ErrUnknownFormat, name)
+}
+
+// Extension returns the file extension for a format, e.g. ".ics"
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// Since returns the sessions that started at or after a time
+func Since(sessions []history.Session, since time.Time) []history.Session {
+	filtered := []history.Session{}
+	for _, session := range sessions {
+		if !session.Start.Before(since) {
+			filtered = append(filtered, session)
+		}
+	}
+
+	return filtered
+}
+
+// Write writes sessions in a format. Calendar events for sessions without a label use the
+// untitled summary instead.
+func Write(w io.Writer, format Format, sessions []history.Session, untitled string) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, sessions)
+
+	case FormatJSON:
+		return WriteJSON(w, sessions)
+
+	case FormatICS:
+		return WriteICS(w, sessions, untitled)
+
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownFormat, format)
+	}
+}
+
+// WriteCSV writes sessions as a table with a header row. Times are in RFC 3339 format and
+// the focused time is in seconds.
+func WriteCSV(w io.Writer, sessions []history.Session) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := cw.Write([]string{
+			session.Start.Format(time.RFC3339),
+			session.End.Format(time.RFC3339),
+			strconv.FormatInt(session.Focused, 10),
+			session.Label,
+			strconv.FormatBool(session.Completed),
+			strconv.FormatBool(session.Break),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// WriteJSON writes sessions as a JSON array in the same format as the history
+func WriteJSON(w io.Writer, sessions []history.Session) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sessions)
+}
+
+// WriteICS writes work sessions as iCalendar events, see RFC 5545. Breaks are left out.
+func WriteICS(w io.Writer, sessions []history.Session, untitled string) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + calendarProductID,
+		"CALSCALE:GREGORIAN",
+	}
+
+	for _, session := range sessions {
+		if session.Break {
+			continue
+		}
+
+		summary := session.Label
+		if summary == "" {
+			summary = untitled
+		}
+
+		start := session.Start.UTC().Format(calendarTimeLayout)
+		end := session.End.UTC().Format(calendarTimeLayout)
+
+		lines = append(
+			lines,
+			"BEGIN:VEVENT",
+			// Exporting the same session again updates its event instead of adding another one
+			"UID:"+start+calendarUIDSuffix,
+			// Sessions are recorded when they end
+			"DTSTAMP:"+end,
+			"DTSTART:"+start,
+			"DTEND:"+end,
+			"SUMMARY:"+escapeCalendarText(summary),
+			"TRANSP:OPAQUE",
+			"END:VEVENT",
+		)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldCalendarLine(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// escapeCalendarText escapes the characters that have a meaning in iCalendar text values
+func escapeCalendarText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// foldCalendarLine splits a line that is too long into multiple lines that continue with a
+// space, without splitting characters
+func foldCalendarLine(line string) string {
+	var (
+		folded strings.Builder
+		length int
+	)
+	for _, r := range line {
+		size := len(string(r))
+
+		if length+size > calendarMaxLineLength {
+			folded.WriteString("\r\n ")
+
+			// The space counts towards the length of the continued line
+			length = 1
+		}
+
+		folded.WriteRune(r)
+		length += size
+	}
+
+	return folded.String()
+}
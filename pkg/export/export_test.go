@@ -0,0 +1,165 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/history"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	start    = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	sessions = []history.Session{
+		{
+			Start:     start,
+			End:       start.Add(time.Minute * 25),
+			Focused:   int64((time.Minute * 25).Seconds()),
+			Label:     "Write report, then review it; \"quickly\"",
+			Completed: true,
+		},
+		{
+			Start:     start.Add(time.Minute * 25),
+			End:       start.Add(time.Minute * 30),
+			Focused:   int64((time.Minute * 5).Seconds()),
+			Completed: true,
+			Break:     true,
+		},
+		{
+			Start:   start.Add(time.Hour),
+			End:     start.Add(time.Hour + time.Minute*10),
+			Focused: int64((time.Minute * 8).Seconds()),
+		},
+	}
+)
+
+var writeTests = []struct {
+	name     string
+	format   Format
+	expected string
+}{
+	{
+		name:   "csv",
+		format: FormatCSV,
+		expected: `start,end,focused,label,completed,break
+2026-10-01T09:00:00Z,2026-10-01T09:25:00Z,1500,"Write report, then review it; ""quickly""",true,false
+2026-10-01T09:25:00Z,2026-10-01T09:30:00Z,300,,true,true
+2026-10-01T10:00:00Z,2026-10-01T10:10:00Z,480,,false,false
+`,
+	},
+	{
+		name:   "json",
+		format: FormatJSON,
+		expected: `[
+  {
+    "start": "2026-10-01T09:00:00Z",
+    "end": "2026-10-01T09:25:00Z",
+    "focused": 1500,
+    "label": "Write report, then review it; \"quickly\"",
+    "completed": true
+  },
+  {
+    "start": "2026-10-01T09:25:00Z",
+    "end": "2026-10-01T09:30:00Z",
+    "focused": 300,
+    "completed": true,
+    "break": true
+  },
+  {
+    "start": "2026-10-01T10:00:00Z",
+    "end": "2026-10-01T10:10:00Z",
+    "focused": 480,
+    "completed": false
+  }
+]
+`,
+	},
+	{
+		name:   "ics",
+		format: FormatICS,
+		expected: strings.ReplaceAll(`BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Felicitas Pojtinger//Sessions//EN
+CALSCALE:GREGORIAN
+BEGIN:VEVENT
+UID:20261001T090000Z@com.pojtinger.felicitas.Sessions
+DTSTAMP:20261001T092500Z
+DTSTART:20261001T090000Z
+DTEND:20261001T092500Z
+SUMMARY:Write report\, then review it\; "quickly"
+TRANSP:OPAQUE
+END:VEVENT
+BEGIN:VEVENT
+UID:20261001T100000Z@com.pojtinger.felicitas.Sessions
+DTSTAMP:20261001T101000Z
+DTSTART:20261001T100000Z
+DTEND:20261001T101000Z
+SUMMARY:Session
+TRANSP:OPAQUE
+END:VEVENT
+END:VCALENDAR
+`, "\n", "\r\n"),
+	},
+}
+
+func TestWrite(t *testing.T) {
+	for _, tt := range writeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, tt.format, sessions, "Session"))
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	require.ErrorIs(t, Write(&bytes.Buffer{}, Format("xml"), sessions, "Session"), ErrUnknownFormat)
+}
+
+var parseFormatTests = []struct {
+	name     string
+	value    string
+	expected Format
+	err      error
+}{
+	{"lowercase", "ics", FormatICS, nil},
+	{"uppercase", "CSV", FormatCSV, nil},
+	{"unknown", "xml", "", ErrUnknownFormat},
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, tt := range parseFormatTests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := ParseFormat(tt.value)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, format)
+		})
+	}
+}
+
+func TestSince(t *testing.T) {
+	require.Equal(t, sessions, Since(sessions, start))
+	require.Equal(t, sessions[1:], Since(sessions, start.Add(time.Minute)))
+	require.Equal(t, sessions[2:], Since(sessions, start.Add(time.Minute*30)))
+	require.Empty(t, Since(sessions, start.Add(time.Hour*2)))
+}
+
+func TestFoldCalendarLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("ä", 40)
+
+	folded := foldCalendarLine(line)
+	for _, l := range strings.Split(folded, "\r\n") {
+		require.LessOrEqual(t, len(l), calendarMaxLineLength)
+	}
+
+	// Unfolding the lines returns the original line
+	require.Equal(t, line, strings.ReplaceAll(folded, "\r\n ", ""))
+}
@@ -0,0 +1,163 @@
+package geometry
+
+import (
+	"math"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/state"
+)
+
+const (
+	// DeadZoneRadius is the radius around the center of the dial in which positions
+	// are ignored, since the angle is too unstable to derive a remaining time from there
+	DeadZoneRadius = 15.0
+	// TrackInset is the distance between the edge of the dial and its track
+	TrackInset = 15.0
+
+	// Revolution is the remaining time that one full turn of the dial represents
+	Revolution = time.Hour
+	// RingSpacing is the distance between the concentric rings that represent
+	// remaining times longer than one revolution
+	RingSpacing = 14.0
+
+	// TickInterval is the remaining time between two tick marks
+	TickInterval = time.Minute
+	// MajorTickInterval is the remaining time between two major tick marks, which can also be labeled
+	MajorTickInterval = time.Minute * 5
+
+	// Positions on the circle that are exactly on an interval boundary can end up slightly below
+	// the boundary after a round trip through floating point trigonometry, which would snap them
+	// to the previous interval
+	intervalEpsilon = 1e-9
+)
+
+// Center returns the center of a dial with the given size
+func Center(width, height float64) (cx, cy float64) {
+	return width / 2, height / 2
+}
+
+// Radius returns the radius of the track of a dial with the given size
+func Radius(width, height float64) float64 {
+	cx, cy := Center(width, height)
+
+	return math.Min(cx, cy) - TrackInset
+}
+
+// Intervals returns the number of adjustment intervals in one full turn of the dial
+func Intervals() int {
+	return int(Revolution / state.RemainingTimerAdjustmentInterval)
+}
+
+// NormalizeAngle maps an angle to [0, 2π)
+func NormalizeAngle(angle float64) float64 {
+	angle = math.Mod(angle, 2*math.Pi)
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+
+	// Adding 2π to a tiny negative angle can round up to exactly 2π
+	if angle >= 2*math.Pi {
+		angle = 0
+	}
+
+	return angle
+}
+
+// PositionToAngle returns the angle of a position on a dial with the given size,
+// measured clockwise from 12 o'clock in [0, 2π). It returns false if the position
+// is within the dead zone.
+func PositionToAngle(width, height, x, y float64) (float64, bool) {
+	cx, cy := Center(width, height)
+	dx, dy := x-cx, y-cy
+
+	// This also catches NaN positions
+	if !(math.Sqrt(dx*dx+dy*dy) >= DeadZoneRadius) {
+		return 0, false
+	}
+
+	return NormalizeAngle(math.Atan2(dy, dx) + math.Pi/2), true
+}
+
+// AngleToPosition returns the position on a circle with the given center and radius
+// for an angle measured clockwise from 12 o'clock
+func AngleToPosition(cx, cy, r, angle float64) (x, y float64) {
+	return cx + r*math.Sin(angle), cy - r*math.Cos(angle)
+}
+
+// AngleToRemainingTime snaps an angle measured clockwise from 12 o'clock down to the
+// previous adjustment interval. Since the minimum remaining time is one interval, the
+// zero angle maps to a full revolution instead.
+func AngleToRemainingTime(angle float64) time.Duration {
+	intervals := int(math.Floor(NormalizeAngle(angle)/(2*math.Pi)*float64(Intervals()) + intervalEpsilon))
+	if intervals <= 0 || intervals > Intervals() {
+		intervals = Intervals()
+	}
+
+	return time.Duration(intervals) * state.RemainingTimerAdjustmentInterval
+}
+
+// RemainingTimeToAngle returns the angle measured clockwise from 12 o'clock for a remaining time
+func RemainingTimeToAngle(remainingTime time.Duration) float64 {
+	return 2 * math.Pi * (float64(remainingTime) / float64(Revolution))
+}
+
+// PositionToRemainingTime returns the remaining time for a position on a dial with the
+// given size. It returns false if the position is within the dead zone.
+func PositionToRemainingTime(width, height, x, y float64) (time.Duration, bool) {
+	angle, ok := PositionToAngle(width, height, x, y)
+	if !ok {
+		return 0, false
+	}
+
+	return AngleToRemainingTime(angle), true
+}
+
+// Tick is a tick mark on the dial
+type Tick struct {
+	// Angle measured clockwise from 12 o'clock
+	Angle float64
+	// Remaining time that the tick mark represents within one revolution
+	RemainingTime time.Duration
+	// Whether this is a major tick mark
+	Major bool
+}
+
+// Ticks returns the tick marks for one revolution of the dial, starting at 12 o'clock
+func Ticks() []Tick {
+	ticks := []Tick{}
+	for remainingTime := time.Duration(0); remainingTime < Revolution; remainingTime += TickInterval {
+		ticks = append(ticks, Tick{
+			Angle:         RemainingTimeToAngle(remainingTime),
+			RemainingTime: remainingTime,
+			Major:         remainingTime%MajorTickInterval == 0,
+		})
+	}
+
+	return ticks
+}
+
+// Rings returns the angles of the concentric rings that represent a remaining time, starting
+// with the outermost ring. Every complete revolution is represented by a full ring with an angle
+// of 2π, and the rest of the remaining time by a partial ring.
+func Rings(remainingTime time.Duration) []float64 {
+	rings := []float64{}
+	for ; remainingTime >= Revolution; remainingTime -= Revolution {
+		rings = append(rings, 2*math.Pi)
+	}
+
+	if remainingTime > 0 {
+		rings = append(rings, RemainingTimeToAngle(remainingTime))
+	}
+
+	return rings
+}
+
+// MaxRings returns the number of concentric rings that the maximum initial remaining time needs
+func MaxRings() int {
+	return max(len(Rings(state.MaxInitialRemainingTime)), 1)
+}
+
+// RingRadius returns the radius of a concentric ring, with the outermost ring being on the track
+func RingRadius(width, height float64, ring int) float64 {
+	return Radius(width, height) - float64(ring)*RingSpacing
+}
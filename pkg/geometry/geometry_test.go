@@ -0,0 +1,228 @@
+package geometry
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/state"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWidth  = 260.0
+	testHeight = 260.0
+)
+
+func TestPositionToRemainingTime(t *testing.T) {
+	var positionToRemainingTimeTests = []struct {
+		name          string
+		x, y          float64
+		remainingTime time.Duration
+		ok            bool
+	}{
+		{
+			name:          "12 o'clock maps to a full revolution",
+			x:             testWidth / 2,
+			y:             0,
+			remainingTime: Revolution,
+			ok:            true,
+		},
+		{
+			name:          "3 o'clock maps to a quarter revolution",
+			x:             testWidth,
+			y:             testHeight / 2,
+			remainingTime: Revolution / 4,
+			ok:            true,
+		},
+		{
+			name:          "6 o'clock maps to half a revolution",
+			x:             testWidth / 2,
+			y:             testHeight,
+			remainingTime: Revolution / 2,
+			ok:            true,
+		},
+		{
+			name:          "9 o'clock maps to three quarters of a revolution",
+			x:             0,
+			y:             testHeight / 2,
+			remainingTime: Revolution / 4 * 3,
+			ok:            true,
+		},
+		{
+			name:          "just right of 12 o'clock maps to a full revolution since it's still in the first interval",
+			x:             testWidth/2 + 1,
+			y:             0,
+			remainingTime: Revolution,
+			ok:            true,
+		},
+		{
+			name:          "just left of 12 o'clock maps to the last interval before a full revolution",
+			x:             testWidth/2 - 1,
+			y:             0,
+			remainingTime: Revolution - state.RemainingTimerAdjustmentInterval,
+			ok:            true,
+		},
+		{
+			name: "center is within the dead zone",
+			x:    testWidth / 2,
+			y:    testHeight / 2,
+			ok:   false,
+		},
+		{
+			name: "just inside the edge of the dead zone is within the dead zone",
+			x:    testWidth/2 + DeadZoneRadius - 0.1,
+			y:    testHeight / 2,
+			ok:   false,
+		},
+		{
+			name:          "edge of the dead zone is outside the dead zone",
+			x:             testWidth/2 + DeadZoneRadius,
+			y:             testHeight / 2,
+			remainingTime: Revolution / 4,
+			ok:            true,
+		},
+	}
+	for _, tt := range positionToRemainingTimeTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				remainingTime, ok := PositionToRemainingTime(testWidth, testHeight, tt.x, tt.y)
+
+				require.Equal(t, tt.ok, ok)
+				require.Equal(t, tt.remainingTime, remainingTime)
+			},
+		)
+	}
+}
+
+func TestTicks(t *testing.T) {
+	ticks := Ticks()
+
+	require.Len(t, ticks, int(Revolution/TickInterval))
+
+	require.Equal(t, Tick{Angle: 0, RemainingTime: 0, Major: true}, ticks[0])
+	require.False(t, ticks[1].Major)
+	require.True(t, ticks[MajorTickInterval/TickInterval].Major)
+	require.InDelta(t, math.Pi, ticks[len(ticks)/2].Angle, 1e-9)
+}
+
+func TestRings(t *testing.T) {
+	var ringsTests = []struct {
+		name          string
+		remainingTime time.Duration
+		rings         []float64
+	}{
+		{
+			name:          "no remaining time has no rings",
+			remainingTime: 0,
+			rings:         []float64{},
+		},
+		{
+			name:          "half a revolution has one partial ring",
+			remainingTime: Revolution / 2,
+			rings:         []float64{math.Pi},
+		},
+		{
+			name:          "one revolution has one full ring",
+			remainingTime: Revolution,
+			rings:         []float64{2 * math.Pi},
+		},
+		{
+			name:          "one and a half revolutions have one full and one partial ring",
+			remainingTime: Revolution + Revolution/2,
+			rings:         []float64{2 * math.Pi, math.Pi},
+		},
+		{
+			name:          "two revolutions have two full rings",
+			remainingTime: Revolution * 2,
+			rings:         []float64{2 * math.Pi, 2 * math.Pi},
+		},
+	}
+	for _, tt := range ringsTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				require.InDeltaSlice(t, tt.rings, Rings(tt.remainingTime), 1e-9)
+			},
+		)
+	}
+}
+
+func FuzzAngleToRemainingTime(f *testing.F) {
+	f.Add(0.0)
+	f.Add(math.Pi)
+	f.Add(2 * math.Pi)
+	f.Add(-math.Pi / 2)
+	f.Add(math.Nextafter(2*math.Pi, 0))
+	f.Add(RemainingTimeToAngle(state.RemainingTimerAdjustmentInterval))
+	f.Add(math.Nextafter(RemainingTimeToAngle(state.RemainingTimerAdjustmentInterval), 0))
+
+	f.Fuzz(func(t *testing.T, angle float64) {
+		if math.IsNaN(angle) || math.IsInf(angle, 0) || math.Abs(angle) > 1e6 {
+			t.Skip()
+		}
+
+		remainingTime := AngleToRemainingTime(angle)
+
+		require.GreaterOrEqual(t, remainingTime, state.MinInitialRemainingTime)
+		require.LessOrEqual(t, remainingTime, Revolution)
+		require.Zero(t, remainingTime%state.RemainingTimerAdjustmentInterval)
+
+		// Angle to remaining time to angle is stable
+		roundTripAngle := RemainingTimeToAngle(remainingTime)
+		require.Equal(t, remainingTime, AngleToRemainingTime(roundTripAngle))
+
+		// The remaining time never snaps forward, only down to the previous interval
+		if remainingTime != Revolution {
+			require.LessOrEqual(t, roundTripAngle, NormalizeAngle(angle)+1e-9)
+		}
+	})
+}
+
+func FuzzPositionToRemainingTime(f *testing.F) {
+	f.Add(testWidth, testHeight, testWidth/2, 0.0)
+	f.Add(testWidth, testHeight, testWidth/2, testHeight/2)
+	f.Add(testWidth, testHeight, testWidth/2+DeadZoneRadius, testHeight/2)
+	f.Add(testWidth, testHeight, testWidth/2+DeadZoneRadius-0.001, testHeight/2)
+	f.Add(100.0, 400.0, 0.0, 0.0)
+	f.Add(0.0, 0.0, -1.0, -1.0)
+
+	f.Fuzz(func(t *testing.T, width, height, x, y float64) {
+		for _, v := range []float64{width, height, x, y} {
+			if math.IsNaN(v) || math.IsInf(v, 0) || math.Abs(v) > 1e6 {
+				t.Skip()
+			}
+		}
+
+		remainingTime, ok := PositionToRemainingTime(width, height, x, y)
+
+		cx, cy := Center(width, height)
+		distance := math.Sqrt((x-cx)*(x-cx) + (y-cy)*(y-cy))
+		if distance < DeadZoneRadius {
+			require.False(t, ok)
+
+			return
+		}
+
+		require.True(t, ok)
+		require.GreaterOrEqual(t, remainingTime, state.MinInitialRemainingTime)
+		require.LessOrEqual(t, remainingTime, Revolution)
+		require.Zero(t, remainingTime%state.RemainingTimerAdjustmentInterval)
+
+		// Remaining time to position to remaining time is stable on the track and on any other
+		// circle outside of the dead zone
+		for _, r := range []float64{Radius(width, height), distance} {
+			// Rounding errors could move positions right on the edge of the dead zone into it
+			if r < DeadZoneRadius+1 {
+				continue
+			}
+
+			px, py := AngleToPosition(cx, cy, r, RemainingTimeToAngle(remainingTime))
+
+			roundTripRemainingTime, ok := PositionToRemainingTime(width, height, px, py)
+			require.True(t, ok)
+			require.Equal(t, remainingTime, roundTripRemainingTime)
+		}
+	})
+}
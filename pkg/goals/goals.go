@@ -0,0 +1,119 @@
+package goals
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/history"
+)
+
+type Period string
+
+const (
+	PeriodDay  Period = "day"
+	PeriodWeek Period = "week"
+)
+
+var (
+	ErrUnknownPeriod = errors.New("unknown goal period")
+)
+
+// ParsePeriod returns the period with a name, e.g. "week"
+func ParsePeriod(name string) (Period, error) {
+	switch period := Period(name); period {
+	case PeriodDay, PeriodWeek:
+		return period, nil
+
+	default:
+		return "", fmt.Errorf("%w: %v", ErrUnknownPeriod, name)
+	}
+}
+
+// Start returns the start of the period that a time is in in its location. Weeks start on Monday.
+func (p Period) Start(t time.Time) time.Time {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	if p == PeriodWeek {
+		// Sunday is the last day of the week, not the first one
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	}
+
+	return start
+}
+
+// Next returns the start of the period after the one that a time is in
+func (p Period) Next(t time.Time) time.Time {
+	if p == PeriodWeek {
+		return p.Start(t).AddDate(0, 0, 7)
+	}
+
+	return p.Start(t).AddDate(0, 0, 1)
+}
+
+// Goal is a number of sessions or focused time per period. Targets that are zero aren't part of the goal.
+type Goal struct {
+	Period   Period
+	Sessions int
+	Focused  time.Duration
+}
+
+// Enabled returns whether the goal has any targets
+func (g Goal) Enabled() bool {
+	return g.Sessions > 0 || g.Focused > 0
+}
+
+// Progress is how much of a goal was done in the current period
+type Progress struct {
+	Goal Goal
+	// Start of the current period
+	Since time.Time
+
+	Sessions int
+	Focused  time.Duration
+}
+
+// Fraction returns how much of the goal is done, between zero and one. If the goal has both
+// targets, it is the fraction of the target that is further away.
+func (p Progress) Fraction() float64 {
+	if !p.Goal.Enabled() {
+		return 0
+	}
+
+	fraction := 1.0
+	if p.Goal.Sessions > 0 {
+		fraction = math.Min(fraction, float64(p.Sessions)/float64(p.Goal.Sessions))
+	}
+
+	if p.Goal.Focused > 0 {
+		fraction = math.Min(fraction, float64(p.Focused)/float64(p.Goal.Focused))
+	}
+
+	return fraction
+}
+
+// Reached returns whether all targets of the goal are reached
+func (p Progress) Reached() bool {
+	return p.Goal.Enabled() && p.Fraction() >= 1
+}
+
+// Compute returns the progress towards a goal from the completed work sessions in a history
+// that started in the same period as now
+func Compute(goal Goal, sessions []history.Session, now time.Time) Progress {
+	progress := Progress{
+		Goal:  goal,
+		Since: goal.Period.Start(now),
+	}
+
+	for _, session := range sessions {
+		if !session.Completed || session.Break || session.Start.Before(progress.Since) {
+			continue
+		}
+
+		progress.Sessions++
+		progress.Focused += time.Duration(session.Focused) * time.Second
+	}
+
+	return progress
+}
@@ -0,0 +1,176 @@
+package goals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/history"
+	"github.com/stretchr/testify/require"
+)
+
+var periodStartTests = []struct {
+	name     string
+	period   Period
+	time     time.Time
+	expected time.Time
+}{
+	{
+		name:     "day",
+		period:   PeriodDay,
+		time:     time.Date(2026, 10, 14, 17, 30, 0, 0, time.UTC),
+		expected: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		name:     "week starts on monday",
+		period:   PeriodWeek,
+		time:     time.Date(2026, 10, 14, 17, 30, 0, 0, time.UTC),
+		expected: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		name:     "sunday is in the week that started on the monday before",
+		period:   PeriodWeek,
+		time:     time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC),
+		expected: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		name:     "monday",
+		period:   PeriodWeek,
+		time:     time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC),
+		expected: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		name:     "period is in the time's location",
+		period:   PeriodDay,
+		time:     time.Date(2026, 10, 14, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)),
+		expected: time.Date(2026, 10, 14, 0, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)),
+	},
+}
+
+func TestPeriodStart(t *testing.T) {
+	for _, tt := range periodStartTests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.True(t, tt.expected.Equal(tt.period.Start(tt.time)))
+		})
+	}
+}
+
+func TestPeriodNext(t *testing.T) {
+	now := time.Date(2026, 10, 14, 17, 30, 0, 0, time.UTC)
+
+	require.Equal(t, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), PeriodDay.Next(now))
+	require.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), PeriodWeek.Next(now))
+}
+
+func TestParsePeriod(t *testing.T) {
+	period, err := ParsePeriod("week")
+	require.NoError(t, err)
+	require.Equal(t, PeriodWeek, period)
+
+	_, err = ParsePeriod("month")
+	require.ErrorIs(t, err, ErrUnknownPeriod)
+}
+
+var progressTests = []struct {
+	name     string
+	goal     Goal
+	sessions int
+	focused  time.Duration
+	fraction float64
+	reached  bool
+}{
+	{
+		name:     "no goal",
+		sessions: 3,
+		focused:  time.Hour,
+	},
+	{
+		name:     "sessions",
+		goal:     Goal{Period: PeriodDay, Sessions: 8},
+		sessions: 2,
+		fraction: 0.25,
+	},
+	{
+		name:     "focused time",
+		goal:     Goal{Period: PeriodDay, Focused: time.Hour * 4},
+		focused:  time.Hour,
+		fraction: 0.25,
+	},
+	{
+		name:     "both targets use the one that is further away",
+		goal:     Goal{Period: PeriodDay, Sessions: 4, Focused: time.Hour * 4},
+		sessions: 3,
+		focused:  time.Hour * 2,
+		fraction: 0.5,
+	},
+	{
+		name:     "reached",
+		goal:     Goal{Period: PeriodDay, Sessions: 4, Focused: time.Hour},
+		sessions: 5,
+		focused:  time.Hour * 2,
+		fraction: 1,
+		reached:  true,
+	},
+}
+
+func TestProgress(t *testing.T) {
+	for _, tt := range progressTests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := Progress{
+				Goal:     tt.goal,
+				Sessions: tt.sessions,
+				Focused:  tt.focused,
+			}
+
+			require.Equal(t, tt.fraction, progress.Fraction())
+			require.Equal(t, tt.reached, progress.Reached())
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	now := time.Date(2026, 10, 14, 17, 30, 0, 0, time.UTC)
+	goal := Goal{Period: PeriodDay, Sessions: 8}
+
+	sessions := []history.Session{
+		// Sessions of earlier periods don't count
+		{
+			Start:     now.Add(-time.Hour * 24),
+			End:       now.Add(-time.Hour*24 + time.Minute*25),
+			Focused:   int64((time.Minute * 25).Seconds()),
+			Completed: true,
+		},
+		{
+			Start:     now.Add(-time.Hour * 8),
+			End:       now.Add(-time.Hour*8 + time.Minute*25),
+			Focused:   int64((time.Minute * 25).Seconds()),
+			Completed: true,
+		},
+		// Sessions that were stopped early don't count
+		{
+			Start:   now.Add(-time.Hour * 4),
+			End:     now.Add(-time.Hour*4 + time.Minute*10),
+			Focused: int64((time.Minute * 10).Seconds()),
+		},
+		// Breaks don't count
+		{
+			Start:     now.Add(-time.Hour * 3),
+			End:       now.Add(-time.Hour*3 + time.Minute*5),
+			Focused:   int64((time.Minute * 5).Seconds()),
+			Completed: true,
+			Break:     true,
+		},
+		{
+			Start:     now.Add(-time.Hour),
+			End:       now.Add(-time.Hour + time.Minute*50),
+			Focused:   int64((time.Minute * 45).Seconds()),
+			Completed: true,
+		},
+	}
+
+	require.Equal(t, Progress{
+		Goal:     goal,
+		Since:    time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		Sessions: 2,
+		Focused:  time.Minute * 70,
+	}, Compute(goal, sessions, now))
+}
@@ -0,0 +1,132 @@
+package goals
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/history"
+)
+
+type Hooks struct {
+	// OnProgress is called with the progress whenever it might have changed
+	OnProgress func(ctx context.Context, progress Progress) error
+	// OnReached is called once per period when a session that completes the goal is recorded
+	OnReached func(ctx context.Context, progress Progress) error
+}
+
+// Tracker keeps track of the progress towards a goal by reading the history when sessions are
+// recorded and when a new period starts
+type Tracker struct {
+	ctx     context.Context
+	log     *slog.Logger
+	history *history.Log
+	hooks   *Hooks
+
+	// Refreshes that are requested while one is running only need to happen once afterwards
+	refreshes chan struct{}
+
+	lock     sync.Mutex
+	goal     Goal
+	progress Progress
+	// Start of the period in which the goal was last reached, so that we only celebrate once
+	reachedSince time.Time
+	// Computes the progress again when the next period starts
+	rollover *time.Timer
+}
+
+func NewTracker(ctx context.Context, log *slog.Logger, history *history.Log, hooks *Hooks) *Tracker {
+	t := &Tracker{
+		ctx:     ctx,
+		log:     log,
+		history: history,
+		hooks:   hooks,
+
+		refreshes: make(chan struct{}, 1),
+	}
+
+	go func() {
+		for {
+			select {
+			case <-t.ctx.Done():
+				return
+
+			case <-t.refreshes:
+				t.refresh(t.ctx, true)
+			}
+		}
+	}()
+
+	return t
+}
+
+// Progress returns the last computed progress
+func (t *Tracker) Progress() Progress {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.progress
+}
+
+// SetGoal changes the goal and computes the progress towards it. A goal that is already
+// reached when it is set isn't celebrated.
+func (t *Tracker) SetGoal(goal Goal) {
+	t.lock.Lock()
+	t.goal = goal
+	t.lock.Unlock()
+
+	t.refresh(t.ctx, false)
+}
+
+func (t *Tracker) refresh(ctx context.Context, celebrate bool) {
+	sessions, err := t.history.Read()
+	if err != nil {
+		t.log.Warn("Could not read history to compute goal progress", "err", err)
+
+		return
+	}
+
+	t.lock.Lock()
+
+	progress := Compute(t.goal, sessions, time.Now())
+	t.progress = progress
+
+	reached := progress.Reached() && !progress.Since.Equal(t.reachedSince)
+	if reached {
+		t.reachedSince = progress.Since
+	}
+
+	if t.rollover != nil {
+		t.rollover.Stop()
+	}
+	t.rollover = time.AfterFunc(time.Until(t.goal.Period.Next(progress.Since)), func() {
+		if t.ctx.Err() != nil {
+			return
+		}
+
+		t.refresh(t.ctx, true)
+	})
+
+	t.lock.Unlock()
+
+	if t.hooks.OnProgress != nil {
+		if err := t.hooks.OnProgress(ctx, progress); err != nil {
+			t.log.Warn("Could not handle goal progress", "err", err)
+		}
+	}
+
+	if reached && celebrate && t.hooks.OnReached != nil {
+		if err := t.hooks.OnReached(ctx, progress); err != nil {
+			t.log.Warn("Could not handle reached goal", "err", err)
+		}
+	}
+}
+
+// Refresh computes the progress again in the background, e.g. after a session was recorded
+func (t *Tracker) Refresh() {
+	select {
+	case t.refreshes <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,118 @@
+package goals
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/neilotoole/slogt"
+	"github.com/pojntfx/sessions/pkg/history"
+	"github.com/pojntfx/sessions/pkg/state"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		log := history.NewLog(filepath.Join(t.TempDir(), "history.jsonl"))
+
+		var (
+			progresses []Progress
+			reached    []Progress
+		)
+		tracker := NewTracker(t.Context(), slogt.New(t), log, &Hooks{
+			OnProgress: func(ctx context.Context, progress Progress) error {
+				progresses = append(progresses, progress)
+
+				return nil
+			},
+			OnReached: func(ctx context.Context, progress Progress) error {
+				reached = append(reached, progress)
+
+				return nil
+			},
+		})
+
+		recorder := history.NewRecorder(slogt.New(t), log)
+		recorder.SetOnRecord(func(history.Session) {
+			tracker.Refresh()
+		})
+
+		s := state.NewStateMachine(
+			t.Context(),
+			state.DefaultInitialRemainingTime,
+			slogt.New(t),
+			state.CombineHooks(recorder.Hooks()),
+		)
+
+		goal := Goal{Period: PeriodDay, Sessions: 2}
+		tracker.SetGoal(goal)
+		require.Equal(t, 0, tracker.Progress().Sessions)
+		require.Len(t, progresses, 1)
+
+		// Sessions that are stopped early don't count
+		require.NoError(t, s.StartTimer(t.Context()))
+		time.Sleep(time.Minute)
+
+		// The progress is only computed again once a session was recorded
+		require.NoError(t, s.PlusTimer(t.Context()))
+		synctest.Wait()
+		require.Len(t, progresses, 1)
+
+		require.NoError(t, s.StopTimer(t.Context()))
+		synctest.Wait()
+
+		require.Equal(t, 0, tracker.Progress().Sessions)
+		require.Empty(t, reached)
+
+		require.NoError(t, s.SetInitialRemainingTime(t.Context(), state.DefaultInitialRemainingTime))
+
+		for range 3 {
+			require.NoError(t, s.StartTimer(t.Context()))
+			time.Sleep(state.DefaultInitialRemainingTime + time.Second)
+			synctest.Wait()
+
+			require.NoError(t, s.StopAlarming(t.Context()))
+		}
+
+		progress := tracker.Progress()
+		require.Equal(t, 3, progress.Sessions)
+		require.Equal(t, state.DefaultInitialRemainingTime*3, progress.Focused)
+		require.True(t, progress.Reached())
+
+		// The goal is only celebrated once per period
+		require.Len(t, reached, 1)
+		require.Equal(t, 2, reached[0].Sessions)
+
+		// Goals that are already reached when they are set aren't celebrated
+		tracker.SetGoal(Goal{Period: PeriodDay, Sessions: 3})
+		require.True(t, tracker.Progress().Reached())
+		require.Len(t, reached, 1)
+	})
+}
+
+func TestTrackerNewPeriod(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		log := history.NewLog(filepath.Join(t.TempDir(), "history.jsonl"))
+
+		now := time.Now()
+		require.NoError(t, log.Append(history.Session{
+			Start:     now,
+			End:       now.Add(time.Minute * 25),
+			Focused:   int64((time.Minute * 25).Seconds()),
+			Completed: true,
+		}))
+
+		tracker := NewTracker(t.Context(), slogt.New(t), log, &Hooks{})
+		tracker.SetGoal(Goal{Period: PeriodDay, Sessions: 1})
+		require.True(t, tracker.Progress().Reached())
+
+		// The progress is computed again once the next day starts
+		time.Sleep(time.Hour * 24)
+		synctest.Wait()
+
+		require.False(t, tracker.Progress().Reached())
+		require.Equal(t, 0, tracker.Progress().Sessions)
+	})
+}
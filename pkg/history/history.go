@@ -0,0 +1,107 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidSession = errors.New("invalid session in history")
+)
+
+// Session is a session that was recorded in the history
+type Session struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Time that the timer counted down for in seconds, which excludes the time it was paused
+	Focused int64 `json:"focused"`
+	// Label of the session, e.g. the task it was for
+	Label string `json:"label,omitempty"`
+	// Whether the timer ran out, instead of being stopped early
+	Completed bool `json:"completed"`
+	// Whether the session was a break instead of work
+	Break bool `json:"break,omitempty"`
+}
+
+// Log is a history that stores one session per line in a JSON Lines file, so that sessions
+// can be appended without reading the whole history
+type Log struct {
+	path string
+
+	lock sync.Mutex
+}
+
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Append adds a session to the end of the history
+func (l *Log) Append(session Session) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	line, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// Read returns all sessions in the history in the order they were recorded. A history
+// that doesn't exist yet is empty.
+func (l *Log) Read() ([]Session, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Session{}, nil
+		}
+
+		return nil, err
+	}
+	defer f.Close()
+
+	sessions := []Session{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var session Session
+		if err := json.Unmarshal(scanner.Bytes(), &session); err != nil {
+			return nil, errors.Join(fmt.Errorf("%w on line %v", ErrInvalidSession, line), err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,58 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.jsonl")
+	history := NewLog(path)
+
+	sessions, err := history.Read()
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	expected := []Session{
+		{
+			Start:     start,
+			End:       start.Add(time.Minute * 25),
+			Focused:   int64((time.Minute * 25).Seconds()),
+			Label:     "Write report",
+			Completed: true,
+		},
+		{
+			Start:   start.Add(time.Hour),
+			End:     start.Add(time.Hour + time.Minute*10),
+			Focused: int64((time.Minute * 8).Seconds()),
+		},
+	}
+	for _, session := range expected {
+		require.NoError(t, history.Append(session))
+	}
+
+	sessions, err = history.Read()
+	require.NoError(t, err)
+	require.Equal(t, expected, sessions)
+
+	// Each session is on its own line, and sessions without a label don't have one
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `{"start":"2026-10-01T09:00:00Z","end":"2026-10-01T09:25:00Z","focused":1500,"label":"Write report","completed":true}
+{"start":"2026-10-01T10:00:00Z","end":"2026-10-01T10:10:00Z","focused":480,"completed":false}
+`, string(content))
+}
+
+func TestLogInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"start\":\"2026-10-01T09:00:00Z\"}\n\n{\n"), 0o644))
+
+	_, err := NewLog(path).Read()
+	require.ErrorIs(t, err, ErrInvalidSession)
+	require.ErrorContains(t, err, "line 3")
+}
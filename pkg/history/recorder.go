@@ -0,0 +1,175 @@
+package history
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/state"
+)
+
+// Recorder records sessions to a history through its hooks. A session lasts from when the timer
+// starts until it runs out or is stopped, so pausing it or adjusting its time doesn't start a new one.
+type Recorder struct {
+	log     *slog.Logger
+	history *Log
+
+	lock    sync.Mutex
+	label   string
+	isBreak bool
+	session *Session
+	focused time.Duration
+	// Time at which the timer last started counting down, zero while it is paused
+	countingSince time.Time
+	// Whether the timer ran out, since it is stopped before the alarm starts
+	ranOut bool
+	// Called after a session was written to the history
+	onRecord func(session Session)
+}
+
+func NewRecorder(log *slog.Logger, history *Log) *Recorder {
+	return &Recorder{
+		log:     log,
+		history: history,
+	}
+}
+
+func (r *Recorder) count(now time.Time) {
+	if !r.countingSince.IsZero() {
+		r.focused += now.Sub(r.countingSince)
+	}
+
+	r.countingSince = time.Time{}
+}
+
+func (r *Recorder) finish() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.session == nil {
+		return
+	}
+
+	now := time.Now()
+	r.count(now)
+
+	session := *r.session
+	session.End = now
+	session.Focused = int64(r.focused.Round(time.Second).Seconds())
+	session.Completed = r.ranOut
+
+	r.session = nil
+	r.focused = 0
+	r.ranOut = false
+
+	// The timer should keep working even if we can't record it
+	if err := r.history.Append(session); err != nil {
+		r.log.Warn("Could not record session in history", "err", err)
+
+		return
+	}
+
+	if r.onRecord != nil {
+		r.onRecord(session)
+	}
+}
+
+// SetBreak sets whether the next sessions are breaks
+func (r *Recorder) SetBreak(isBreak bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.isBreak = isBreak
+}
+
+// AddFocused counts time towards the current session's focused time, e.g. time during which the
+// timer was paused but that should still be part of the session
+func (r *Recorder) AddFocused(focused time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.session == nil || focused <= 0 {
+		return
+	}
+
+	r.focused += focused
+}
+
+// SetOnRecord sets a function that is called after a session was written to the history. It is
+// called while recording, so it shouldn't block.
+func (r *Recorder) SetOnRecord(onRecord func(session Session)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.onRecord = onRecord
+}
+
+// Hooks returns state machine hooks that record sessions, which can be combined with other hooks
+func (r *Recorder) Hooks() *state.Hooks {
+	return &state.Hooks{
+		OnStartTimer: func(ctx context.Context) error {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+
+			now := time.Now()
+			if r.session == nil {
+				r.session = &Session{
+					Start: now,
+					Label: r.label,
+					Break: r.isBreak,
+				}
+			}
+
+			// The timer also starts again after its time was adjusted, which continues the session
+			if r.countingSince.IsZero() {
+				r.countingSince = now
+			}
+
+			return nil
+		},
+		OnStopTimer: func(ctx context.Context) error {
+			r.finish()
+
+			return nil
+		},
+
+		OnPauseTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+
+			r.count(time.Now())
+
+			return nil
+		},
+		OnResumeTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+
+			r.countingSince = time.Now()
+
+			return nil
+		},
+
+		OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+
+			r.ranOut = r.session != nil && currentRemainingTime <= 0
+
+			return nil
+		},
+
+		OnLabelChange: func(ctx context.Context, label string) error {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+
+			r.label = label
+			if r.session != nil {
+				r.session.Label = label
+			}
+
+			return nil
+		},
+	}
+}
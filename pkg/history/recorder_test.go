@@ -0,0 +1,278 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/neilotoole/slogt"
+	"github.com/pojntfx/sessions/pkg/state"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedSession struct {
+	duration  time.Duration
+	focused   time.Duration
+	label     string
+	completed bool
+	isBreak   bool
+}
+
+var recorderTests = []struct {
+	name     string
+	run      func(t *testing.T, s *state.StateMachine)
+	breaks   bool
+	expected []recordedSession
+}{
+	{
+		name: "session that runs out is completed",
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimerWithLabel(t.Context(), "Write report"))
+
+			time.Sleep(time.Minute*5 + time.Second)
+		},
+		expected: []recordedSession{
+			{
+				duration:  time.Minute * 5,
+				focused:   time.Minute * 5,
+				label:     "Write report",
+				completed: true,
+			},
+		},
+	},
+	{
+		name: "session that is stopped early is not completed",
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+
+			time.Sleep(time.Minute * 2)
+
+			require.NoError(t, s.StopTimer(t.Context()))
+		},
+		expected: []recordedSession{
+			{
+				duration: time.Minute * 2,
+				focused:  time.Minute * 2,
+			},
+		},
+	},
+	{
+		name: "time while paused is not focused",
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+
+			time.Sleep(time.Minute)
+			require.NoError(t, s.PauseTimer(t.Context()))
+
+			time.Sleep(time.Minute * 10)
+			require.NoError(t, s.ResumeTimer(t.Context()))
+
+			time.Sleep(time.Minute)
+			require.NoError(t, s.StopTimer(t.Context()))
+		},
+		expected: []recordedSession{
+			{
+				duration: time.Minute * 12,
+				focused:  time.Minute * 2,
+			},
+		},
+	},
+	{
+		name: "adjusting the time continues the session",
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+
+			time.Sleep(time.Minute)
+			require.NoError(t, s.PlusTimer(t.Context()))
+
+			time.Sleep(time.Minute)
+			require.NoError(t, s.StartDragging(t.Context()))
+			require.NoError(t, s.StopDragging(t.Context(), time.Minute*10))
+
+			time.Sleep(time.Minute)
+			require.NoError(t, s.StopTimer(t.Context()))
+		},
+		expected: []recordedSession{
+			{
+				duration: time.Minute * 3,
+				focused:  time.Minute * 3,
+			},
+		},
+	},
+	{
+		name: "label that changes during the session is recorded",
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimerWithLabel(t.Context(), "Write report"))
+
+			time.Sleep(time.Minute)
+			require.NoError(t, s.SetLabel(t.Context(), "Review report"))
+
+			time.Sleep(time.Minute)
+			require.NoError(t, s.StopTimer(t.Context()))
+
+			// The label is kept for the next session
+			require.NoError(t, s.StartTimer(t.Context()))
+
+			time.Sleep(time.Minute)
+			require.NoError(t, s.StopTimer(t.Context()))
+		},
+		expected: []recordedSession{
+			{
+				duration: time.Minute * 2,
+				focused:  time.Minute * 2,
+				label:    "Review report",
+			},
+			{
+				duration: time.Minute,
+				focused:  time.Minute,
+				label:    "Review report",
+			},
+		},
+	},
+	{
+		name:   "breaks are recorded as breaks",
+		breaks: true,
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+
+			time.Sleep(time.Minute*5 + time.Second)
+		},
+		expected: []recordedSession{
+			{
+				duration:  time.Minute * 5,
+				focused:   time.Minute * 5,
+				completed: true,
+				isBreak:   true,
+			},
+		},
+	},
+	{
+		name: "stopping the alarm does not record another session",
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+
+			time.Sleep(time.Minute*5 + time.Second)
+			synctest.Wait()
+
+			require.NoError(t, s.StopAlarming(t.Context()))
+		},
+		expected: []recordedSession{
+			{
+				duration:  time.Minute * 5,
+				focused:   time.Minute * 5,
+				completed: true,
+			},
+		},
+	},
+}
+
+func TestRecorder(t *testing.T) {
+	for _, tt := range recorderTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				synctest.Test(t, func(t *testing.T) {
+					history := NewLog(filepath.Join(t.TempDir(), "history.jsonl"))
+					recorder := NewRecorder(slogt.New(t), history)
+					recorder.SetBreak(tt.breaks)
+
+					s := state.NewStateMachine(t.Context(), state.DefaultInitialRemainingTime, slogt.New(t), state.CombineHooks(recorder.Hooks()))
+
+					tt.run(t, s)
+					synctest.Wait()
+
+					sessions, err := history.Read()
+					require.NoError(t, err)
+
+					recorded := []recordedSession{}
+					for _, session := range sessions {
+						recorded = append(recorded, recordedSession{
+							duration:  session.End.Sub(session.Start),
+							focused:   time.Duration(session.Focused) * time.Second,
+							label:     session.Label,
+							completed: session.Completed,
+							isBreak:   session.Break,
+						})
+					}
+					require.Equal(t, tt.expected, recorded)
+				})
+			},
+		)
+	}
+}
+
+func TestRecorderWithoutSession(t *testing.T) {
+	history := NewLog(filepath.Join(t.TempDir(), "history.jsonl"))
+	hooks := NewRecorder(slogt.New(t), history).Hooks()
+
+	// Nothing is recorded if no session is in progress
+	require.NoError(t, hooks.OnCurrentRemainingTimeTick(context.Background(), 0))
+	require.NoError(t, hooks.OnStopTimer(context.Background()))
+
+	sessions, err := history.Read()
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestRecorderOnRecord(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		history := NewLog(filepath.Join(t.TempDir(), "history.jsonl"))
+		recorder := NewRecorder(slogt.New(t), history)
+
+		recorded := []Session{}
+		recorder.SetOnRecord(func(session Session) {
+			recorded = append(recorded, session)
+		})
+
+		s := state.NewStateMachine(t.Context(), state.DefaultInitialRemainingTime, slogt.New(t), state.CombineHooks(recorder.Hooks()))
+
+		// Adjusting the time doesn't record the session
+		require.NoError(t, s.StartTimer(t.Context()))
+		time.Sleep(time.Minute)
+		require.NoError(t, s.PlusTimer(t.Context()))
+		require.Empty(t, recorded)
+
+		time.Sleep(time.Minute)
+		require.NoError(t, s.StopTimer(t.Context()))
+		synctest.Wait()
+
+		sessions, err := history.Read()
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.Len(t, recorded, 1)
+		require.True(t, sessions[0].End.Equal(recorded[0].End))
+		require.Equal(t, int64((time.Minute * 2).Seconds()), recorded[0].Focused)
+	})
+}
+
+func TestRecorderAddFocused(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		history := NewLog(filepath.Join(t.TempDir(), "history.jsonl"))
+		recorder := NewRecorder(slogt.New(t), history)
+
+		s := state.NewStateMachine(t.Context(), state.DefaultInitialRemainingTime, slogt.New(t), state.CombineHooks(recorder.Hooks()))
+
+		// Time without a session isn't counted
+		recorder.AddFocused(time.Hour)
+
+		require.NoError(t, s.StartTimer(t.Context()))
+		time.Sleep(time.Minute)
+		require.NoError(t, s.PauseTimer(t.Context()))
+
+		// Keeping the time the timer was paused for counts it
+		time.Sleep(time.Minute * 5)
+		recorder.AddFocused(time.Minute * 5)
+		require.NoError(t, s.ResumeTimer(t.Context()))
+
+		time.Sleep(time.Minute)
+		require.NoError(t, s.StopTimer(t.Context()))
+		synctest.Wait()
+
+		sessions, err := history.Read()
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.Equal(t, int64((time.Minute * 7).Seconds()), sessions[0].Focused)
+	})
+}
@@ -0,0 +1,177 @@
+package idle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	ErrAlreadyStarted = errors.New("idle monitor is already started")
+)
+
+// Source notifies about changes to the user's idle time. Watch IDs are never zero.
+type Source interface {
+	// AddIdleWatch adds a watch that fires every time the user has been idle for interval
+	AddIdleWatch(interval time.Duration) (uint32, error)
+	// AddUserActiveWatch adds a watch that fires once the next time the user is active
+	AddUserActiveWatch() (uint32, error)
+	RemoveWatch(id uint32) error
+
+	// Fired returns the IDs of watches as they fire
+	Fired() <-chan uint32
+}
+
+type Hooks struct {
+	// OnIdle is called once the user has been idle for the threshold
+	OnIdle func(ctx context.Context, idleSince time.Time) error
+	// OnActive is called once the user is active again after OnIdle was called
+	OnActive func(ctx context.Context, idleSince time.Time) error
+}
+
+// Monitor calls hooks when the user goes idle and becomes active again
+type Monitor struct {
+	ctx       context.Context
+	source    Source
+	log       *slog.Logger
+	threshold time.Duration
+	hooks     *Hooks
+
+	lock        sync.Mutex
+	idleWatch   uint32
+	activeWatch uint32
+	idleSince   time.Time
+	cancel      context.CancelFunc
+}
+
+func NewMonitor(
+	ctx context.Context,
+	source Source,
+	log *slog.Logger,
+	threshold time.Duration,
+	hooks *Hooks,
+) *Monitor {
+	return &Monitor{
+		ctx:       ctx,
+		source:    source,
+		log:       log,
+		threshold: threshold,
+		hooks:     hooks,
+	}
+}
+
+// Start starts watching for the user going idle
+func (m *Monitor) Start() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.cancel != nil {
+		return ErrAlreadyStarted
+	}
+
+	id, err := m.source.AddIdleWatch(m.threshold)
+	if err != nil {
+		return err
+	}
+	m.idleWatch = id
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancel = cancel
+
+	fired := m.source.Fired()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case id := <-fired:
+				if err := m.handleWatch(ctx, id); err != nil {
+					m.log.Warn("Could not handle idle watch", "id", id, "err", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Started returns whether the monitor is watching for the user going idle
+func (m *Monitor) Started() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.cancel != nil
+}
+
+func (m *Monitor) handleWatch(ctx context.Context, id uint32) error {
+	m.lock.Lock()
+
+	switch {
+	case id == m.idleWatch && m.idleSince.IsZero():
+		activeWatch, err := m.source.AddUserActiveWatch()
+		if err != nil {
+			m.lock.Unlock()
+
+			return err
+		}
+
+		// The watch fires once the threshold has passed, so the user went idle before that
+		m.activeWatch = activeWatch
+		m.idleSince = time.Now().Add(-m.threshold)
+		idleSince := m.idleSince
+
+		m.lock.Unlock()
+
+		m.log.Debug("User went idle", "idleSince", idleSince)
+
+		return m.hooks.OnIdle(ctx, idleSince)
+
+	case id == m.activeWatch && m.activeWatch != 0:
+		idleSince := m.idleSince
+
+		m.activeWatch = 0
+		m.idleSince = time.Time{}
+
+		m.lock.Unlock()
+
+		m.log.Debug("User is active again", "idleSince", idleSince)
+
+		return m.hooks.OnActive(ctx, idleSince)
+	}
+
+	m.lock.Unlock()
+
+	return nil
+}
+
+// Stop stops watching. OnActive isn't called if the user was idle when the monitor was stopped.
+func (m *Monitor) Stop() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.cancel == nil {
+		return nil
+	}
+
+	m.cancel()
+	m.cancel = nil
+
+	if err := m.source.RemoveWatch(m.idleWatch); err != nil {
+		return err
+	}
+	m.idleWatch = 0
+
+	if m.activeWatch != 0 {
+		if err := m.source.RemoveWatch(m.activeWatch); err != nil {
+			return err
+		}
+		m.activeWatch = 0
+	}
+
+	m.idleSince = time.Time{}
+
+	return nil
+}
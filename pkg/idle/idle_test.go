@@ -0,0 +1,272 @@
+package idle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/neilotoole/slogt"
+	"github.com/pojntfx/sessions/internal/dbustest"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSource struct {
+	lock    sync.Mutex
+	nextID  uint32
+	idle    map[uint32]time.Duration
+	active  map[uint32]struct{}
+	removed []uint32
+	fired   chan uint32
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{
+		idle:   map[uint32]time.Duration{},
+		active: map[uint32]struct{}{},
+		fired:  make(chan uint32),
+	}
+}
+
+func (s *fakeSource) AddIdleWatch(interval time.Duration) (uint32, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.nextID++
+	s.idle[s.nextID] = interval
+
+	return s.nextID, nil
+}
+
+func (s *fakeSource) AddUserActiveWatch() (uint32, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.nextID++
+	s.active[s.nextID] = struct{}{}
+
+	return s.nextID, nil
+}
+
+func (s *fakeSource) RemoveWatch(id uint32) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.idle, id)
+	delete(s.active, id)
+	s.removed = append(s.removed, id)
+
+	return nil
+}
+
+func (s *fakeSource) Fired() <-chan uint32 {
+	return s.fired
+}
+
+// goIdle fires all idle watches, like the compositor does once the user is idle
+func (s *fakeSource) goIdle() {
+	s.lock.Lock()
+	ids := []uint32{}
+	for id := range s.idle {
+		ids = append(ids, id)
+	}
+	s.lock.Unlock()
+
+	for _, id := range ids {
+		s.fired <- id
+	}
+}
+
+// goActive fires all user active watches, which only fire once
+func (s *fakeSource) goActive() {
+	s.lock.Lock()
+	ids := []uint32{}
+	for id := range s.active {
+		ids = append(ids, id)
+		delete(s.active, id)
+	}
+	s.lock.Unlock()
+
+	for _, id := range ids {
+		s.fired <- id
+	}
+}
+
+func newRecordingMonitor(t *testing.T, source Source, threshold time.Duration) (*Monitor, func() []string) {
+	t.Helper()
+
+	var (
+		lock   sync.Mutex
+		events []string
+	)
+	record := func(event string) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		events = append(events, event)
+	}
+
+	m := NewMonitor(t.Context(), source, slogt.New(t), threshold, &Hooks{
+		OnIdle: func(ctx context.Context, idleSince time.Time) error {
+			record(fmt.Sprintf("idle(%v)", time.Since(idleSince)))
+
+			return nil
+		},
+		OnActive: func(ctx context.Context, idleSince time.Time) error {
+			record(fmt.Sprintf("active(%v)", time.Since(idleSince)))
+
+			return nil
+		},
+	})
+
+	return m, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return append([]string{}, events...)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		source := newFakeSource()
+		m, events := newRecordingMonitor(t, source, time.Minute*5)
+
+		require.NoError(t, m.Start())
+		require.ErrorIs(t, m.Start(), ErrAlreadyStarted)
+		require.True(t, m.Started())
+		require.Equal(t, map[uint32]time.Duration{1: time.Minute * 5}, source.idle)
+
+		source.goIdle()
+		synctest.Wait()
+		require.Equal(t, []string{"idle(5m0s)"}, events())
+
+		// The idle watch fires again if the compositor resets it, which we ignore until the user is back
+		source.goIdle()
+		synctest.Wait()
+		require.Equal(t, []string{"idle(5m0s)"}, events())
+
+		time.Sleep(time.Minute * 2)
+
+		source.goActive()
+		synctest.Wait()
+		require.Equal(t, []string{"idle(5m0s)", "active(7m0s)"}, events())
+
+		source.goIdle()
+		synctest.Wait()
+		require.Equal(t, []string{"idle(5m0s)", "active(7m0s)", "idle(5m0s)"}, events())
+
+		require.NoError(t, m.Stop())
+		require.NoError(t, m.Stop())
+		require.False(t, m.Started())
+		require.Empty(t, source.idle)
+		require.Empty(t, source.active)
+	})
+}
+
+func TestMonitorStopWhileIdle(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		source := newFakeSource()
+		m, events := newRecordingMonitor(t, source, time.Minute)
+
+		require.NoError(t, m.Start())
+
+		source.goIdle()
+		synctest.Wait()
+
+		require.NoError(t, m.Stop())
+		require.ElementsMatch(t, []uint32{1, 2}, source.removed)
+
+		// Restarting doesn't remember that the user was idle
+		require.NoError(t, m.Start())
+		require.NoError(t, m.Stop())
+
+		require.Equal(t, []string{"idle(1m0s)"}, events())
+	})
+}
+
+type fakeMutter struct {
+	lock   sync.Mutex
+	calls  []string
+	nextID uint32
+}
+
+func (m *fakeMutter) recordedCalls() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]string{}, m.calls...)
+}
+
+func (m *fakeMutter) AddIdleWatch(interval uint64) (uint32, *dbus.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.nextID++
+	m.calls = append(m.calls, fmt.Sprintf("AddIdleWatch(%v)", interval))
+
+	return m.nextID, nil
+}
+
+func (m *fakeMutter) AddUserActiveWatch() (uint32, *dbus.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.nextID++
+	m.calls = append(m.calls, "AddUserActiveWatch()")
+
+	return m.nextID, nil
+}
+
+func (m *fakeMutter) RemoveWatch(id uint32) *dbus.Error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.calls = append(m.calls, fmt.Sprintf("RemoveWatch(%v)", id))
+
+	return nil
+}
+
+func TestMutterSource(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	compositor := dbustest.Connect(t, address)
+
+	mutter := &fakeMutter{}
+	require.NoError(t, compositor.Export(mutter, mutterPath, mutterInterface))
+
+	reply, err := compositor.RequestName(mutterName, dbus.NameFlagDoNotQueue)
+	require.NoError(t, err)
+	require.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+
+	s := NewMutterSource(t.Context(), dbustest.Connect(t, address))
+	require.NoError(t, s.Open())
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+	})
+
+	idleWatch, err := s.AddIdleWatch(time.Minute * 5)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), idleWatch)
+
+	activeWatch, err := s.AddUserActiveWatch()
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), activeWatch)
+
+	require.NoError(t, s.RemoveWatch(idleWatch))
+
+	require.Equal(t, []string{"AddIdleWatch(300000)", "AddUserActiveWatch()", "RemoveWatch(1)"}, mutter.recordedCalls())
+
+	require.NoError(t, compositor.Emit(mutterPath, mutterInterface+".WatchFired", activeWatch))
+
+	select {
+	case id := <-s.Fired():
+		require.Equal(t, activeWatch, id)
+
+	case <-time.After(time.Second * 5):
+		require.FailNow(t, "timed out waiting for watch to fire")
+	}
+}
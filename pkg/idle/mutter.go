@@ -0,0 +1,124 @@
+package idle
+
+import (
+	"context"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	mutterName      = "org.gnome.Mutter.IdleMonitor"
+	mutterInterface = "org.gnome.Mutter.IdleMonitor"
+	mutterPath      = dbus.ObjectPath("/org/gnome/Mutter/IdleMonitor/Core")
+)
+
+// MutterSource is a source that uses the idle monitor of GNOME's compositor
+type MutterSource struct {
+	ctx  context.Context
+	conn *dbus.Conn
+
+	fired  chan uint32
+	cancel context.CancelFunc
+}
+
+func NewMutterSource(ctx context.Context, conn *dbus.Conn) *MutterSource {
+	return &MutterSource{
+		ctx:  ctx,
+		conn: conn,
+
+		fired: make(chan uint32, 10),
+	}
+}
+
+func (s *MutterSource) matchOptions() []dbus.MatchOption {
+	return []dbus.MatchOption{
+		dbus.WithMatchObjectPath(mutterPath),
+		dbus.WithMatchInterface(mutterInterface),
+		dbus.WithMatchMember("WatchFired"),
+	}
+}
+
+// Open subscribes to watches firing
+func (s *MutterSource) Open() error {
+	if err := s.conn.AddMatchSignal(s.matchOptions()...); err != nil {
+		return err
+	}
+
+	signals := make(chan *dbus.Signal, 10)
+	s.conn.Signal(signals)
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.cancel = cancel
+
+	go func() {
+		defer s.conn.RemoveSignal(signals)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case signal := <-signals:
+				if signal.Name != mutterInterface+".WatchFired" || len(signal.Body) < 1 {
+					continue
+				}
+
+				id, ok := signal.Body[0].(uint32)
+				if !ok {
+					continue
+				}
+
+				select {
+				case s.fired <- id:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close unsubscribes from watches firing
+func (s *MutterSource) Close() error {
+	if s.cancel == nil {
+		return nil
+	}
+
+	s.cancel()
+	s.cancel = nil
+
+	return s.conn.RemoveMatchSignal(s.matchOptions()...)
+}
+
+func (s *MutterSource) object() dbus.BusObject {
+	return s.conn.Object(mutterName, mutterPath)
+}
+
+func (s *MutterSource) AddIdleWatch(interval time.Duration) (uint32, error) {
+	var id uint32
+	if err := s.object().Call(mutterInterface+".AddIdleWatch", 0, uint64(interval.Milliseconds())).Store(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *MutterSource) AddUserActiveWatch() (uint32, error) {
+	var id uint32
+	if err := s.object().Call(mutterInterface+".AddUserActiveWatch", 0).Store(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *MutterSource) RemoveWatch(id uint32) error {
+	return s.object().Call(mutterInterface+".RemoveWatch", 0, id).Err
+}
+
+func (s *MutterSource) Fired() <-chan uint32 {
+	return s.fired
+}
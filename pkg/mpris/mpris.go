@@ -0,0 +1,517 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+	"github.com/pojntfx/sessions/pkg/state"
+)
+
+const (
+	// Prefix of the well-known name that players own, followed by the player's name
+	BusNamePrefix = "org.mpris.MediaPlayer2."
+
+	rootInterface   = "org.mpris.MediaPlayer2"
+	playerInterface = "org.mpris.MediaPlayer2.Player"
+	playerPath      = dbus.ObjectPath("/org/mpris/MediaPlayer2")
+)
+
+var (
+	ErrAlreadyOpen  = errors.New("media player is already open")
+	ErrNameNotOwned = errors.New("could not become primary owner of media player name")
+	ErrNotSupported = errors.New("not supported by this media player")
+)
+
+// PlaybackStatus is the playback status of a media player
+type PlaybackStatus string
+
+const (
+	PlaybackStatusPlaying PlaybackStatus = "Playing"
+	PlaybackStatusPaused  PlaybackStatus = "Paused"
+	PlaybackStatusStopped PlaybackStatus = "Stopped"
+)
+
+// Timer is the part of the state machine that the media player controls
+type Timer interface {
+	StartTimer(ctx context.Context) error
+	StopTimer(ctx context.Context) error
+	PauseTimer(ctx context.Context) error
+	ResumeTimer(ctx context.Context) error
+	StopAlarming(ctx context.Context) error
+}
+
+type Hooks struct {
+	// OnRaise is called when a client asks for the player's window to be shown
+	OnRaise func(ctx context.Context) error
+}
+
+// Player exposes the timer as a media player that implements the MPRIS specification,
+// so that media keys and widgets can control it. The session is the track, the time
+// that has elapsed since it started is the position and its duration is the length.
+type Player struct {
+	ctx   context.Context
+	conn  *dbus.Conn
+	log   *slog.Logger
+	timer Timer
+	hooks *Hooks
+
+	name,
+	identity,
+	desktopEntry string
+
+	lock      sync.Mutex
+	props     *prop.Properties
+	status    PlaybackStatus
+	alarming  bool
+	adjusting bool
+	title     string
+	length    time.Duration
+	remaining time.Duration
+}
+
+// NewPlayer creates a new media player on a connection. The player is only exported and
+// owns its name once it is opened.
+func NewPlayer(
+	ctx context.Context,
+	conn *dbus.Conn,
+	log *slog.Logger,
+	timer Timer,
+	name,
+	identity,
+	desktopEntry,
+	title string,
+	initialRemainingTime time.Duration,
+	hooks *Hooks,
+) *Player {
+	return &Player{
+		ctx:   ctx,
+		conn:  conn,
+		log:   log,
+		timer: timer,
+		hooks: hooks,
+
+		name:         name,
+		identity:     identity,
+		desktopEntry: desktopEntry,
+
+		status:    PlaybackStatusStopped,
+		title:     title,
+		length:    initialRemainingTime,
+		remaining: initialRemainingTime,
+	}
+}
+
+// Open exports the player and requests its name, which makes clients pick it up
+func (p *Player) Open() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.props != nil {
+		return ErrAlreadyOpen
+	}
+
+	props, err := prop.Export(p.conn, playerPath, prop.Map{
+		rootInterface: {
+			"CanQuit":             {Value: false, Emit: prop.EmitFalse},
+			"CanRaise":            {Value: p.hooks.OnRaise != nil, Emit: prop.EmitFalse},
+			"HasTrackList":        {Value: false, Emit: prop.EmitFalse},
+			"Identity":            {Value: p.identity, Emit: prop.EmitFalse},
+			"DesktopEntry":        {Value: p.desktopEntry, Emit: prop.EmitFalse},
+			"SupportedUriSchemes": {Value: []string{}, Emit: prop.EmitFalse},
+			"SupportedMimeTypes":  {Value: []string{}, Emit: prop.EmitFalse},
+		},
+		playerInterface: {
+			"PlaybackStatus": {Value: string(p.status), Emit: prop.EmitTrue},
+			"Rate":           {Value: 1.0, Emit: prop.EmitFalse},
+			"Metadata":       {Value: p.metadata(), Emit: prop.EmitTrue},
+			"Volume":         {Value: 1.0, Emit: prop.EmitFalse},
+			// Clients are expected to poll the position and to rely on the `Seeked` signal for jumps
+			"Position":      {Value: p.position().Microseconds(), Emit: prop.EmitFalse},
+			"MinimumRate":   {Value: 1.0, Emit: prop.EmitFalse},
+			"MaximumRate":   {Value: 1.0, Emit: prop.EmitFalse},
+			"CanGoNext":     {Value: false, Emit: prop.EmitFalse},
+			"CanGoPrevious": {Value: false, Emit: prop.EmitFalse},
+			"CanPlay":       {Value: false, Emit: prop.EmitTrue},
+			"CanPause":      {Value: false, Emit: prop.EmitTrue},
+			"CanSeek":       {Value: false, Emit: prop.EmitFalse},
+			"CanControl":    {Value: true, Emit: prop.EmitFalse},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Both interfaces live on the same path, so we export method tables instead of the player itself
+	if err := p.conn.ExportMethodTable(map[string]any{
+		"Raise": p.raise,
+		"Quit":  p.quit,
+	}, playerPath, rootInterface); err != nil {
+		return err
+	}
+
+	if err := p.conn.ExportMethodTable(map[string]any{
+		"Play":        p.play,
+		"Pause":       p.pause,
+		"PlayPause":   p.playPause,
+		"Stop":        p.stop,
+		"Next":        p.next,
+		"Previous":    p.previous,
+		"Seek":        p.seek,
+		"SetPosition": p.setPosition,
+		"OpenUri":     p.openURI,
+	}, playerPath, playerInterface); err != nil {
+		return err
+	}
+
+	if err := p.conn.Export(introspect.NewIntrospectable(&introspect.Node{
+		Name: string(playerPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{
+				Name:       rootInterface,
+				Methods:    []introspect.Method{{Name: "Raise"}, {Name: "Quit"}},
+				Properties: props.Introspection(rootInterface),
+			},
+			{
+				Name: playerInterface,
+				Methods: []introspect.Method{
+					{Name: "Play"},
+					{Name: "Pause"},
+					{Name: "PlayPause"},
+					{Name: "Stop"},
+					{Name: "Next"},
+					{Name: "Previous"},
+					{Name: "Seek", Args: []introspect.Arg{{Name: "Offset", Type: "x", Direction: "in"}}},
+					{Name: "SetPosition", Args: []introspect.Arg{
+						{Name: "TrackId", Type: "o", Direction: "in"},
+						{Name: "Position", Type: "x", Direction: "in"},
+					}},
+					{Name: "OpenUri", Args: []introspect.Arg{{Name: "Uri", Type: "s", Direction: "in"}}},
+				},
+				Properties: props.Introspection(playerInterface),
+				Signals: []introspect.Signal{
+					{Name: "Seeked", Args: []introspect.Arg{{Name: "Position", Type: "x"}}},
+				},
+			},
+		},
+	}), playerPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return err
+	}
+
+	reply, err := p.conn.RequestName(BusNamePrefix+p.name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return err
+	}
+
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return ErrNameNotOwned
+	}
+
+	p.props = props
+
+	return nil
+}
+
+// Close releases the player's name and unexports it, which removes it from the clients
+func (p *Player) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.props == nil {
+		return nil
+	}
+
+	if _, err := p.conn.ReleaseName(BusNamePrefix + p.name); err != nil {
+		return err
+	}
+
+	for _, iface := range []string{rootInterface, playerInterface, "org.freedesktop.DBus.Properties", "org.freedesktop.DBus.Introspectable"} {
+		if err := p.conn.Export(nil, playerPath, iface); err != nil {
+			return err
+		}
+	}
+
+	p.props = nil
+
+	return nil
+}
+
+func (p *Player) trackID() dbus.ObjectPath {
+	// Track IDs must be valid object paths that aren't in the `/org/mpris` namespace
+	return dbus.ObjectPath("/" + strings.NewReplacer(".", "/", "-", "_").Replace(p.desktopEntry) + "/Session")
+}
+
+// metadata must be called with the lock held
+func (p *Player) metadata() map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(p.trackID()),
+		"mpris:length":  dbus.MakeVariant(p.length.Microseconds()),
+		"xesam:title":   dbus.MakeVariant(p.title),
+		"xesam:artist":  dbus.MakeVariant([]string{p.identity}),
+	}
+}
+
+// position must be called with the lock held
+func (p *Player) position() time.Duration {
+	if p.status == PlaybackStatusStopped {
+		return 0
+	}
+
+	return p.length - p.remaining
+}
+
+// set must be called with the lock held
+func (p *Player) set(iface, property string, value any) {
+	if p.props == nil {
+		return
+	}
+
+	p.props.SetMust(iface, property, value)
+}
+
+// seeked must be called with the lock held
+func (p *Player) seeked() {
+	if p.props == nil {
+		return
+	}
+
+	if err := p.conn.Emit(playerPath, playerInterface+".Seeked", p.position().Microseconds()); err != nil {
+		p.log.Warn("Could not emit media player seeked signal", "err", err)
+	}
+}
+
+// SetTitle sets the title of the track, which is the name of the session's phase
+func (p *Player) SetTitle(title string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.title = title
+	p.set(playerInterface, "Metadata", p.metadata())
+}
+
+// Hooks returns state machine hooks that keep the player in sync with the timer, which
+// can be combined with other hooks
+func (p *Player) Hooks() *state.Hooks {
+	setStatus := func(status PlaybackStatus, remaining time.Duration) {
+		p.status = status
+		p.remaining = remaining
+
+		p.set(playerInterface, "PlaybackStatus", string(p.status))
+		p.set(playerInterface, "Position", p.position().Microseconds())
+	}
+
+	return &state.Hooks{
+		OnStartTimer: func(ctx context.Context) error {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			// Adding or removing time while counting down starts the timer over
+			adjusted := p.adjusting
+			p.adjusting = false
+
+			setStatus(PlaybackStatusPlaying, p.length)
+
+			if adjusted {
+				p.seeked()
+			}
+
+			return nil
+		},
+		OnStopTimer: func(ctx context.Context) error {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			setStatus(PlaybackStatusStopped, p.length)
+
+			return nil
+		},
+
+		OnPauseTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			setStatus(PlaybackStatusPaused, currentRemainingTime)
+
+			return nil
+		},
+		OnResumeTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			setStatus(PlaybackStatusPlaying, currentRemainingTime)
+
+			return nil
+		},
+
+		OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			p.adjusting = p.status == PlaybackStatusPlaying && !p.alarming
+			p.length = initialRemainingTime
+			if p.status == PlaybackStatusStopped {
+				p.remaining = initialRemainingTime
+			}
+
+			p.set(playerInterface, "Metadata", p.metadata())
+
+			return nil
+		},
+		OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			p.remaining = currentRemainingTime
+			p.set(playerInterface, "Position", p.position().Microseconds())
+
+			return nil
+		},
+
+		OnStartAlarm: func(ctx context.Context) error {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			// The alarm is still part of the session, so it keeps playing at its end
+			p.alarming = true
+			setStatus(PlaybackStatusPlaying, 0)
+
+			return nil
+		},
+		OnStopAlarm: func(ctx context.Context) error {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			p.alarming = false
+			setStatus(PlaybackStatusStopped, p.length)
+
+			return nil
+		},
+
+		OnPermittedTriggersChange: func(ctx context.Context, permittedTriggers []state.Trigger) error {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			p.set(playerInterface, "CanPlay", slices.Contains(permittedTriggers, state.TriggerStartTimer) || slices.Contains(permittedTriggers, state.TriggerResumeTimer))
+			p.set(playerInterface, "CanPause", slices.Contains(permittedTriggers, state.TriggerPauseTimer))
+
+			return nil
+		},
+	}
+}
+
+func (p *Player) snapshot() (PlaybackStatus, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.status, p.alarming
+}
+
+// call runs an action and converts its error into a D-Bus error. The lock must not be held,
+// since the action calls the hooks.
+func (p *Player) call(method string, action func(ctx context.Context) error) *dbus.Error {
+	if err := action(p.ctx); err != nil {
+		p.log.Warn("Could not handle media player method call", "method", method, "err", err)
+
+		return dbus.MakeFailedError(err)
+	}
+
+	return nil
+}
+
+func (p *Player) raise() *dbus.Error {
+	if p.hooks.OnRaise == nil {
+		return dbus.MakeFailedError(ErrNotSupported)
+	}
+
+	return p.call("Raise", p.hooks.OnRaise)
+}
+
+func (p *Player) quit() *dbus.Error {
+	return dbus.MakeFailedError(ErrNotSupported)
+}
+
+// play starts the timer, or resumes it if it is paused
+func (p *Player) play() *dbus.Error {
+	switch status, _ := p.snapshot(); status {
+	case PlaybackStatusStopped:
+		return p.call("Play", p.timer.StartTimer)
+
+	case PlaybackStatusPaused:
+		return p.call("Play", p.timer.ResumeTimer)
+
+	default:
+		return nil
+	}
+}
+
+// pause pauses the timer if it is counting down
+func (p *Player) pause() *dbus.Error {
+	if status, alarming := p.snapshot(); status != PlaybackStatusPlaying || alarming {
+		return nil
+	}
+
+	return p.call("Pause", p.timer.PauseTimer)
+}
+
+// playPause pauses the timer if it is counting down, stops the alarm if it is ringing
+// and starts or resumes the timer otherwise
+func (p *Player) playPause() *dbus.Error {
+	status, alarming := p.snapshot()
+	if alarming {
+		return p.call("PlayPause", p.timer.StopAlarming)
+	}
+
+	if status == PlaybackStatusPlaying {
+		return p.pause()
+	}
+
+	return p.play()
+}
+
+// stop stops the alarm if it is ringing and the timer otherwise
+func (p *Player) stop() *dbus.Error {
+	status, alarming := p.snapshot()
+	if alarming {
+		return p.call("Stop", p.timer.StopAlarming)
+	}
+
+	if status == PlaybackStatusStopped {
+		return nil
+	}
+
+	return p.call("Stop", p.timer.StopTimer)
+}
+
+// next has no effect since there is only one track
+func (p *Player) next() *dbus.Error {
+	return nil
+}
+
+// previous has no effect since there is only one track
+func (p *Player) previous() *dbus.Error {
+	return nil
+}
+
+// seek has no effect since the player can't seek
+func (p *Player) seek(offset int64) *dbus.Error {
+	return nil
+}
+
+// setPosition has no effect since the player can't seek
+func (p *Player) setPosition(trackID dbus.ObjectPath, position int64) *dbus.Error {
+	return nil
+}
+
+// openURI is not supported since the player doesn't play media
+func (p *Player) openURI(uri string) *dbus.Error {
+	return dbus.MakeFailedError(ErrNotSupported)
+}
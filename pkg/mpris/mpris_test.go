@@ -0,0 +1,299 @@
+package mpris
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/neilotoole/slogt"
+	"github.com/pojntfx/sessions/internal/dbustest"
+	"github.com/pojntfx/sessions/pkg/state"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testName         = "test"
+	testDesktopEntry = "com.example.Test"
+)
+
+type fakeTimer struct {
+	lock  sync.Mutex
+	calls []string
+}
+
+func (f *fakeTimer) record(call string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		f.calls = append(f.calls, call)
+
+		return nil
+	}
+}
+
+func (f *fakeTimer) recordedCalls() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return append([]string{}, f.calls...)
+}
+
+func (f *fakeTimer) StartTimer(ctx context.Context) error   { return f.record("StartTimer")(ctx) }
+func (f *fakeTimer) StopTimer(ctx context.Context) error    { return f.record("StopTimer")(ctx) }
+func (f *fakeTimer) PauseTimer(ctx context.Context) error   { return f.record("PauseTimer")(ctx) }
+func (f *fakeTimer) ResumeTimer(ctx context.Context) error  { return f.record("ResumeTimer")(ctx) }
+func (f *fakeTimer) StopAlarming(ctx context.Context) error { return f.record("StopAlarming")(ctx) }
+
+func openPlayer(t *testing.T, conn *dbus.Conn, timer Timer, hooks *Hooks) *Player {
+	t.Helper()
+
+	p := NewPlayer(t.Context(), conn, slogt.New(t), timer, testName, "Test", testDesktopEntry, "Session", state.DefaultInitialRemainingTime, hooks)
+	require.NoError(t, p.Open())
+
+	t.Cleanup(func() {
+		require.NoError(t, p.Close())
+	})
+
+	return p
+}
+
+func playerObject(conn *dbus.Conn) dbus.BusObject {
+	return conn.Object(BusNamePrefix+testName, playerPath)
+}
+
+func getProperty[T any](t *testing.T, conn *dbus.Conn, iface, property string) T {
+	t.Helper()
+
+	var value T
+	require.NoError(t, playerObject(conn).Call("org.freedesktop.DBus.Properties.Get", 0, iface, property).Store(&value))
+
+	return value
+}
+
+func call(t *testing.T, conn *dbus.Conn, method string) {
+	t.Helper()
+
+	require.NoError(t, playerObject(conn).Call(playerInterface+"."+method, 0).Err)
+}
+
+func TestOpen(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	conn := dbustest.Connect(t, address)
+	openPlayer(t, conn, &fakeTimer{}, &Hooks{})
+
+	client := dbustest.Connect(t, address)
+
+	require.Equal(t, "Test", getProperty[string](t, client, rootInterface, "Identity"))
+	require.Equal(t, testDesktopEntry, getProperty[string](t, client, rootInterface, "DesktopEntry"))
+	require.False(t, getProperty[bool](t, client, rootInterface, "CanRaise"))
+
+	require.Equal(t, string(PlaybackStatusStopped), getProperty[string](t, client, playerInterface, "PlaybackStatus"))
+
+	metadata := getProperty[map[string]dbus.Variant](t, client, playerInterface, "Metadata")
+	require.Equal(t, dbus.ObjectPath("/com/example/Test/Session"), metadata["mpris:trackid"].Value())
+	require.Equal(t, state.DefaultInitialRemainingTime.Microseconds(), metadata["mpris:length"].Value())
+	require.Equal(t, "Session", metadata["xesam:title"].Value())
+
+	// Only one player can own the name
+	other := NewPlayer(t.Context(), dbustest.Connect(t, address), slogt.New(t), &fakeTimer{}, testName, "Test", testDesktopEntry, "Session", state.DefaultInitialRemainingTime, &Hooks{})
+	require.ErrorIs(t, other.Open(), ErrNameNotOwned)
+}
+
+func TestRaise(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	raised := make(chan struct{}, 1)
+	openPlayer(t, dbustest.Connect(t, address), &fakeTimer{}, &Hooks{
+		OnRaise: func(ctx context.Context) error {
+			raised <- struct{}{}
+
+			return nil
+		},
+	})
+
+	client := dbustest.Connect(t, address)
+	require.True(t, getProperty[bool](t, client, rootInterface, "CanRaise"))
+
+	require.NoError(t, playerObject(client).Call(rootInterface+".Raise", 0).Err)
+	require.Len(t, raised, 1)
+
+	require.Error(t, playerObject(client).Call(rootInterface+".Quit", 0).Err)
+}
+
+func TestControls(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	conn := dbustest.Connect(t, address)
+
+	p := NewPlayer(t.Context(), conn, slogt.New(t), nil, testName, "Test", testDesktopEntry, "Session", state.DefaultInitialRemainingTime, &Hooks{})
+	s := state.NewStateMachine(t.Context(), state.DefaultInitialRemainingTime, slogt.New(t), state.CombineHooks(p.Hooks()))
+	p.timer = s
+
+	require.NoError(t, p.Open())
+	t.Cleanup(func() {
+		_ = s.StopTimer(context.Background())
+
+		require.NoError(t, p.Close())
+	})
+	s.FlushPermittedTriggers(t.Context())
+
+	client := dbustest.Connect(t, address)
+
+	var controlsTests = []struct {
+		name     string
+		method   string
+		status   PlaybackStatus
+		canPlay  bool
+		canPause bool
+	}{
+		{
+			name:     "play starts the timer",
+			method:   "Play",
+			status:   PlaybackStatusPlaying,
+			canPlay:  false,
+			canPause: true,
+		},
+		{
+			name:     "play while playing has no effect",
+			method:   "Play",
+			status:   PlaybackStatusPlaying,
+			canPlay:  false,
+			canPause: true,
+		},
+		{
+			name:     "pause pauses the timer",
+			method:   "Pause",
+			status:   PlaybackStatusPaused,
+			canPlay:  true,
+			canPause: false,
+		},
+		{
+			name:     "play pause resumes the timer",
+			method:   "PlayPause",
+			status:   PlaybackStatusPlaying,
+			canPlay:  false,
+			canPause: true,
+		},
+		{
+			name:     "play pause pauses the timer",
+			method:   "PlayPause",
+			status:   PlaybackStatusPaused,
+			canPlay:  true,
+			canPause: false,
+		},
+		{
+			name:     "stop stops the paused timer",
+			method:   "Stop",
+			status:   PlaybackStatusStopped,
+			canPlay:  true,
+			canPause: false,
+		},
+		{
+			name:     "play pause starts the timer",
+			method:   "PlayPause",
+			status:   PlaybackStatusPlaying,
+			canPlay:  false,
+			canPause: true,
+		},
+		{
+			name:     "stop stops the running timer",
+			method:   "Stop",
+			status:   PlaybackStatusStopped,
+			canPlay:  true,
+			canPause: false,
+		},
+	}
+	// The calls build on each other, so they share a player
+	for _, tt := range controlsTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				call(t, client, tt.method)
+
+				require.Equal(t, string(tt.status), getProperty[string](t, client, playerInterface, "PlaybackStatus"))
+				require.Equal(t, tt.canPlay, getProperty[bool](t, client, playerInterface, "CanPlay"))
+				require.Equal(t, tt.canPause, getProperty[bool](t, client, playerInterface, "CanPause"))
+			},
+		)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	p := openPlayer(t, dbustest.Connect(t, address), &fakeTimer{}, &Hooks{})
+	h := p.Hooks()
+
+	client := dbustest.Connect(t, address)
+	require.NoError(t, client.AddMatchSignal(dbus.WithMatchInterface(playerInterface), dbus.WithMatchMember("Seeked")))
+
+	signals := make(chan *dbus.Signal, 10)
+	client.Signal(signals)
+
+	position := func() time.Duration {
+		return time.Duration(getProperty[int64](t, client, playerInterface, "Position")) * time.Microsecond
+	}
+
+	require.NoError(t, h.OnStartTimer(t.Context()))
+	require.Equal(t, time.Duration(0), position())
+
+	require.NoError(t, h.OnCurrentRemainingTimeTick(t.Context(), state.DefaultInitialRemainingTime-time.Minute))
+	require.Equal(t, time.Minute, position())
+
+	// Adding time while counting down starts the timer over with the new length
+	require.NoError(t, h.OnInitialRemainingTimeChange(t.Context(), time.Minute*5+time.Second*30))
+	require.NoError(t, h.OnStartTimer(t.Context()))
+	require.Equal(t, time.Duration(0), position())
+
+	metadata := getProperty[map[string]dbus.Variant](t, client, playerInterface, "Metadata")
+	require.Equal(t, (time.Minute*5 + time.Second*30).Microseconds(), metadata["mpris:length"].Value())
+
+	select {
+	case signal := <-signals:
+		require.Equal(t, playerInterface+".Seeked", signal.Name)
+		require.Equal(t, []any{int64(0)}, signal.Body)
+
+	case <-time.After(time.Second * 5):
+		require.FailNow(t, "timed out waiting for seeked signal")
+	}
+
+	require.NoError(t, h.OnPauseTimer(t.Context(), time.Minute*5))
+	require.Equal(t, time.Second*30, position())
+
+	require.NoError(t, h.OnStopTimer(t.Context()))
+	require.Equal(t, time.Duration(0), position())
+
+	// Changing the length while stopped isn't a jump
+	require.NoError(t, h.OnInitialRemainingTimeChange(t.Context(), time.Minute*10))
+	require.Empty(t, signals)
+}
+
+func TestAlarm(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	timer := &fakeTimer{}
+	p := openPlayer(t, dbustest.Connect(t, address), timer, &Hooks{})
+	h := p.Hooks()
+
+	client := dbustest.Connect(t, address)
+
+	require.NoError(t, h.OnStartTimer(t.Context()))
+	require.NoError(t, h.OnStartAlarm(t.Context()))
+
+	// The alarm keeps playing at the end of the session
+	require.Equal(t, string(PlaybackStatusPlaying), getProperty[string](t, client, playerInterface, "PlaybackStatus"))
+	require.Equal(t, state.DefaultInitialRemainingTime.Microseconds(), getProperty[int64](t, client, playerInterface, "Position"))
+
+	call(t, client, "Pause")
+	call(t, client, "PlayPause")
+	call(t, client, "Stop")
+
+	require.Equal(t, []string{"StopAlarming", "StopAlarming"}, timer.recordedCalls())
+
+	require.NoError(t, h.OnStopAlarm(t.Context()))
+	require.Equal(t, string(PlaybackStatusStopped), getProperty[string](t, client, playerInterface, "PlaybackStatus"))
+}
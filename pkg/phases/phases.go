@@ -0,0 +1,389 @@
+package phases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/state"
+)
+
+type Phase string
+
+const (
+	PhaseWork  Phase = "work"
+	PhaseBreak Phase = "break"
+)
+
+// Next returns the phase that follows a phase
+func (p Phase) Next() Phase {
+	if p == PhaseBreak {
+		return PhaseWork
+	}
+
+	return PhaseBreak
+}
+
+// Strictness limits how often a break can be postponed
+type Strictness string
+
+const (
+	StrictnessLenient  Strictness = "lenient"
+	StrictnessModerate Strictness = "moderate"
+	StrictnessStrict   Strictness = "strict"
+)
+
+var (
+	Strictnesses = []Strictness{StrictnessLenient, StrictnessModerate, StrictnessStrict}
+
+	ErrUnknownStrictness = errors.New("unknown strictness")
+	ErrNotBreak          = errors.New("not in a break")
+	ErrPostponeLimit     = errors.New("break can't be postponed any more")
+)
+
+// ParseStrictness parses a strictness by its name
+func ParseStrictness(name string) (Strictness, error) {
+	for _, strictness := range Strictnesses {
+		if string(strictness) == name {
+			return strictness, nil
+		}
+	}
+
+	return "", ErrUnknownStrictness
+}
+
+// Postpones returns how often a break can be postponed, or a negative number if there is no limit
+func (s Strictness) Postpones() int {
+	switch s {
+	case StrictnessLenient:
+		return -1
+
+	case StrictnessModerate:
+		return 1
+
+	default:
+		return 0
+	}
+}
+
+type withoutAdvancingKey struct{}
+
+// WithoutAdvancing returns a context for stopping the alarm without advancing to the next phase,
+// e.g. because a different duration is set right afterwards
+func WithoutAdvancing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutAdvancingKey{}, true)
+}
+
+// Timer is the part of the state machine that the advancer controls
+type Timer interface {
+	StartTimer(ctx context.Context) error
+	StopTimer(ctx context.Context) error
+	StopAlarming(ctx context.Context) error
+	SetInitialRemainingTime(ctx context.Context, initialRemainingTime time.Duration) error
+}
+
+// Config configures which phases start automatically
+type Config struct {
+	BreakDuration time.Duration
+
+	// Whether work and breaks alternate even if no phase starts automatically, e.g. because breaks
+	// are shown differently
+	Alternate bool
+
+	AutoStartWork   bool
+	AutoStartBreaks bool
+
+	// Time after which a ringing alarm is stopped so that the next phase starts, zero to wait
+	// until it is stopped
+	GracePeriod time.Duration
+
+	Strictness Strictness
+}
+
+// Enabled returns whether work and breaks alternate, which is also the case if any phase starts automatically
+func (c Config) Enabled() bool {
+	return c.Alternate || c.AutoStartWork || c.AutoStartBreaks
+}
+
+func (c Config) autoStart(phase Phase) bool {
+	if phase == PhaseBreak {
+		return c.AutoStartBreaks
+	}
+
+	return c.AutoStartWork
+}
+
+type Hooks struct {
+	// OnPhaseChange is called with the next phase before the timer is set up for it
+	OnPhaseChange func(ctx context.Context, phase Phase) error
+	// OnAutoStart is called after the countdown of a phase was started automatically
+	OnAutoStart func(ctx context.Context, phase Phase) error
+}
+
+// Advancer alternates between work and breaks through its hooks. Once the alarm of a phase is
+// stopped, the timer is set to the next phase's duration and, if configured, started.
+type Advancer struct {
+	ctx   context.Context
+	log   *slog.Logger
+	timer Timer
+	hooks *Hooks
+
+	lock   sync.Mutex
+	config Config
+	phase  Phase
+	// Duration of work phases, which is restored after breaks
+	workDuration time.Duration
+	// Whether we are changing the timer ourselves, so that we don't take break durations for work durations
+	advancing bool
+	grace     *time.Timer
+	// How often the current break was postponed
+	postpones int
+	// Whether the break was postponed, which starts it again afterwards. The phase stays a break
+	// in the meantime, so that the delay isn't taken for work.
+	postponed bool
+}
+
+func NewAdvancer(ctx context.Context, log *slog.Logger, timer Timer, workDuration time.Duration, hooks *Hooks) *Advancer {
+	return &Advancer{
+		ctx:   ctx,
+		log:   log,
+		timer: timer,
+		hooks: hooks,
+
+		phase:        PhaseWork,
+		workDuration: workDuration,
+	}
+}
+
+// Phase returns the current phase
+func (a *Advancer) Phase() Phase {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.phase
+}
+
+// Postponed returns whether the current break was postponed and hasn't started again yet
+func (a *Advancer) Postponed() bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.postponed
+}
+
+// SetConfig changes which phases start automatically. A break that is in progress when
+// alternating is disabled still ends with work.
+func (a *Advancer) SetConfig(config Config) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.config = config
+}
+
+// Skip stops the current phase, if it is running, and advances to the next one
+func (a *Advancer) Skip(ctx context.Context) error {
+	// The timer might already be stopped
+	if err := a.timer.StopTimer(ctx); err != nil {
+		a.log.Debug("Could not stop timer before skipping phase", "err", err)
+	}
+
+	return a.advance(ctx)
+}
+
+// CanPostpone returns whether the current phase is a break that can still be postponed
+func (a *Advancer) CanPostpone() bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.canPostpone() == nil
+}
+
+func (a *Advancer) canPostpone() error {
+	if a.phase != PhaseBreak || a.postponed {
+		return ErrNotBreak
+	}
+
+	if limit := a.config.Strictness.Postpones(); limit >= 0 && a.postpones >= limit {
+		return ErrPostponeLimit
+	}
+
+	return nil
+}
+
+// Postpone stops the current break and starts it again once the timer has counted down the delay
+func (a *Advancer) Postpone(ctx context.Context, delay time.Duration) error {
+	a.lock.Lock()
+
+	if err := a.canPostpone(); err != nil {
+		a.lock.Unlock()
+
+		return err
+	}
+
+	a.cancelGrace()
+
+	a.postpones++
+	a.postponed = true
+	a.advancing = true
+
+	a.lock.Unlock()
+
+	defer func() {
+		a.lock.Lock()
+		defer a.lock.Unlock()
+
+		a.advancing = false
+	}()
+
+	// The timer might already be stopped
+	if err := a.timer.StopTimer(ctx); err != nil {
+		a.log.Debug("Could not stop timer before postponing break", "err", err)
+	}
+
+	if err := a.timer.SetInitialRemainingTime(ctx, delay); err != nil {
+		return err
+	}
+
+	return a.timer.StartTimer(ctx)
+}
+
+// autoStart returns whether a phase starts automatically, which postponed breaks always do
+func (a *Advancer) autoStart(phase Phase) bool {
+	return a.config.autoStart(phase) || (phase == PhaseBreak && a.postponed)
+}
+
+// next returns the phase that follows the current one, which is the break again if it was postponed
+func (a *Advancer) next() Phase {
+	if a.postponed {
+		return a.phase
+	}
+
+	return a.phase.Next()
+}
+
+func (a *Advancer) cancelGrace() {
+	if a.grace != nil {
+		a.grace.Stop()
+		a.grace = nil
+	}
+}
+
+func (a *Advancer) advance(ctx context.Context) error {
+	a.lock.Lock()
+
+	a.cancelGrace()
+
+	if a.phase == PhaseWork && !a.config.Enabled() {
+		a.lock.Unlock()
+
+		return nil
+	}
+
+	a.phase = a.next()
+	a.advancing = true
+
+	var (
+		phase     = a.phase
+		autoStart = a.autoStart(phase)
+		duration  = a.workDuration
+	)
+	if phase == PhaseBreak {
+		duration = a.config.BreakDuration
+	} else {
+		// Each break can be postponed again
+		a.postpones = 0
+	}
+	a.postponed = false
+
+	a.lock.Unlock()
+
+	defer func() {
+		a.lock.Lock()
+		defer a.lock.Unlock()
+
+		a.advancing = false
+	}()
+
+	if a.hooks.OnPhaseChange != nil {
+		if err := a.hooks.OnPhaseChange(ctx, phase); err != nil {
+			return err
+		}
+	}
+
+	if err := a.timer.SetInitialRemainingTime(ctx, duration); err != nil {
+		return err
+	}
+
+	if !autoStart {
+		return nil
+	}
+
+	if err := a.timer.StartTimer(ctx); err != nil {
+		return err
+	}
+
+	if a.hooks.OnAutoStart != nil {
+		if err := a.hooks.OnAutoStart(ctx, phase); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Hooks returns state machine hooks that advance to the next phase, which can be combined with other hooks
+func (a *Advancer) Hooks() *state.Hooks {
+	return &state.Hooks{
+		OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
+			a.lock.Lock()
+			defer a.lock.Unlock()
+
+			// Adjusting the timer during a break or while it is postponed only changes that break
+			if a.phase == PhaseWork && !a.advancing {
+				a.workDuration = initialRemainingTime
+			}
+
+			return nil
+		},
+
+		OnStartAlarm: func(ctx context.Context) error {
+			a.lock.Lock()
+			defer a.lock.Unlock()
+
+			if a.config.GracePeriod <= 0 || !a.autoStart(a.next()) {
+				return nil
+			}
+
+			a.cancelGrace()
+			a.grace = time.AfterFunc(a.config.GracePeriod, func() {
+				// The alarm might have been stopped in the meantime, which already advanced
+				if err := a.timer.StopAlarming(a.ctx); err != nil {
+					a.log.Debug("Could not stop alarm after grace period", "err", err)
+				}
+			})
+
+			return nil
+		},
+		OnStopAlarm: func(ctx context.Context) error {
+			if withoutAdvancing, _ := ctx.Value(withoutAdvancingKey{}).(bool); withoutAdvancing {
+				a.lock.Lock()
+				defer a.lock.Unlock()
+
+				a.cancelGrace()
+
+				return nil
+			}
+
+			// The timer can't be changed while it is still transitioning, so we advance afterwards
+			go func() {
+				if err := a.advance(a.ctx); err != nil {
+					a.log.Warn("Could not advance to next phase", "err", err)
+				}
+			}()
+
+			return nil
+		},
+	}
+}
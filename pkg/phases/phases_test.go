@@ -0,0 +1,431 @@
+package phases
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/neilotoole/slogt"
+	"github.com/pojntfx/sessions/pkg/history"
+	"github.com/pojntfx/sessions/pkg/state"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWorkDuration  = time.Minute * 25
+	testBreakDuration = time.Minute * 5
+)
+
+type event struct {
+	name  string
+	phase Phase
+}
+
+// newAdvancer returns an advancer, the state machine that it controls and the phase events
+// it records. The state machine also calls the other hooks.
+func newAdvancer(t *testing.T, config Config, hooks ...*state.Hooks) (*Advancer, *state.StateMachine, *[]event) {
+	t.Helper()
+
+	var (
+		s        *state.StateMachine
+		events   = &[]event{}
+		advancer *Advancer
+	)
+	advancer = NewAdvancer(t.Context(), slogt.New(t), &lazyTimer{&s}, testWorkDuration, &Hooks{
+		OnPhaseChange: func(ctx context.Context, phase Phase) error {
+			*events = append(*events, event{"change", phase})
+
+			return nil
+		},
+		OnAutoStart: func(ctx context.Context, phase Phase) error {
+			*events = append(*events, event{"autoStart", phase})
+
+			return nil
+		},
+	})
+	advancer.SetConfig(config)
+
+	s = state.NewStateMachine(t.Context(), testWorkDuration, slogt.New(t), state.CombineHooks(append([]*state.Hooks{advancer.Hooks()}, hooks...)...))
+	t.Cleanup(func() {
+		_ = s.StopTimer(context.Background())
+	})
+
+	return advancer, s, events
+}
+
+// lazyTimer forwards to a state machine that is only created after the advancer, since it needs its hooks
+type lazyTimer struct {
+	s **state.StateMachine
+}
+
+func (t *lazyTimer) StartTimer(ctx context.Context) error   { return (*t.s).StartTimer(ctx) }
+func (t *lazyTimer) StopTimer(ctx context.Context) error    { return (*t.s).StopTimer(ctx) }
+func (t *lazyTimer) StopAlarming(ctx context.Context) error { return (*t.s).StopAlarming(ctx) }
+func (t *lazyTimer) SetInitialRemainingTime(ctx context.Context, initialRemainingTime time.Duration) error {
+	return (*t.s).SetInitialRemainingTime(ctx, initialRemainingTime)
+}
+
+func TestPhaseNext(t *testing.T) {
+	require.Equal(t, PhaseBreak, PhaseWork.Next())
+	require.Equal(t, PhaseWork, PhaseBreak.Next())
+}
+
+var advancerTests = []struct {
+	name   string
+	config Config
+	run    func(t *testing.T, s *state.StateMachine)
+	// Phase and state of the timer at the end
+	phase   Phase
+	running bool
+	events  []event
+}{
+	{
+		name:   "nothing changes without auto-start",
+		config: Config{BreakDuration: testBreakDuration},
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+			time.Sleep(testWorkDuration + time.Second)
+			synctest.Wait()
+
+			require.NoError(t, s.StopAlarming(t.Context()))
+		},
+		phase:  PhaseWork,
+		events: []event{},
+	},
+	{
+		name:   "break is set up if phases alternate without auto-start",
+		config: Config{BreakDuration: testBreakDuration, Alternate: true},
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+			time.Sleep(testWorkDuration + time.Second)
+			synctest.Wait()
+
+			require.NoError(t, s.StopAlarming(t.Context()))
+		},
+		phase:  PhaseBreak,
+		events: []event{{"change", PhaseBreak}},
+	},
+	{
+		name:   "break starts when the alarm is stopped",
+		config: Config{BreakDuration: testBreakDuration, AutoStartBreaks: true},
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+			time.Sleep(testWorkDuration + time.Second)
+			synctest.Wait()
+
+			require.NoError(t, s.StopAlarming(t.Context()))
+		},
+		phase:   PhaseBreak,
+		running: true,
+		events:  []event{{"change", PhaseBreak}, {"autoStart", PhaseBreak}},
+	},
+	{
+		name:   "break starts after the grace period",
+		config: Config{BreakDuration: testBreakDuration, AutoStartBreaks: true, GracePeriod: time.Minute},
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+			time.Sleep(testWorkDuration + time.Second + time.Minute)
+		},
+		phase:   PhaseBreak,
+		running: true,
+		events:  []event{{"change", PhaseBreak}, {"autoStart", PhaseBreak}},
+	},
+	{
+		name:   "work is only set up if it doesn't start automatically",
+		config: Config{BreakDuration: testBreakDuration, AutoStartBreaks: true, GracePeriod: time.Minute},
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+			time.Sleep(testWorkDuration + time.Second + time.Minute)
+
+			// The grace period only applies if the next phase starts automatically
+			time.Sleep(testBreakDuration + time.Second + time.Minute)
+			synctest.Wait()
+
+			require.NoError(t, s.StopAlarming(t.Context()))
+		},
+		phase:  PhaseWork,
+		events: []event{{"change", PhaseBreak}, {"autoStart", PhaseBreak}, {"change", PhaseWork}},
+	},
+	{
+		name:   "work and breaks alternate",
+		config: Config{BreakDuration: testBreakDuration, AutoStartBreaks: true, AutoStartWork: true, GracePeriod: time.Minute},
+		run: func(t *testing.T, s *state.StateMachine) {
+			require.NoError(t, s.StartTimer(t.Context()))
+			time.Sleep(testWorkDuration + time.Second + time.Minute)
+			time.Sleep(testBreakDuration + time.Second + time.Minute)
+		},
+		phase:   PhaseWork,
+		running: true,
+		events:  []event{{"change", PhaseBreak}, {"autoStart", PhaseBreak}, {"change", PhaseWork}, {"autoStart", PhaseWork}},
+	},
+}
+
+func TestAdvancer(t *testing.T) {
+	for _, tt := range advancerTests {
+		t.Run(tt.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				advancer, s, events := newAdvancer(t, tt.config)
+
+				tt.run(t, s)
+				synctest.Wait()
+
+				require.Equal(t, tt.phase, advancer.Phase())
+				require.Equal(t, tt.events, *events)
+
+				// Only a running timer can be paused
+				err := s.PauseTimer(t.Context())
+				if tt.running {
+					require.NoError(t, err)
+				} else {
+					require.Error(t, err)
+				}
+			})
+		})
+	}
+}
+
+func TestAdvancerRestoresWorkDuration(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		durations := []time.Duration{}
+		advancer, s, _ := newAdvancer(t, Config{BreakDuration: testBreakDuration, AutoStartBreaks: true}, &state.Hooks{
+			OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
+				durations = append(durations, initialRemainingTime)
+
+				return nil
+			},
+		})
+
+		// Adjusting the timer during work changes the work duration
+		require.NoError(t, s.SetInitialRemainingTime(t.Context(), time.Minute*50))
+
+		require.NoError(t, s.StartTimer(t.Context()))
+		time.Sleep(time.Minute*50 + time.Second)
+		synctest.Wait()
+
+		require.NoError(t, s.StopAlarming(t.Context()))
+		synctest.Wait()
+		require.Equal(t, PhaseBreak, advancer.Phase())
+
+		// Adjusting the timer during a break only changes that break
+		require.NoError(t, s.PlusTimer(t.Context()))
+
+		require.NoError(t, advancer.Skip(t.Context()))
+		require.Equal(t, PhaseWork, advancer.Phase())
+
+		require.Equal(t, []time.Duration{
+			time.Minute * 50,
+			testBreakDuration,
+			testBreakDuration + state.RemainingTimerAdjustmentInterval,
+			time.Minute * 50,
+		}, durations)
+	})
+}
+
+func TestAdvancerWithoutAdvancing(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		advancer, s, events := newAdvancer(t, Config{BreakDuration: testBreakDuration, AutoStartBreaks: true, GracePeriod: time.Minute})
+
+		require.NoError(t, s.StartTimer(t.Context()))
+		time.Sleep(testWorkDuration + time.Second)
+		synctest.Wait()
+
+		// Setting a different duration after stopping the alarm keeps the phase and the duration
+		require.NoError(t, s.StopAlarming(WithoutAdvancing(t.Context())))
+		require.NoError(t, s.SetInitialRemainingTime(t.Context(), time.Minute*10))
+
+		// The grace period doesn't advance either
+		time.Sleep(time.Minute * 2)
+		synctest.Wait()
+
+		require.Equal(t, PhaseWork, advancer.Phase())
+		require.Empty(t, *events)
+
+		require.NoError(t, s.StartTimer(t.Context()))
+		time.Sleep(time.Minute*10 + time.Second)
+		synctest.Wait()
+
+		require.NoError(t, s.StopAlarming(t.Context()))
+		synctest.Wait()
+		require.Equal(t, PhaseBreak, advancer.Phase())
+	})
+}
+
+func TestParseStrictness(t *testing.T) {
+	for _, strictness := range Strictnesses {
+		parsed, err := ParseStrictness(string(strictness))
+		require.NoError(t, err)
+		require.Equal(t, strictness, parsed)
+	}
+
+	_, err := ParseStrictness("relaxed")
+	require.ErrorIs(t, err, ErrUnknownStrictness)
+}
+
+var postponeTests = []struct {
+	name       string
+	strictness Strictness
+	// Number of times that the break is postponed
+	postpones int
+	// Error of the last postpone
+	err    error
+	events []event
+}{
+	{
+		name:       "lenient breaks can be postponed repeatedly",
+		strictness: StrictnessLenient,
+		postpones:  3,
+		events: []event{
+			{"change", PhaseBreak}, {"autoStart", PhaseBreak},
+			{"change", PhaseBreak}, {"autoStart", PhaseBreak},
+			{"change", PhaseBreak}, {"autoStart", PhaseBreak},
+			{"change", PhaseBreak}, {"autoStart", PhaseBreak},
+		},
+	},
+	{
+		name:       "moderate breaks can be postponed once",
+		strictness: StrictnessModerate,
+		postpones:  2,
+		err:        ErrPostponeLimit,
+		events: []event{
+			{"change", PhaseBreak}, {"autoStart", PhaseBreak},
+			{"change", PhaseBreak}, {"autoStart", PhaseBreak},
+		},
+	},
+	{
+		name:       "strict breaks can't be postponed",
+		strictness: StrictnessStrict,
+		postpones:  1,
+		err:        ErrPostponeLimit,
+		events:     []event{{"change", PhaseBreak}, {"autoStart", PhaseBreak}},
+	},
+}
+
+func TestAdvancerPostpone(t *testing.T) {
+	for _, tt := range postponeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				advancer, s, events := newAdvancer(t, Config{BreakDuration: testBreakDuration, AutoStartBreaks: true, GracePeriod: time.Minute, Strictness: tt.strictness})
+
+				require.ErrorIs(t, advancer.Postpone(t.Context(), time.Minute*2), ErrNotBreak)
+
+				require.NoError(t, s.StartTimer(t.Context()))
+				time.Sleep(testWorkDuration + time.Second + time.Minute)
+				synctest.Wait()
+
+				var err error
+				for range tt.postpones {
+					if err = advancer.Postpone(t.Context(), time.Minute*2); err != nil {
+						break
+					}
+					require.Equal(t, PhaseBreak, advancer.Phase())
+					require.True(t, advancer.Postponed())
+					require.False(t, advancer.CanPostpone())
+
+					// Postponed breaks start again even though only breaks start automatically
+					time.Sleep(time.Minute*2 + time.Second + time.Minute)
+					synctest.Wait()
+					require.Equal(t, PhaseBreak, advancer.Phase())
+					require.False(t, advancer.Postponed())
+				}
+				require.ErrorIs(t, err, tt.err)
+				require.Equal(t, tt.err == nil, advancer.CanPostpone())
+
+				require.Equal(t, tt.events, *events)
+			})
+		})
+	}
+}
+
+func TestAdvancerPostponeKeepsWorkDuration(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		advancer, s, _ := newAdvancer(t, Config{BreakDuration: testBreakDuration, AutoStartBreaks: true, Strictness: StrictnessLenient})
+
+		require.NoError(t, s.StartTimer(t.Context()))
+		time.Sleep(testWorkDuration + time.Second)
+		synctest.Wait()
+
+		require.NoError(t, s.StopAlarming(t.Context()))
+		synctest.Wait()
+
+		require.NoError(t, advancer.Postpone(t.Context(), time.Minute*2))
+
+		// Adjusting the timer while the break is postponed only changes the delay
+		require.NoError(t, s.PlusTimer(t.Context()))
+
+		// Postponed breaks start again without a grace period once the alarm is stopped
+		time.Sleep(time.Minute*2 + state.RemainingTimerAdjustmentInterval + time.Second)
+		synctest.Wait()
+
+		require.NoError(t, s.StopAlarming(t.Context()))
+		synctest.Wait()
+		require.Equal(t, PhaseBreak, advancer.Phase())
+
+		require.NoError(t, advancer.Skip(t.Context()))
+		require.Equal(t, PhaseWork, advancer.Phase())
+
+		// Each break can be postponed again
+		require.NoError(t, s.StartTimer(t.Context()))
+		time.Sleep(testWorkDuration + time.Second)
+		synctest.Wait()
+
+		require.NoError(t, s.StopAlarming(t.Context()))
+		synctest.Wait()
+		require.True(t, advancer.CanPostpone())
+	})
+}
+
+func TestAdvancerPostponeIsRecordedAsBreak(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		log := history.NewLog(filepath.Join(t.TempDir(), "history.jsonl"))
+		recorder := history.NewRecorder(slogt.New(t), log)
+
+		var (
+			s        *state.StateMachine
+			advancer *Advancer
+		)
+		advancer = NewAdvancer(t.Context(), slogt.New(t), &lazyTimer{&s}, testWorkDuration, &Hooks{
+			OnPhaseChange: func(ctx context.Context, phase Phase) error {
+				recorder.SetBreak(phase == PhaseBreak)
+
+				return nil
+			},
+		})
+		advancer.SetConfig(Config{BreakDuration: testBreakDuration, AutoStartBreaks: true, Strictness: StrictnessLenient})
+
+		s = state.NewStateMachine(t.Context(), testWorkDuration, slogt.New(t), state.CombineHooks(recorder.Hooks(), advancer.Hooks()))
+		t.Cleanup(func() {
+			_ = s.StopTimer(context.Background())
+		})
+
+		require.NoError(t, s.StartTimer(t.Context()))
+		time.Sleep(testWorkDuration + time.Second)
+		synctest.Wait()
+
+		require.NoError(t, s.StopAlarming(t.Context()))
+		synctest.Wait()
+
+		require.NoError(t, advancer.Postpone(t.Context(), time.Minute*2))
+
+		// The postponed break runs out, which starts the break again
+		time.Sleep(time.Minute*2 + time.Second)
+		synctest.Wait()
+
+		require.NoError(t, s.StopAlarming(t.Context()))
+		synctest.Wait()
+
+		sessions, err := log.Read()
+		require.NoError(t, err)
+		require.Len(t, sessions, 3)
+
+		// The work session, the break until it was postponed and the delay, which ran out but
+		// mustn't count as a completed work session
+		breaks := []bool{}
+		for _, session := range sessions {
+			breaks = append(breaks, session.Break)
+		}
+		require.Equal(t, []bool{false, true, true}, breaks)
+		require.True(t, sessions[2].Completed)
+	})
+}
@@ -0,0 +1,212 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/pojntfx/sessions/pkg/geometry"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+const (
+	// Maximum distance between a flattened arc and the real arc in pixels
+	flatteningTolerance = 0.1
+	// Upper bound for the number of lines that an arc is flattened into
+	maxArcSteps = 1024
+)
+
+type point struct {
+	x, y float64
+}
+
+type contour struct {
+	points []point
+	closed bool
+}
+
+func arcSteps(r, sweep float64) int {
+	if r <= flatteningTolerance {
+		return 1
+	}
+
+	step := 2 * math.Acos(1-flatteningTolerance/r)
+
+	return max(1, min(maxArcSteps, int(math.Ceil(math.Abs(sweep)/step))))
+}
+
+// flatten converts a path into contours of straight lines
+func flatten(path Path) []contour {
+	contours := []contour{}
+
+	for _, segment := range path.Segments {
+		switch segment.Kind {
+		case SegmentMoveTo:
+			contours = append(contours, contour{points: []point{{segment.X, segment.Y}}})
+
+		case SegmentLineTo, SegmentArc:
+			if len(contours) == 0 {
+				contours = append(contours, contour{})
+			}
+			c := &contours[len(contours)-1]
+
+			if segment.Kind == SegmentLineTo {
+				c.points = append(c.points, point{segment.X, segment.Y})
+
+				continue
+			}
+
+			steps := arcSteps(segment.R, segment.EndAngle-segment.StartAngle)
+			for i := 1; i <= steps; i++ {
+				angle := segment.StartAngle + (segment.EndAngle-segment.StartAngle)*float64(i)/float64(steps)
+
+				x, y := geometry.AngleToPosition(segment.X, segment.Y, segment.R, angle)
+				c.points = append(c.points, point{x, y})
+			}
+
+		case SegmentCircle:
+			steps := arcSteps(segment.R, 2*math.Pi)
+
+			c := contour{closed: true}
+			for i := range steps {
+				x, y := geometry.AngleToPosition(segment.X, segment.Y, segment.R, 2*math.Pi*float64(i)/float64(steps))
+				c.points = append(c.points, point{x, y})
+			}
+
+			contours = append(contours, c)
+		}
+	}
+
+	return contours
+}
+
+func addPolygon(z *vector.Rasterizer, points ...point) {
+	if len(points) < 3 {
+		return
+	}
+
+	z.MoveTo(float32(points[0].x), float32(points[0].y))
+	for _, p := range points[1:] {
+		z.LineTo(float32(p.x), float32(p.y))
+	}
+	z.ClosePath()
+}
+
+// addDisc adds a disc with the same winding direction as the quads of addStroke, since
+// overlapping shapes with opposite winding directions would cancel each other out
+func addDisc(z *vector.Rasterizer, center point, r float64) {
+	steps := arcSteps(r, 2*math.Pi)
+
+	points := []point{}
+	for i := range steps {
+		x, y := geometry.AngleToPosition(center.x, center.y, r, -2*math.Pi*float64(i)/float64(steps))
+		points = append(points, point{x, y})
+	}
+
+	addPolygon(z, points...)
+}
+
+// addStroke outlines every line of the contour with a quad and joins them with discs
+func addStroke(z *vector.Rasterizer, c contour, lineWidth float64, roundCap bool) {
+	h := lineWidth / 2
+
+	points := c.points
+	if c.closed && len(points) > 0 {
+		points = append(points, points[0])
+	}
+
+	var previousDirection point
+	for i := 0; i+1 < len(points); i++ {
+		p1, p2 := points[i], points[i+1]
+
+		dx, dy := p2.x-p1.x, p2.y-p1.y
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		direction := point{dx / length, dy / length}
+		n := point{-direction.y * h, direction.x * h}
+
+		addPolygon(
+			z,
+			point{p1.x + n.x, p1.y + n.y},
+			point{p2.x + n.x, p2.y + n.y},
+			point{p2.x - n.x, p2.y - n.y},
+			point{p1.x - n.x, p1.y - n.y},
+		)
+
+		// Flattened arcs change direction only slightly at every point, so the gaps
+		// between their quads are far below a pixel and don't need a join
+		if i > 0 && previousDirection.x*direction.x+previousDirection.y*direction.y < math.Cos(math.Pi/36) {
+			addDisc(z, p1, h)
+		}
+		previousDirection = direction
+	}
+
+	if roundCap && !c.closed && len(c.points) > 0 {
+		addDisc(z, c.points[0], h)
+		addDisc(z, c.points[len(c.points)-1], h)
+	}
+}
+
+func toNRGBA(c Color) color.NRGBA {
+	channel := func(v float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+
+	return color.NRGBA{R: channel(c.R), G: channel(c.G), B: channel(c.B), A: channel(c.A)}
+}
+
+// Rasterize draws the scene onto a transparent image with the given size. Text is drawn
+// with a fixed-size bitmap font, so it doesn't scale with the image.
+func Rasterize(width, height int, ops []Op) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for _, op := range ops {
+		src := image.NewUniform(toNRGBA(op.Color))
+
+		switch op.Kind {
+		case OpFill, OpStroke:
+			z := vector.NewRasterizer(width, height)
+
+			for _, c := range flatten(op.Path) {
+				if op.Kind == OpFill {
+					addPolygon(z, c.points...)
+				} else {
+					addStroke(z, c, op.LineWidth, op.RoundCap)
+				}
+			}
+
+			z.Draw(img, img.Bounds(), src, image.Point{})
+
+		case OpText:
+			face := basicfont.Face7x13
+			metrics := face.Metrics()
+
+			d := font.Drawer{
+				Dst:  img,
+				Src:  src,
+				Face: face,
+			}
+			textWidth := d.MeasureString(op.Text)
+
+			d.Dot = fixed.Point26_6{
+				X: fixed.I(int(math.Round(op.X))) - textWidth/2,
+				Y: fixed.I(int(math.Round(op.Y))) + (metrics.Ascent-metrics.Descent)/2,
+			}
+			d.DrawString(op.Text)
+		}
+	}
+
+	return img
+}
+
+// WritePNG writes the scene as a PNG image with the given size to w
+func WritePNG(w io.Writer, width, height int, ops []Op) error {
+	return png.Encode(w, Rasterize(width, height, ops))
+}
@@ -0,0 +1,324 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/geometry"
+)
+
+// Color is a non-premultiplied RGBA color with components in [0, 1]
+type Color struct {
+	R, G, B, A float64
+}
+
+// Palette contains the named colors that the dial is drawn with
+type Palette struct {
+	Accent,
+	Error,
+	Destructive,
+	WindowBackground,
+	WindowForeground,
+	Track Color
+}
+
+var (
+	// LightPalette matches the default libadwaita light style
+	LightPalette = Palette{
+		Accent:           Color{R: 0x35 / 255.0, G: 0x84 / 255.0, B: 0xe4 / 255.0, A: 1},
+		Error:            Color{R: 0xc0 / 255.0, G: 0x1c / 255.0, B: 0x28 / 255.0, A: 1},
+		Destructive:      Color{R: 0xc0 / 255.0, G: 0x1c / 255.0, B: 0x28 / 255.0, A: 1},
+		WindowBackground: Color{R: 0xfa / 255.0, G: 0xfa / 255.0, B: 0xfb / 255.0, A: 1},
+		WindowForeground: Color{R: 0, G: 0, B: 0x06 / 255.0, A: 0.8},
+		Track:            Color{R: 0xd8 / 255.0, G: 0xd8 / 255.0, B: 0xd8 / 255.0, A: 1},
+	}
+
+	// DarkPalette matches the default libadwaita dark style
+	DarkPalette = Palette{
+		Accent:           Color{R: 0x35 / 255.0, G: 0x84 / 255.0, B: 0xe4 / 255.0, A: 1},
+		Error:            Color{R: 0xff / 255.0, G: 0x7b / 255.0, B: 0x63 / 255.0, A: 1},
+		Destructive:      Color{R: 0xff / 255.0, G: 0x7b / 255.0, B: 0x63 / 255.0, A: 1},
+		WindowBackground: Color{R: 0x22 / 255.0, G: 0x22 / 255.0, B: 0x26 / 255.0, A: 1},
+		WindowForeground: Color{R: 1, G: 1, B: 1, A: 1},
+		Track:            Color{R: 0x34 / 255.0, G: 0x34 / 255.0, B: 0x37 / 255.0, A: 1},
+	}
+)
+
+// Options describe what to draw
+type Options struct {
+	Width, Height float64
+
+	RemainingTime time.Duration
+	CountingDown  bool
+	// Disabled dials are drawn like insensitive widgets, which is the case while alarming
+	Disabled bool
+	Focused  bool
+
+	HighContrast bool
+	ShowLabels   bool
+
+	Palette Palette
+}
+
+type SegmentKind int
+
+const (
+	SegmentMoveTo SegmentKind = iota
+	SegmentLineTo
+	// Arcs start at the current point and go around the center, clockwise if the
+	// end angle is larger than the start angle and counter-clockwise otherwise
+	SegmentArc
+	// Circles start a new closed contour
+	SegmentCircle
+)
+
+// Segment is a part of a path. Angles are measured clockwise from 12 o'clock.
+type Segment struct {
+	Kind SegmentKind
+
+	// Position for move and line segments, center for arc and circle segments
+	X, Y float64
+	R    float64
+
+	StartAngle,
+	EndAngle float64
+}
+
+// Path is a list of segments
+type Path struct {
+	Segments []Segment
+}
+
+func (p *Path) MoveTo(x, y float64) {
+	p.Segments = append(p.Segments, Segment{Kind: SegmentMoveTo, X: x, Y: y})
+}
+
+func (p *Path) LineTo(x, y float64) {
+	p.Segments = append(p.Segments, Segment{Kind: SegmentLineTo, X: x, Y: y})
+}
+
+func (p *Path) ArcTo(cx, cy, r, startAngle, endAngle float64) {
+	p.Segments = append(p.Segments, Segment{Kind: SegmentArc, X: cx, Y: cy, R: r, StartAngle: startAngle, EndAngle: endAngle})
+}
+
+func (p *Path) Circle(cx, cy, r float64) {
+	p.Segments = append(p.Segments, Segment{Kind: SegmentCircle, X: cx, Y: cy, R: r})
+}
+
+type OpKind int
+
+const (
+	// Fills the path using the non-zero winding rule
+	OpFill OpKind = iota
+	// Strokes the path
+	OpStroke
+	// Draws text centered on a position
+	OpText
+)
+
+// Op is a single drawing operation
+type Op struct {
+	Kind  OpKind
+	Color Color
+
+	Path      Path
+	LineWidth float64
+	RoundCap  bool
+
+	Text     string
+	X, Y     float64
+	FontSize float64
+}
+
+// Scene returns the drawing operations for a dial, in painting order
+func Scene(o Options) []Op {
+	ops := []Op{}
+
+	cx, cy := geometry.Center(o.Width, o.Height)
+	r := geometry.Radius(o.Width, o.Height)
+
+	trackColor := o.Palette.Track
+	if o.Disabled {
+		// Matches the text colour of a .destructive-action libadwaita button
+		const disabledOpacity = 0.5
+		a := disabledOpacity * o.Palette.Destructive.A
+		trackColor = Color{
+			R: o.Palette.Destructive.R*a + o.Palette.WindowBackground.R*(1-a),
+			G: o.Palette.Destructive.G*a + o.Palette.WindowBackground.G*(1-a),
+			B: o.Palette.Destructive.B*a + o.Palette.WindowBackground.B*(1-a),
+			A: 1.0,
+		}
+	}
+
+	borderColor := o.Palette.WindowForeground
+	borderColor.A = 0.5
+
+	strokeBorder := func(path Path) {
+		if o.HighContrast {
+			ops = append(ops, Op{Kind: OpStroke, Path: path, Color: borderColor, LineWidth: 1})
+		}
+	}
+
+	circle := func(r float64) Path {
+		var path Path
+		path.Circle(cx, cy, r)
+
+		return path
+	}
+
+	// Every ring that the maximum remaining time could need gets its own track
+	maxRings := geometry.MaxRings()
+	for ring := range maxRings {
+		ringR := geometry.RingRadius(o.Width, o.Height, ring)
+
+		ops = append(ops, Op{Kind: OpStroke, Path: circle(ringR), Color: trackColor, LineWidth: 10})
+
+		strokeBorder(circle(ringR + 5))
+		strokeBorder(circle(ringR - 5))
+	}
+
+	if o.Focused {
+		// Matches the focus ring of libadwaita widgets
+		focusRingColor := o.Palette.Accent
+		focusRingColor.A = 0.5
+
+		ops = append(ops, Op{Kind: OpStroke, Path: circle(r + 9), Color: focusRingColor, LineWidth: 2})
+	}
+
+	// Tick marks go on the inside of the innermost track so that they don't overlap with the rings
+	tickR := geometry.RingRadius(o.Width, o.Height, maxRings-1) - 9
+	for _, major := range []bool{false, true} {
+		tickColor := o.Palette.WindowForeground
+		tickLength, tickWidth := 4.0, 1.0
+		tickColor.A = 0.25
+		if major {
+			tickLength, tickWidth = 8.0, 2.0
+			tickColor.A = 0.5
+		}
+		if o.HighContrast {
+			tickColor.A = 1.0
+		}
+
+		var ticksPath Path
+		for _, tick := range geometry.Ticks() {
+			if tick.Major != major {
+				continue
+			}
+
+			ticksPath.MoveTo(geometry.AngleToPosition(cx, cy, tickR, tick.Angle))
+			ticksPath.LineTo(geometry.AngleToPosition(cx, cy, tickR-tickLength, tick.Angle))
+		}
+
+		ops = append(ops, Op{Kind: OpStroke, Path: ticksPath, Color: tickColor, LineWidth: tickWidth, RoundCap: true})
+	}
+
+	if o.ShowLabels {
+		labelColor := o.Palette.WindowForeground
+		if !o.HighContrast {
+			labelColor.A = 0.7
+		}
+
+		for _, tick := range geometry.Ticks() {
+			if !tick.Major {
+				continue
+			}
+
+			x, y := geometry.AngleToPosition(cx, cy, tickR-20, tick.Angle)
+
+			ops = append(ops, Op{
+				Kind:     OpText,
+				Color:    labelColor,
+				Text:     fmt.Sprintf("%d", int(tick.RemainingTime.Minutes())),
+				X:        x,
+				Y:        y,
+				FontSize: 11,
+			})
+		}
+	}
+
+	// Skip the arc when remaining=0 and we're counting down, else we get a red flash
+	// until we render the non-sensitive colour
+	if o.Disabled || (o.CountingDown && o.RemainingTime == 0) || (o.RemainingTime <= 0 && !o.CountingDown) {
+		return ops
+	}
+
+	lineColor := o.Palette.Accent
+	fillColor := Color{R: 0.6, G: 0.6, B: 0.6, A: 0.2}
+	if o.CountingDown {
+		lineColor = o.Palette.Error
+		fillColor = Color{R: o.Palette.Error.R, G: o.Palette.Error.G, B: o.Palette.Error.B, A: 0.3}
+	}
+
+	lineStrokeColor := lineColor
+	lineStrokeColor.A = 1.0
+
+	// Remaining times longer than one revolution are drawn as concentric rings like on a
+	// kitchen timer, with the outermost ring being the first revolution. Only the outermost
+	// ring is filled, and only the innermost ring has a handle.
+	rings := geometry.Rings(o.RemainingTime)
+	for ring, angle := range rings {
+		r := geometry.RingRadius(o.Width, o.Height, ring)
+
+		isFullCircle := angle >= 2*math.Pi
+
+		arcOrCircle := func(path *Path, r float64) {
+			if isFullCircle {
+				path.Circle(cx, cy, r)
+			} else {
+				path.ArcTo(cx, cy, r, 0, angle)
+			}
+		}
+
+		if ring == 0 {
+			if isFullCircle {
+				ops = append(ops, Op{Kind: OpFill, Path: circle(r), Color: fillColor})
+				strokeBorder(circle(r))
+			} else {
+				var sectorPath Path
+				sectorPath.MoveTo(cx, cy)
+				sectorPath.LineTo(cx, cy-r)
+				sectorPath.ArcTo(cx, cy, r, 0, angle)
+				sectorPath.LineTo(cx, cy)
+
+				ops = append(ops, Op{Kind: OpFill, Path: sectorPath, Color: fillColor})
+				strokeBorder(sectorPath)
+			}
+		}
+
+		var arcPath Path
+		arcPath.MoveTo(cx, cy-r)
+		arcOrCircle(&arcPath, r)
+
+		ops = append(ops, Op{Kind: OpStroke, Path: arcPath, Color: lineStrokeColor, LineWidth: 10, RoundCap: true})
+
+		if o.HighContrast {
+			var startCapPath Path
+			startCapPath.MoveTo(cx, cy-(r+5))
+			startCapPath.ArcTo(cx, cy-r, 5, 0, -math.Pi)
+			strokeBorder(startCapPath)
+
+			var outerArcPath Path
+			outerArcPath.MoveTo(cx, cy-(r+5))
+			arcOrCircle(&outerArcPath, r+5)
+			strokeBorder(outerArcPath)
+
+			var innerArcPath Path
+			innerArcPath.MoveTo(cx, cy-(r-5))
+			arcOrCircle(&innerArcPath, r-5)
+			strokeBorder(innerArcPath)
+		}
+
+		if ring == len(rings)-1 {
+			hx, hy := geometry.AngleToPosition(cx, cy, r, angle)
+
+			var handlePath Path
+			handlePath.Circle(hx, hy, 8)
+
+			ops = append(ops, Op{Kind: OpFill, Path: handlePath, Color: lineColor})
+			strokeBorder(handlePath)
+		}
+	}
+
+	return ops
+}
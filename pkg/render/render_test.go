@@ -0,0 +1,183 @@
+package render
+
+import (
+	"bytes"
+	"flag"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+const (
+	testSize = 260
+)
+
+var goldenTests = []struct {
+	name    string
+	options Options
+}{
+	{
+		name: "stopped",
+		options: Options{
+			RemainingTime: time.Minute * 5,
+			Palette:       LightPalette,
+		},
+	},
+	{
+		name: "counting-down-dark",
+		options: Options{
+			RemainingTime: time.Minute*42 + time.Second*30,
+			CountingDown:  true,
+			Palette:       DarkPalette,
+		},
+	},
+	{
+		name: "high-contrast-focused-labels",
+		options: Options{
+			RemainingTime: time.Minute * 20,
+			Focused:       true,
+			HighContrast:  true,
+			ShowLabels:    true,
+			Palette:       LightPalette,
+		},
+	},
+	{
+		name: "full-revolution",
+		options: Options{
+			RemainingTime: time.Hour,
+			Palette:       LightPalette,
+		},
+	},
+	{
+		name: "alarming",
+		options: Options{
+			CountingDown: true,
+			Disabled:     true,
+			Palette:      LightPalette,
+		},
+	},
+}
+
+func requireGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+		require.NoError(t, os.WriteFile(golden, actual, 0o644))
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual, "output differs from %v, run the tests with -update to regenerate it", golden)
+}
+
+func TestGolden(t *testing.T) {
+	for _, tt := range goldenTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				tt.options.Width, tt.options.Height = testSize, testSize
+				ops := Scene(tt.options)
+
+				var svg bytes.Buffer
+				require.NoError(t, WriteSVG(&svg, testSize, testSize, ops))
+				requireGolden(t, tt.name+".svg", svg.Bytes())
+
+				var img bytes.Buffer
+				require.NoError(t, WritePNG(&img, testSize, testSize, ops))
+				requireGolden(t, tt.name+".png", img.Bytes())
+			},
+		)
+	}
+}
+
+func TestRasterize(t *testing.T) {
+	img := Rasterize(testSize, testSize, Scene(Options{
+		Width:         testSize,
+		Height:        testSize,
+		RemainingTime: time.Minute * 15,
+		Palette:       LightPalette,
+	}))
+
+	// The center and the corners are transparent
+	require.Zero(t, img.RGBAAt(testSize/2, testSize/2).A)
+	require.Zero(t, img.RGBAAt(0, 0).A)
+
+	// The arc at 3 o'clock is opaque accent, the track at 9 o'clock is opaque track
+	accent := toNRGBA(LightPalette.Accent)
+	arc := img.RGBAAt(testSize-15, testSize/2)
+	require.Equal(t, [4]uint8{accent.R, accent.G, accent.B, 255}, [4]uint8{arc.R, arc.G, arc.B, arc.A})
+
+	track := toNRGBA(LightPalette.Track)
+	trackPixel := img.RGBAAt(15, testSize/2)
+	require.Equal(t, [4]uint8{track.R, track.G, track.B, 255}, [4]uint8{trackPixel.R, trackPixel.G, trackPixel.B, trackPixel.A})
+}
+
+func TestWritePNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WritePNG(&buf, 22, 22, Scene(Options{Width: 22, Height: 22, Palette: DarkPalette})))
+
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, 22, img.Bounds().Dx())
+	require.Equal(t, 22, img.Bounds().Dy())
+}
+
+func TestScene(t *testing.T) {
+	var sceneTests = []struct {
+		name    string
+		options Options
+		arc     bool
+		labels  int
+	}{
+		{
+			name:    "stopped with remaining time has an arc",
+			options: Options{RemainingTime: time.Minute},
+			arc:     true,
+		},
+		{
+			name:    "counting down to zero has no arc",
+			options: Options{CountingDown: true},
+			arc:     false,
+		},
+		{
+			name:    "disabled has no arc",
+			options: Options{RemainingTime: time.Minute, Disabled: true},
+			arc:     false,
+		},
+		{
+			name:    "labels are drawn for every major tick",
+			options: Options{ShowLabels: true},
+			labels:  12,
+		},
+	}
+	for _, tt := range sceneTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				tt.options.Width, tt.options.Height = testSize, testSize
+
+				arc, labels := false, 0
+				for _, op := range Scene(tt.options) {
+					switch {
+					case op.Kind == OpStroke && op.RoundCap && op.LineWidth == 10:
+						arc = true
+
+					case op.Kind == OpText:
+						labels++
+					}
+				}
+
+				require.Equal(t, tt.arc, arc)
+				require.Equal(t, tt.labels, labels)
+			},
+		)
+	}
+}
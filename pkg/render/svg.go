@@ -0,0 +1,117 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pojntfx/sessions/pkg/geometry"
+)
+
+// formatFloat rounds to three decimals so that the output is stable across platforms
+func formatFloat(v float64) string {
+	v = math.Round(v*1000) / 1000
+	if v == 0 {
+		// Avoids negative zero
+		v = 0
+	}
+
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatColor(c Color) (string, string) {
+	channel := func(v float64) int {
+		return int(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", channel(c.R), channel(c.G), channel(c.B)), formatFloat(c.A)
+}
+
+func svgPathData(path Path) string {
+	d := []string{}
+	for _, segment := range path.Segments {
+		switch segment.Kind {
+		case SegmentMoveTo:
+			d = append(d, "M", formatFloat(segment.X), formatFloat(segment.Y))
+
+		case SegmentLineTo:
+			d = append(d, "L", formatFloat(segment.X), formatFloat(segment.Y))
+
+		case SegmentArc:
+			endX, endY := geometry.AngleToPosition(segment.X, segment.Y, segment.R, segment.EndAngle)
+
+			largeArc, sweep := "0", "0"
+			if math.Abs(segment.EndAngle-segment.StartAngle) > math.Pi {
+				largeArc = "1"
+			}
+			if segment.EndAngle > segment.StartAngle {
+				sweep = "1"
+			}
+
+			d = append(d, "A", formatFloat(segment.R), formatFloat(segment.R), "0", largeArc, sweep, formatFloat(endX), formatFloat(endY))
+
+		case SegmentCircle:
+			// SVG arcs can't describe full circles, so we draw two half circles instead
+			topX, topY := segment.X, segment.Y-segment.R
+			bottomX, bottomY := segment.X, segment.Y+segment.R
+			r := formatFloat(segment.R)
+
+			d = append(
+				d,
+				"M", formatFloat(topX), formatFloat(topY),
+				"A", r, r, "0", "1", "1", formatFloat(bottomX), formatFloat(bottomY),
+				"A", r, r, "0", "1", "1", formatFloat(topX), formatFloat(topY),
+				"Z",
+			)
+		}
+	}
+
+	return strings.Join(d, " ")
+}
+
+// WriteSVG writes the scene as an SVG document with the given size to w
+func WriteSVG(w io.Writer, width, height float64, ops []Op) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(
+		bw,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		formatFloat(width), formatFloat(height), formatFloat(width), formatFloat(height),
+	)
+
+	for _, op := range ops {
+		color, opacity := formatColor(op.Color)
+
+		switch op.Kind {
+		case OpFill:
+			fmt.Fprintf(bw, `<path d="%s" fill="%s" fill-opacity="%s" fill-rule="nonzero"/>`+"\n", svgPathData(op.Path), color, opacity)
+
+		case OpStroke:
+			lineCap := "butt"
+			if op.RoundCap {
+				lineCap = "round"
+			}
+
+			fmt.Fprintf(
+				bw,
+				`<path d="%s" fill="none" stroke="%s" stroke-opacity="%s" stroke-width="%s" stroke-linecap="%s"/>`+"\n",
+				svgPathData(op.Path), color, opacity, formatFloat(op.LineWidth), lineCap,
+			)
+
+		case OpText:
+			fmt.Fprintf(
+				bw,
+				`<text x="%s" y="%s" font-family="sans-serif" font-size="%s" text-anchor="middle" dominant-baseline="central" fill="%s" fill-opacity="%s">%s</text>`+"\n",
+				formatFloat(op.X), formatFloat(op.Y), formatFloat(op.FontSize), color, opacity, html.EscapeString(op.Text),
+			)
+		}
+	}
+
+	fmt.Fprintln(bw, `</svg>`)
+
+	return bw.Flush()
+}
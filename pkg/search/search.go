@@ -0,0 +1,51 @@
+package search
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/duration"
+)
+
+const (
+	// Minimum number of characters that need to be typed before a keyword matches
+	minKeywordPrefixLength = 3
+)
+
+func matchesKeyword(term string, keywords []string) bool {
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(keyword)
+
+		if term == keyword || (len([]rune(term)) >= minKeywordPrefixLength && strings.HasPrefix(keyword, term)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match returns the timer durations that the terms of a search query match. Queries can start with
+// one of the keywords, or a prefix of one, followed by an optional duration, e.g. "timer 10", in which
+// case the presets are returned if there is no duration. Queries without a keyword need to have a duration
+// with a unit, e.g. "25 min", so that a bare number doesn't match. Durations aren't snapped or range-checked.
+func Match(terms, keywords []string, presets []time.Duration, units duration.Units) []time.Duration {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	keyword := matchesKeyword(strings.ToLower(terms[0]), keywords)
+	if keyword {
+		terms = terms[1:]
+
+		if len(terms) == 0 {
+			return presets
+		}
+	}
+
+	d, hasUnit, err := duration.ParseRaw(strings.Join(terms, " "), units)
+	if err != nil || d <= 0 || (!keyword && !hasUnit) {
+		return nil
+	}
+
+	return []time.Duration{d}
+}
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/duration"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKeywords = []string{"timer", "Session"}
+	testPresets  = []time.Duration{time.Minute * 5, time.Minute * 25}
+)
+
+var matchTests = []struct {
+	name     string
+	terms    []string
+	expected []time.Duration
+}{
+	{
+		name:     "no terms",
+		terms:    []string{},
+		expected: nil,
+	},
+	{
+		name:     "keyword returns presets",
+		terms:    []string{"timer"},
+		expected: testPresets,
+	},
+	{
+		name:     "keyword prefix returns presets",
+		terms:    []string{"ses"},
+		expected: testPresets,
+	},
+	{
+		name:     "keyword case is ignored",
+		terms:    []string{"SESSION"},
+		expected: testPresets,
+	},
+	{
+		name:     "short keyword prefix doesn't match",
+		terms:    []string{"ti"},
+		expected: nil,
+	},
+	{
+		name:     "keyword with number",
+		terms:    []string{"timer", "10"},
+		expected: []time.Duration{time.Minute * 10},
+	},
+	{
+		name:     "keyword with duration",
+		terms:    []string{"timer", "1h", "30"},
+		expected: []time.Duration{time.Minute * 90},
+	},
+	{
+		name:     "keyword with clock time",
+		terms:    []string{"timer", "17:30"},
+		expected: []time.Duration{time.Minute*17 + time.Second*30},
+	},
+	{
+		name:     "duration with unit",
+		terms:    []string{"25", "min"},
+		expected: []time.Duration{time.Minute * 25},
+	},
+	{
+		name:     "number without unit or keyword doesn't match",
+		terms:    []string{"25"},
+		expected: nil,
+	},
+	{
+		name:     "other query doesn't match",
+		terms:    []string{"firefox"},
+		expected: nil,
+	},
+	{
+		name:     "keyword with other query doesn't match",
+		terms:    []string{"timer", "app"},
+		expected: nil,
+	},
+	{
+		name:     "zero duration doesn't match",
+		terms:    []string{"timer", "0"},
+		expected: nil,
+	},
+}
+
+func TestMatch(t *testing.T) {
+	for _, tt := range matchTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				require.Equal(t, tt.expected, Match(tt.terms, testKeywords, testPresets, duration.Units{}))
+			},
+		)
+	}
+}
@@ -0,0 +1,250 @@
+package searchprovider
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/pojntfx/sessions/pkg/duration"
+	"github.com/pojntfx/sessions/pkg/search"
+)
+
+const (
+	providerInterface = "org.gnome.Shell.SearchProvider2"
+)
+
+var (
+	ErrAlreadyOpen  = errors.New("search provider is already open")
+	ErrNameNotOwned = errors.New("could not become primary owner of search provider name")
+	ErrInvalidID    = errors.New("invalid search result ID")
+)
+
+type Hooks struct {
+	// OnActivateResult is called when a result is activated with the duration of its timer
+	OnActivateResult func(ctx context.Context, duration time.Duration) error
+	// OnLaunchSearch is called when the search is opened in the app itself
+	OnLaunchSearch func(ctx context.Context, terms []string) error
+}
+
+// Describe returns the name and description of the result for a timer with a duration
+type Describe func(duration time.Duration) (name, description string)
+
+// Provider offers timers as results in the GNOME Shell search. Result IDs are the durations
+// of the timers in seconds.
+type Provider struct {
+	ctx   context.Context
+	conn  *dbus.Conn
+	log   *slog.Logger
+	hooks *Hooks
+
+	name     string
+	path     dbus.ObjectPath
+	icon     string
+	keywords []string
+	presets  []time.Duration
+	units    duration.Units
+	describe Describe
+
+	lock sync.Mutex
+	open bool
+}
+
+// NewProvider creates a new search provider on a connection. The provider is only exported and
+// owns its name once it is opened.
+func NewProvider(
+	ctx context.Context,
+	conn *dbus.Conn,
+	log *slog.Logger,
+	name string,
+	path dbus.ObjectPath,
+	icon string,
+	keywords []string,
+	presets []time.Duration,
+	units duration.Units,
+	describe Describe,
+	hooks *Hooks,
+) *Provider {
+	return &Provider{
+		ctx:   ctx,
+		conn:  conn,
+		log:   log,
+		hooks: hooks,
+
+		name:     name,
+		path:     path,
+		icon:     icon,
+		keywords: keywords,
+		presets:  presets,
+		units:    units,
+		describe: describe,
+	}
+}
+
+// Open exports the provider and requests its name
+func (p *Provider) Open() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.open {
+		return ErrAlreadyOpen
+	}
+
+	if err := p.conn.Export(p, p.path, providerInterface); err != nil {
+		return err
+	}
+
+	if err := p.conn.Export(introspect.NewIntrospectable(&introspect.Node{
+		Name: string(p.path),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			{
+				Name:    providerInterface,
+				Methods: introspect.Methods(p),
+			},
+		},
+	}), p.path, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return err
+	}
+
+	reply, err := p.conn.RequestName(p.name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return err
+	}
+
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return ErrNameNotOwned
+	}
+
+	p.open = true
+
+	return nil
+}
+
+// Close releases the provider's name and unexports it
+func (p *Provider) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.open {
+		return nil
+	}
+
+	if _, err := p.conn.ReleaseName(p.name); err != nil {
+		return err
+	}
+
+	for _, iface := range []string{providerInterface, "org.freedesktop.DBus.Introspectable"} {
+		if err := p.conn.Export(nil, p.path, iface); err != nil {
+			return err
+		}
+	}
+
+	p.open = false
+
+	return nil
+}
+
+func (p *Provider) results(terms []string) []string {
+	ids := []string{}
+	for _, d := range search.Match(terms, p.keywords, p.presets, p.units) {
+		// Durations that can't be set with the dial are snapped to the closest one that can
+		d, err := duration.Snap(d)
+		if err != nil {
+			continue
+		}
+
+		ids = append(ids, strconv.FormatInt(int64(d.Seconds()), 10))
+	}
+
+	return ids
+}
+
+func parseID(id string) (time.Duration, error) {
+	seconds, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, errors.Join(ErrInvalidID, err)
+	}
+
+	// IDs are always snapped already
+	d := time.Duration(seconds) * time.Second
+	if snapped, err := duration.Snap(d); err != nil || snapped != d {
+		return 0, ErrInvalidID
+	}
+
+	return d, nil
+}
+
+// GetInitialResultSet is called by the shell when a search starts
+func (p *Provider) GetInitialResultSet(terms []string) ([]string, *dbus.Error) {
+	return p.results(terms), nil
+}
+
+// GetSubsearchResultSet is called by the shell when a search is refined. Results depend on
+// the whole query, so we search again instead of filtering the previous results.
+func (p *Provider) GetSubsearchResultSet(previousResults, terms []string) ([]string, *dbus.Error) {
+	return p.results(terms), nil
+}
+
+// GetResultMetas is called by the shell to display results
+func (p *Provider) GetResultMetas(ids []string) ([]map[string]dbus.Variant, *dbus.Error) {
+	metas := []map[string]dbus.Variant{}
+	for _, id := range ids {
+		d, err := parseID(id)
+		if err != nil {
+			p.log.Debug("Skipping unknown search result", "id", id, "err", err)
+
+			continue
+		}
+
+		name, description := p.describe(d)
+
+		metas = append(metas, map[string]dbus.Variant{
+			"id":          dbus.MakeVariant(id),
+			"name":        dbus.MakeVariant(name),
+			"description": dbus.MakeVariant(description),
+			"gicon":       dbus.MakeVariant(p.icon),
+		})
+	}
+
+	return metas, nil
+}
+
+// ActivateResult is called by the shell when a result is clicked
+func (p *Provider) ActivateResult(id string, terms []string, timestamp uint32) *dbus.Error {
+	d, err := parseID(id)
+	if err != nil {
+		return dbus.MakeFailedError(err)
+	}
+
+	if p.hooks.OnActivateResult == nil {
+		return nil
+	}
+
+	if err := p.hooks.OnActivateResult(p.ctx, d); err != nil {
+		p.log.Warn("Could not activate search result", "id", id, "err", err)
+
+		return dbus.MakeFailedError(err)
+	}
+
+	return nil
+}
+
+// LaunchSearch is called by the shell when the provider's icon is clicked
+func (p *Provider) LaunchSearch(terms []string, timestamp uint32) *dbus.Error {
+	if p.hooks.OnLaunchSearch == nil {
+		return nil
+	}
+
+	if err := p.hooks.OnLaunchSearch(p.ctx, terms); err != nil {
+		p.log.Warn("Could not launch search", "err", err)
+
+		return dbus.MakeFailedError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package searchprovider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/neilotoole/slogt"
+	"github.com/pojntfx/sessions/internal/dbustest"
+	"github.com/pojntfx/sessions/pkg/duration"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testName = "com.example.Test.SearchProvider"
+	testPath = dbus.ObjectPath("/com/example/Test/SearchProvider")
+	testIcon = "com.example.Test"
+)
+
+func describe(duration time.Duration) (string, string) {
+	return fmt.Sprintf("%v timer", duration), "Start a timer"
+}
+
+func openProvider(t *testing.T, conn *dbus.Conn, hooks *Hooks) *Provider {
+	t.Helper()
+
+	p := NewProvider(
+		t.Context(),
+		conn,
+		slogt.New(t),
+		testName,
+		testPath,
+		testIcon,
+		[]string{"timer"},
+		[]time.Duration{time.Minute * 5, time.Minute * 25},
+		duration.Units{},
+		describe,
+		hooks,
+	)
+	require.NoError(t, p.Open())
+
+	t.Cleanup(func() {
+		require.NoError(t, p.Close())
+	})
+
+	return p
+}
+
+var resultSetTests = []struct {
+	name     string
+	terms    []string
+	expected []string
+}{
+	{
+		name:     "keyword returns presets",
+		terms:    []string{"timer"},
+		expected: []string{"300", "1500"},
+	},
+	{
+		name:     "duration with unit",
+		terms:    []string{"25", "min"},
+		expected: []string{"1500"},
+	},
+	{
+		name:     "durations are snapped to the adjustment interval",
+		terms:    []string{"timer", "10m", "20s"},
+		expected: []string{"630"},
+	},
+	{
+		name:     "durations above the maximum don't match",
+		terms:    []string{"timer", "2h"},
+		expected: []string{},
+	},
+	{
+		name:     "durations below the minimum don't match",
+		terms:    []string{"timer", "10s"},
+		expected: []string{},
+	},
+	{
+		name:     "other queries don't match",
+		terms:    []string{"firefox"},
+		expected: []string{},
+	},
+}
+
+func TestResultSet(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	openProvider(t, dbustest.Connect(t, address), &Hooks{})
+
+	provider := dbustest.Connect(t, address).Object(testName, testPath)
+
+	for _, tt := range resultSetTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				var initial []string
+				require.NoError(t, provider.Call(providerInterface+".GetInitialResultSet", 0, tt.terms).Store(&initial))
+				require.Equal(t, tt.expected, initial)
+
+				var subsearch []string
+				require.NoError(t, provider.Call(providerInterface+".GetSubsearchResultSet", 0, []string{"1500"}, tt.terms).Store(&subsearch))
+				require.Equal(t, tt.expected, subsearch)
+			},
+		)
+	}
+}
+
+func TestResultMetas(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	openProvider(t, dbustest.Connect(t, address), &Hooks{})
+
+	var metas []map[string]dbus.Variant
+	require.NoError(t, dbustest.Connect(t, address).Object(testName, testPath).Call(providerInterface+".GetResultMetas", 0, []string{"1500", "invalid", "1501"}).Store(&metas))
+
+	require.Len(t, metas, 1)
+	require.Equal(t, "1500", metas[0]["id"].Value())
+	require.Equal(t, "25m0s timer", metas[0]["name"].Value())
+	require.Equal(t, "Start a timer", metas[0]["description"].Value())
+	require.Equal(t, testIcon, metas[0]["gicon"].Value())
+}
+
+func TestActivation(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	var (
+		activated = make(chan time.Duration, 1)
+		launched  = make(chan []string, 1)
+	)
+	openProvider(t, dbustest.Connect(t, address), &Hooks{
+		OnActivateResult: func(ctx context.Context, duration time.Duration) error {
+			activated <- duration
+
+			return nil
+		},
+		OnLaunchSearch: func(ctx context.Context, terms []string) error {
+			launched <- terms
+
+			return nil
+		},
+	})
+
+	provider := dbustest.Connect(t, address).Object(testName, testPath)
+
+	require.NoError(t, provider.Call(providerInterface+".ActivateResult", 0, "600", []string{"timer", "10"}, uint32(0)).Err)
+	require.Equal(t, time.Minute*10, <-activated)
+
+	require.Error(t, provider.Call(providerInterface+".ActivateResult", 0, "invalid", []string{}, uint32(0)).Err)
+	require.Empty(t, activated)
+
+	require.NoError(t, provider.Call(providerInterface+".LaunchSearch", 0, []string{"timer"}, uint32(0)).Err)
+	require.Equal(t, []string{"timer"}, <-launched)
+}
+
+func TestOpen(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	p := openProvider(t, dbustest.Connect(t, address), &Hooks{})
+	require.ErrorIs(t, p.Open(), ErrAlreadyOpen)
+
+	// Only one provider can own the name
+	other := NewProvider(t.Context(), dbustest.Connect(t, address), slogt.New(t), testName, testPath, testIcon, nil, nil, duration.Units{}, describe, &Hooks{})
+	require.ErrorIs(t, other.Open(), ErrNameNotOwned)
+}
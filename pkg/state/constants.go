@@ -11,6 +11,7 @@ const (
 	stateDragging     state = "dragging"
 	stateCountingDown state = "countingDown"
 	stateAlarming     state = "alarming"
+	statePaused       state = "paused"
 )
 
 type Trigger string
@@ -18,15 +19,32 @@ type Trigger string
 const (
 	TriggerPlusTimer  Trigger = "plusTimer"
 	TriggerMinusTimer Trigger = "minusTimer"
+	// Checking whether this trigger can be used depends on the extension,
+	// use `CanExtendTimer` instead
+	triggerExtendTimer Trigger = "extendTimer"
+	// Checking whether this trigger can be used depends on the adjustment,
+	// use `CanAdjustTimer` instead
+	triggerAdjustTimer Trigger = "adjustTimer"
+	// Checking whether this trigger can be used depends on what the new `initialRemainingTime`
+	// would be, use `CanSetInitialRemainingTime` instead
+	triggerSetInitialRemainingTime Trigger = "setInitialRemainingTime"
 
 	TriggerStartDragging Trigger = "startDragging"
 	// Checking whether this trigger can be used depends on what the new `remainingTime`
 	// would be, use `CanStopDragging` instead
 	triggerStopDragging Trigger = "stopDragging"
+	// Returns to the state we started dragging from, without changing the remaining time
+	TriggerCancelDragging Trigger = "cancelDragging"
 
 	TriggerStartTimer Trigger = "startTimer"
 	TriggerStopTimer  Trigger = "stopTimer"
 
+	TriggerPauseTimer  Trigger = "pauseTimer"
+	TriggerResumeTimer Trigger = "resumeTimer"
+	// Checking whether this trigger can be used depends on what the new `currentRemainingTime`
+	// would be, use `CanResumeTimerWithRemainingTime` instead
+	triggerResumeTimerWithRemainingTime Trigger = "resumeTimerWithRemainingTime"
+
 	// This one is only called from within the state machine
 	triggerTimerFinished Trigger = "timerFinished"
 	TriggerStopAlarming  Trigger = "stopAlarming"
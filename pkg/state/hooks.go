@@ -0,0 +1,63 @@
+package state
+
+import (
+	"context"
+	"time"
+)
+
+// CombineHooks returns hooks that call the respective hook of each of the given hooks in
+// order. Hooks that aren't set are skipped, and the first error stops the remaining
+// hooks from being called.
+func CombineHooks(hooks ...*Hooks) *Hooks {
+	return &Hooks{
+		OnStartTimer: combine(hooks, func(h *Hooks) func(context.Context) error { return h.OnStartTimer }),
+		OnStopTimer:  combine(hooks, func(h *Hooks) func(context.Context) error { return h.OnStopTimer }),
+
+		OnPauseTimer:  combineWithArg(hooks, func(h *Hooks) func(context.Context, time.Duration) error { return h.OnPauseTimer }),
+		OnResumeTimer: combineWithArg(hooks, func(h *Hooks) func(context.Context, time.Duration) error { return h.OnResumeTimer }),
+
+		OnInitialRemainingTimeChange: combineWithArg(hooks, func(h *Hooks) func(context.Context, time.Duration) error {
+			return h.OnInitialRemainingTimeChange
+		}),
+		OnCurrentRemainingTimeTick: combineWithArg(hooks, func(h *Hooks) func(context.Context, time.Duration) error {
+			return h.OnCurrentRemainingTimeTick
+		}),
+
+		OnLabelChange: combineWithArg(hooks, func(h *Hooks) func(context.Context, string) error { return h.OnLabelChange }),
+
+		OnStartAlarm: combine(hooks, func(h *Hooks) func(context.Context) error { return h.OnStartAlarm }),
+		OnStopAlarm:  combine(hooks, func(h *Hooks) func(context.Context) error { return h.OnStopAlarm }),
+
+		OnPermittedTriggersChange: combineWithArg(hooks, func(h *Hooks) func(context.Context, []Trigger) error {
+			return h.OnPermittedTriggersChange
+		}),
+	}
+}
+
+func combine(hooks []*Hooks, hook func(h *Hooks) func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		for _, h := range hooks {
+			if fn := hook(h); fn != nil {
+				if err := fn(ctx); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+func combineWithArg[T any](hooks []*Hooks, hook func(h *Hooks) func(context.Context, T) error) func(context.Context, T) error {
+	return func(ctx context.Context, arg T) error {
+		for _, h := range hooks {
+			if fn := hook(h); fn != nil {
+				if err := fn(ctx, arg); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+}
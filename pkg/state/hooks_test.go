@@ -0,0 +1,79 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombineHooks(t *testing.T) {
+	var calls []string
+
+	errFirst := errors.New("first hook failed")
+
+	first := &Hooks{
+		OnStartTimer: func(ctx context.Context) error {
+			calls = append(calls, "first.startTimer")
+
+			return nil
+		},
+		OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			calls = append(calls, fmt.Sprintf("first.tick(%v)", currentRemainingTime))
+
+			return nil
+		},
+		OnStopAlarm: func(ctx context.Context) error {
+			calls = append(calls, "first.stopAlarm")
+
+			return errFirst
+		},
+	}
+	second := &Hooks{
+		OnStartTimer: func(ctx context.Context) error {
+			calls = append(calls, "second.startTimer")
+
+			return nil
+		},
+		OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			calls = append(calls, fmt.Sprintf("second.tick(%v)", currentRemainingTime))
+
+			return nil
+		},
+		OnLabelChange: func(ctx context.Context, label string) error {
+			calls = append(calls, fmt.Sprintf("second.label(%v)", label))
+
+			return nil
+		},
+		OnStopAlarm: func(ctx context.Context) error {
+			calls = append(calls, "second.stopAlarm")
+
+			return nil
+		},
+	}
+
+	combined := CombineHooks(first, second)
+
+	require.NoError(t, combined.OnStartTimer(t.Context()))
+	require.NoError(t, combined.OnCurrentRemainingTimeTick(t.Context(), time.Minute))
+	require.NoError(t, combined.OnLabelChange(t.Context(), "Write report"))
+
+	// Hooks that none of the combined hooks set are no-ops
+	require.NoError(t, combined.OnStopTimer(t.Context()))
+	require.NoError(t, combined.OnPermittedTriggersChange(t.Context(), []Trigger{TriggerStartTimer}))
+
+	// The first error stops the remaining hooks from being called
+	require.ErrorIs(t, combined.OnStopAlarm(t.Context()), errFirst)
+
+	require.Equal(t, []string{
+		"first.startTimer",
+		"second.startTimer",
+		"first.tick(1m0s)",
+		"second.tick(1m0s)",
+		"second.label(Write report)",
+		"first.stopAlarm",
+	}, calls)
+}
@@ -14,9 +14,16 @@ type Hooks struct {
 	OnStartTimer func(ctx context.Context) error
 	OnStopTimer  func(ctx context.Context) error
 
+	// OnPauseTimer and OnResumeTimer are only called when pausing and resuming, so they are optional
+	OnPauseTimer  func(ctx context.Context, currentRemainingTime time.Duration) error
+	OnResumeTimer func(ctx context.Context, currentRemainingTime time.Duration) error
+
 	OnInitialRemainingTimeChange func(ctx context.Context, initialRemainingTime time.Duration) error
 	OnCurrentRemainingTimeTick   func(ctx context.Context, currentRemainingTime time.Duration) error
 
+	// OnLabelChange is only called when the label actually changes, so it is optional
+	OnLabelChange func(ctx context.Context, label string) error
+
 	OnStartAlarm func(ctx context.Context) error
 	OnStopAlarm  func(ctx context.Context) error
 
@@ -26,10 +33,14 @@ type Hooks struct {
 type StateMachine struct {
 	initialRemainingTime,
 	currentRemainingTime time.Duration
+	label string
 	ctx   context.Context
 	log   *slog.Logger
 	hooks *Hooks
 
+	// State that cancelling dragging returns to
+	stateBeforeDragging state
+
 	machine         *stateless.StateMachine
 	ticker          *time.Ticker
 	tickerCtx       context.Context
@@ -79,6 +90,42 @@ func NewStateMachine(
 		OnExitWith(TriggerMinusTimer, s.stopTimerWithoutHooks).
 		OnEntryFrom(TriggerMinusTimer, s.decreaseInitialRemainingTimeFromCurrentRemainingTime)
 
+	// From counting down state, we can also extend the timer by more than one interval at once,
+	// up to the maximum remaining time. This restarts the timer only once, as opposed to
+	// incrementing it repeatedly.
+	s.machine.SetTriggerParameters(triggerExtendTimer, reflect.TypeFor[time.Duration]())
+	s.machine.
+		Configure(stateCountingDown).
+		PermitReentry(triggerExtendTimer, s.canExtendTimer).
+		OnExitWith(triggerExtendTimer, s.stopTimerWithoutHooks).
+		OnEntryFrom(triggerExtendTimer, s.extendInitialRemainingTimeFromCurrentRemainingTime)
+
+	// From stopped, counting down and paused state, we can also adjust the remaining time by a number
+	// of intervals at once, which is clamped to the minimum and maximum remaining time. This restarts a
+	// running timer only once, and keeps a paused timer paused.
+	s.machine.SetTriggerParameters(triggerAdjustTimer, reflect.TypeFor[time.Duration]())
+	s.machine.
+		Configure(stateStopped).
+		PermitReentry(triggerAdjustTimer, s.validAdjustment).
+		OnEntryFrom(triggerAdjustTimer, s.adjustInitialRemainingTime)
+	s.machine.
+		Configure(stateCountingDown).
+		PermitReentry(triggerAdjustTimer, s.validAdjustment).
+		OnExitWith(triggerAdjustTimer, s.stopTimerWithoutHooks).
+		OnEntryFrom(triggerAdjustTimer, s.adjustInitialRemainingTimeFromCurrentRemainingTime)
+	s.machine.
+		Configure(statePaused).
+		PermitReentry(triggerAdjustTimer, s.validAdjustment).
+		OnEntryFrom(triggerAdjustTimer, s.adjustInitialRemainingTimeFromCurrentRemainingTime)
+
+	// From stopped state, we can also set the initial remaining time directly, as long as it
+	// would be valid after we stop dragging
+	s.machine.SetTriggerParameters(triggerSetInitialRemainingTime, reflect.TypeFor[time.Duration]())
+	s.machine.
+		Configure(stateStopped).
+		PermitReentry(triggerSetInitialRemainingTime, s.validInitialRemainingTime).
+		OnEntryFrom(triggerSetInitialRemainingTime, s.setInitialRemainingTime)
+
 	// From stopped state, we can start dragging
 	s.machine.Configure(stateStopped).Permit(TriggerStartDragging, stateDragging)
 
@@ -99,10 +146,38 @@ func NewStateMachine(
 		Permit(TriggerStartDragging, stateDragging).
 		OnExitWith(TriggerStartDragging, s.stopTimerWithoutHooks)
 
+	// When we cancel dragging, we return to the state we started dragging from. A timer that was
+	// counting down continues from where it was, so no hooks are called in any of these states.
+	s.machine.Configure(stateDragging).OnEntryFrom(TriggerStartDragging, s.rememberStateBeforeDragging)
+	s.machine.Configure(stateDragging).PermitDynamic(TriggerCancelDragging, s.getStateBeforeDragging)
+
 	// From counting down state, we can stop/reset and then restart the timer
 	s.machine.Configure(stateCountingDown).Permit(TriggerStopTimer, stateStopped)
 	s.machine.Configure(stateStopped).Permit(TriggerStartTimer, stateCountingDown)
 
+	// From counting down state, we can pause the timer. From paused state, we can resume
+	// the timer from where we left off, or stop/reset it
+	s.machine.
+		Configure(stateCountingDown).
+		Permit(TriggerPauseTimer, statePaused).
+		OnExitWith(TriggerPauseTimer, s.stopTimerWithoutHooks)
+	s.machine.
+		Configure(statePaused).
+		OnEntryFrom(TriggerPauseTimer, s.pauseTimer).
+		Permit(TriggerResumeTimer, stateCountingDown).
+		Permit(TriggerStopTimer, stateStopped)
+
+	// From paused state, we can also resume with a different remaining time, e.g. to account for
+	// time that passed while the timer was paused, without changing the initial remaining time
+	s.machine.SetTriggerParameters(triggerResumeTimerWithRemainingTime, reflect.TypeFor[time.Duration]())
+	s.machine.
+		Configure(statePaused).
+		Permit(triggerResumeTimerWithRemainingTime, stateCountingDown, s.validCurrentRemainingTime)
+	s.machine.Configure(stateCountingDown).OnEntryFrom(triggerResumeTimerWithRemainingTime, s.setCurrentRemainingTime)
+
+	// From paused state, we can also start dragging, which starts the timer over once we stop dragging
+	s.machine.Configure(statePaused).Permit(TriggerStartDragging, stateDragging)
+
 	// From counting down state, we can go into alarming state when the timer has finished
 	s.machine.Configure(stateCountingDown).Permit(triggerTimerFinished, stateAlarming)
 
@@ -246,6 +321,41 @@ func (s *StateMachine) setInitialRemainingTime(ctx context.Context, args ...any)
 	return nil
 }
 
+func (s *StateMachine) validCurrentRemainingTime(ctx context.Context, args ...any) bool {
+	if len(args) <= 0 {
+		// Like for setting the initial remaining time, we can't make a decision
+		// without knowing the argument value when listing the permitted triggers
+
+		return false
+	}
+
+	// The timer only finishes if it counts down to exactly zero
+	newCurrentRemainingTime := args[0].(time.Duration)
+	if newCurrentRemainingTime < tickerInterval ||
+		newCurrentRemainingTime > s.initialRemainingTime ||
+		newCurrentRemainingTime%tickerInterval != 0 {
+		return false
+	}
+
+	return true
+}
+
+func (s *StateMachine) setCurrentRemainingTime(ctx context.Context, args ...any) error {
+	s.currentRemainingTime = args[0].(time.Duration)
+
+	return nil
+}
+
+func (s *StateMachine) rememberStateBeforeDragging(ctx context.Context, args ...any) error {
+	s.stateBeforeDragging = stateless.GetTransition(ctx).Source.(state)
+
+	return nil
+}
+
+func (s *StateMachine) getStateBeforeDragging(ctx context.Context, args ...any) (stateless.State, error) {
+	return s.stateBeforeDragging, nil
+}
+
 func getInitialRemainingTimeFromCurrentRemainingTime(currentRemainingTime time.Duration, intervalsToAdd int) time.Duration {
 	intervals := time.Duration(
 		math.Round(
@@ -293,6 +403,89 @@ func (s *StateMachine) decreaseInitialRemainingTimeFromCurrentRemainingTime(ctx
 	return nil
 }
 
+func (s *StateMachine) canExtendTimer(ctx context.Context, args ...any) bool {
+	if len(args) <= 0 {
+		// Like for setting the initial remaining time, we can't make a decision
+		// without knowing the argument value when listing the permitted triggers
+
+		return false
+	}
+
+	return args[0].(time.Duration) >= RemainingTimerAdjustmentInterval && s.mustBeBelowMaxCurrentRemainingTime(ctx)
+}
+
+func (s *StateMachine) extendInitialRemainingTimeFromCurrentRemainingTime(ctx context.Context, args ...any) error {
+	extension := args[0].(time.Duration)
+
+	s.initialRemainingTime = min(
+		getInitialRemainingTimeFromCurrentRemainingTime(s.currentRemainingTime, int(extension/RemainingTimerAdjustmentInterval)),
+		MaxInitialRemainingTime,
+	)
+
+	s.log.InfoContext(
+		s.ctx, "Calling onInitialRemainingTimeChange hook",
+		"initialRemainingTime", s.initialRemainingTime,
+	)
+	if err := s.hooks.OnInitialRemainingTimeChange(ctx, s.initialRemainingTime); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *StateMachine) validAdjustment(ctx context.Context, args ...any) bool {
+	if len(args) <= 0 {
+		// Like for setting the initial remaining time, we can't make a decision
+		// without knowing the argument value when listing the permitted triggers
+
+		return false
+	}
+
+	adjustment := args[0].(time.Duration)
+
+	return adjustment != 0 && adjustment%RemainingTimerAdjustmentInterval == 0
+}
+
+func getAdjustedRemainingTime(remainingTime, adjustment time.Duration) time.Duration {
+	return min(
+		max(
+			getInitialRemainingTimeFromCurrentRemainingTime(remainingTime, int(adjustment/RemainingTimerAdjustmentInterval)),
+			MinInitialRemainingTime,
+		),
+		MaxInitialRemainingTime,
+	)
+}
+
+func (s *StateMachine) adjustInitialRemainingTime(ctx context.Context, args ...any) error {
+	s.initialRemainingTime = getAdjustedRemainingTime(s.initialRemainingTime, args[0].(time.Duration))
+
+	s.log.InfoContext(
+		s.ctx, "Calling onInitialRemainingTimeChange hook",
+		"initialRemainingTime", s.initialRemainingTime,
+	)
+	if err := s.hooks.OnInitialRemainingTimeChange(ctx, s.initialRemainingTime); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *StateMachine) adjustInitialRemainingTimeFromCurrentRemainingTime(ctx context.Context, args ...any) error {
+	s.initialRemainingTime = getAdjustedRemainingTime(s.currentRemainingTime, args[0].(time.Duration))
+	// A paused timer continues from the adjusted time once it is resumed
+	s.currentRemainingTime = s.initialRemainingTime
+
+	s.log.InfoContext(
+		s.ctx, "Calling onInitialRemainingTimeChange hook",
+		"initialRemainingTime", s.initialRemainingTime,
+	)
+	if err := s.hooks.OnInitialRemainingTimeChange(ctx, s.initialRemainingTime); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *StateMachine) mustBeAboveMinCurrentRemainingTime(ctx context.Context, args ...any) bool {
 	newInitialRemainingTime := getInitialRemainingTimeFromCurrentRemainingTime(s.currentRemainingTime, -1)
 	if newInitialRemainingTime < MinInitialRemainingTime {
@@ -310,6 +503,41 @@ func (s *StateMachine) MinusTimer(ctx context.Context) error {
 	return s.machine.FireCtx(ctx, TriggerMinusTimer)
 }
 
+// ExtendTimer adds whole adjustment intervals to the remaining time of a running timer. It stops
+// early instead of failing if this would go past the maximum remaining time.
+func (s *StateMachine) ExtendTimer(ctx context.Context, extension time.Duration) error {
+	return s.machine.FireCtx(ctx, triggerExtendTimer, extension)
+}
+
+// CanExtendTimer exists because onPermittedTriggersChange can't correctly report whether
+// you can extend the timer without knowing what the extension would be
+func (s *StateMachine) CanExtendTimer(ctx context.Context, extension time.Duration) (bool, error) {
+	return s.machine.CanFireCtx(ctx, triggerExtendTimer, extension)
+}
+
+// AdjustTimer adds or removes whole adjustment intervals from the remaining time, clamped to the
+// minimum and maximum remaining time. A running timer starts over from the adjusted time, and a
+// paused one continues from it once it is resumed.
+func (s *StateMachine) AdjustTimer(ctx context.Context, adjustment time.Duration) error {
+	return s.machine.FireCtx(ctx, triggerAdjustTimer, adjustment)
+}
+
+// CanAdjustTimer exists because onPermittedTriggersChange can't correctly report whether
+// you can adjust the timer without knowing what the adjustment would be
+func (s *StateMachine) CanAdjustTimer(ctx context.Context, adjustment time.Duration) (bool, error) {
+	return s.machine.CanFireCtx(ctx, triggerAdjustTimer, adjustment)
+}
+
+func (s *StateMachine) SetInitialRemainingTime(ctx context.Context, initialRemainingTime time.Duration) error {
+	return s.machine.FireCtx(ctx, triggerSetInitialRemainingTime, initialRemainingTime)
+}
+
+// CanSetInitialRemainingTime exists because onPermittedTriggersChange can't correctly report whether
+// you can set the initial remaining time without knowing what the new initialRemainingTime would be
+func (s *StateMachine) CanSetInitialRemainingTime(ctx context.Context, initialRemainingTime time.Duration) (bool, error) {
+	return s.machine.CanFireCtx(ctx, triggerSetInitialRemainingTime, initialRemainingTime)
+}
+
 func (s *StateMachine) StartDragging(ctx context.Context) error {
 	return s.machine.FireCtx(ctx, TriggerStartDragging)
 }
@@ -318,12 +546,69 @@ func (s *StateMachine) StopDragging(ctx context.Context, remainingTime time.Dura
 	return s.machine.FireCtx(ctx, triggerStopDragging, remainingTime)
 }
 
+// CancelDragging returns to the state we started dragging from, without changing the initial
+// or current remaining time
+func (s *StateMachine) CancelDragging(ctx context.Context) error {
+	return s.machine.FireCtx(ctx, TriggerCancelDragging)
+}
+
 // CanStopDragging exists because onPermittedTriggersChange can't correctly report whether
 // you can stop dragging without knowing what the new remainingTime would be
 func (s *StateMachine) CanStopDragging(ctx context.Context, remainingTime time.Duration) (bool, error) {
 	return s.machine.CanFireCtx(ctx, triggerStopDragging, remainingTime)
 }
 
+// InitialRemainingTime returns the duration of the current or next session
+func (s *StateMachine) InitialRemainingTime() time.Duration {
+	return s.initialRemainingTime
+}
+
+// Label returns the label of the current or next session, e.g. the task it is for
+func (s *StateMachine) Label() string {
+	return s.label
+}
+
+// SetLabel sets the label of the current or next session. It can be changed in every state,
+// since the session keeps its label until it is changed again.
+func (s *StateMachine) SetLabel(ctx context.Context, label string) error {
+	if label == s.label {
+		return nil
+	}
+
+	s.label = label
+
+	if s.hooks.OnLabelChange == nil {
+		return nil
+	}
+
+	s.log.InfoContext(
+		ctx, "Calling onLabelChange hook",
+		"label", s.label,
+	)
+	if err := s.hooks.OnLabelChange(ctx, s.label); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// StartTimerWithLabel sets the label of the session and starts the timer. The label is only set
+// if the timer can be started.
+func (s *StateMachine) StartTimerWithLabel(ctx context.Context, label string) error {
+	ok, err := s.machine.CanFireCtx(ctx, TriggerStartTimer)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		if err := s.SetLabel(ctx, label); err != nil {
+			return err
+		}
+	}
+
+	return s.StartTimer(ctx)
+}
+
 func (s *StateMachine) StopTimer(ctx context.Context) error {
 	return s.machine.FireCtx(ctx, TriggerStopTimer)
 }
@@ -332,6 +617,26 @@ func (s *StateMachine) StartTimer(ctx context.Context) error {
 	return s.machine.FireCtx(ctx, TriggerStartTimer)
 }
 
+func (s *StateMachine) PauseTimer(ctx context.Context) error {
+	return s.machine.FireCtx(ctx, TriggerPauseTimer)
+}
+
+func (s *StateMachine) ResumeTimer(ctx context.Context) error {
+	return s.machine.FireCtx(ctx, TriggerResumeTimer)
+}
+
+// ResumeTimerWithRemainingTime resumes a paused timer with a different remaining time, which
+// needs to be a whole number of seconds and can't exceed the initial remaining time
+func (s *StateMachine) ResumeTimerWithRemainingTime(ctx context.Context, currentRemainingTime time.Duration) error {
+	return s.machine.FireCtx(ctx, triggerResumeTimerWithRemainingTime, currentRemainingTime)
+}
+
+// CanResumeTimerWithRemainingTime exists because onPermittedTriggersChange can't correctly report whether
+// you can resume with a remaining time without knowing what the new remaining time would be
+func (s *StateMachine) CanResumeTimerWithRemainingTime(ctx context.Context, currentRemainingTime time.Duration) (bool, error) {
+	return s.machine.CanFireCtx(ctx, triggerResumeTimerWithRemainingTime, currentRemainingTime)
+}
+
 func (s *StateMachine) timerFinished(ctx context.Context) error {
 	return s.machine.FireCtx(ctx, triggerTimerFinished)
 }
@@ -341,7 +646,14 @@ func (s *StateMachine) StopAlarming(ctx context.Context) error {
 }
 
 func (s *StateMachine) startTimer(ctx context.Context, args ...any) error {
-	s.currentRemainingTime = s.initialRemainingTime
+	// When resuming, we continue counting down from where we paused instead of starting over
+	trigger := stateless.GetTransition(ctx).Trigger
+	resuming := trigger == TriggerResumeTimer || trigger == triggerResumeTimerWithRemainingTime
+	// When we cancel dragging, the timer continues as if we never started dragging
+	continuing := trigger == TriggerCancelDragging
+	if !resuming && !continuing {
+		s.currentRemainingTime = s.initialRemainingTime
+	}
 	s.ticker = time.NewTicker(tickerInterval)
 	s.tickerCtx, s.cancelTickerCtx = context.WithCancel(s.ctx)
 
@@ -372,6 +684,26 @@ func (s *StateMachine) startTimer(ctx context.Context, args ...any) error {
 		}
 	}()
 
+	if continuing {
+		return nil
+	}
+
+	if resuming {
+		if s.hooks.OnResumeTimer == nil {
+			return nil
+		}
+
+		s.log.InfoContext(
+			ctx, "Calling onResumeTimer hook",
+			"currentRemainingTime", s.currentRemainingTime,
+		)
+		if err := s.hooks.OnResumeTimer(ctx, s.currentRemainingTime); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
 	s.log.InfoContext(ctx, "Calling onStartTimer hook")
 	if err := s.hooks.OnStartTimer(ctx); err != nil {
 		return err
@@ -380,6 +712,22 @@ func (s *StateMachine) startTimer(ctx context.Context, args ...any) error {
 	return nil
 }
 
+func (s *StateMachine) pauseTimer(ctx context.Context, args ...any) error {
+	if s.hooks.OnPauseTimer == nil {
+		return nil
+	}
+
+	s.log.InfoContext(
+		ctx, "Calling onPauseTimer hook",
+		"currentRemainingTime", s.currentRemainingTime,
+	)
+	if err := s.hooks.OnPauseTimer(ctx, s.currentRemainingTime); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *StateMachine) stopTimer(ctx context.Context, args ...any) error {
 	if err := s.stopTimerWithoutHooks(ctx, args...); err != nil {
 		return err
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"slices"
 	"testing"
 	"testing/synctest"
 	"time"
@@ -419,6 +421,122 @@ func TestStopDragging(t *testing.T) {
 	}
 }
 
+func TestSetInitialRemainingTime(t *testing.T) {
+	var setInitialRemainingTimeTests = []struct {
+		name                 string
+		initialRemainingTime time.Duration
+		prepare              func(*StateMachine) error
+		expectErr            bool
+	}{
+		{
+			name:                 "can set valid initial remaining time in stopped state",
+			initialRemainingTime: DefaultInitialRemainingTime,
+			prepare: func(sm *StateMachine) error {
+				return nil
+			},
+			expectErr: false,
+		},
+		{
+			name:                 "can set maximum initial remaining time in stopped state",
+			initialRemainingTime: MaxInitialRemainingTime,
+			prepare: func(sm *StateMachine) error {
+				return nil
+			},
+			expectErr: false,
+		},
+		{
+			name:                 "can not set initial remaining time below minimum remaining time",
+			initialRemainingTime: MinInitialRemainingTime - RemainingTimerAdjustmentInterval,
+			prepare: func(sm *StateMachine) error {
+				return nil
+			},
+			expectErr: true,
+		},
+		{
+			name:                 "can not set initial remaining time above maximum remaining time",
+			initialRemainingTime: MaxInitialRemainingTime + RemainingTimerAdjustmentInterval,
+			prepare: func(sm *StateMachine) error {
+				return nil
+			},
+			expectErr: true,
+		},
+		{
+			name:                 "can not set initial remaining time that's not divisible by remainingTimerAdjustmentInterval",
+			initialRemainingTime: DefaultInitialRemainingTime + time.Second,
+			prepare: func(sm *StateMachine) error {
+				return nil
+			},
+			expectErr: true,
+		},
+		{
+			name:                 "can not set initial remaining time while counting down",
+			initialRemainingTime: DefaultInitialRemainingTime,
+			prepare: func(sm *StateMachine) error {
+				return sm.StartTimer(t.Context())
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range setInitialRemainingTimeTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				var (
+					onStartTimerCalled = 0
+
+					internalInitialRemainingTime = MinInitialRemainingTime
+				)
+				s := newTestingStateMachine(
+					t,
+					MinInitialRemainingTime,
+					&Hooks{
+						OnStartTimer: func(ctx context.Context) error {
+							onStartTimerCalled++
+
+							return nil
+						},
+						OnStopTimer: func(ctx context.Context) error { return nil },
+
+						OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
+							internalInitialRemainingTime = initialRemainingTime
+
+							return nil
+						},
+						OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error { return nil },
+
+						OnStartAlarm: func(ctx context.Context) error { return nil },
+						OnStopAlarm:  func(ctx context.Context) error { return nil },
+
+						OnPermittedTriggersChange: func(ctx context.Context, permittedTriggers []Trigger) error { return nil },
+					},
+				)
+				t.Cleanup(func() {
+					_ = s.StopTimer(context.Background())
+				})
+
+				require.NoError(t, tt.prepare(s))
+				startTimerCalledBefore := onStartTimerCalled
+
+				canSetInitialRemainingTime, err := s.CanSetInitialRemainingTime(t.Context(), tt.initialRemainingTime)
+				require.NoError(t, err)
+				require.Equal(t, !tt.expectErr, canSetInitialRemainingTime)
+
+				err = s.SetInitialRemainingTime(t.Context(), tt.initialRemainingTime)
+				if tt.expectErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+
+					require.Equal(t, tt.initialRemainingTime, internalInitialRemainingTime)
+				}
+
+				// Unlike stopping to drag, setting the initial remaining time doesn't start the timer
+				require.Equal(t, startTimerCalledBefore, onStartTimerCalled)
+			},
+		)
+	}
+}
+
 func TestStopTimer(t *testing.T) {
 	var stopTimerTests = []struct {
 		name                       string
@@ -568,6 +686,357 @@ func TestStartTimer(t *testing.T) {
 	}
 }
 
+func TestStartTimerWithLabel(t *testing.T) {
+	var startTimerWithLabelTests = []struct {
+		name          string
+		prepare       func(*StateMachine) error
+		label         string
+		expectErr     bool
+		expectedLabel string
+		labelChanges  []string
+	}{
+		{
+			name: "sets the label when starting from stopped state",
+			prepare: func(sm *StateMachine) error {
+				return nil
+			},
+			label:         "Write report",
+			expectErr:     false,
+			expectedLabel: "Write report",
+			labelChanges:  []string{"Write report"},
+		},
+		{
+			name: "does not change the label if it is the same",
+			prepare: func(sm *StateMachine) error {
+				return sm.SetLabel(t.Context(), "Write report")
+			},
+			label:         "Write report",
+			expectErr:     false,
+			expectedLabel: "Write report",
+			labelChanges:  []string{"Write report"},
+		},
+		{
+			name: "can clear the label",
+			prepare: func(sm *StateMachine) error {
+				return sm.SetLabel(t.Context(), "Write report")
+			},
+			label:         "",
+			expectErr:     false,
+			expectedLabel: "",
+			labelChanges:  []string{"Write report", ""},
+		},
+		{
+			name: "does not set the label if the timer can not be started",
+			prepare: func(sm *StateMachine) error {
+				return sm.StartDragging(t.Context())
+			},
+			label:         "Write report",
+			expectErr:     true,
+			expectedLabel: "",
+			labelChanges:  nil,
+		},
+	}
+	for _, tt := range startTimerWithLabelTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				var (
+					labelChanges []string
+					startLabels  []string
+				)
+				var s *StateMachine
+				s = newTestingStateMachine(
+					t,
+					MinInitialRemainingTime,
+					&Hooks{
+						OnStartTimer: func(ctx context.Context) error {
+							startLabels = append(startLabels, s.Label())
+
+							return nil
+						},
+						OnStopTimer: func(ctx context.Context) error { return nil },
+
+						OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error { return nil },
+						OnCurrentRemainingTimeTick:   func(ctx context.Context, currentRemainingTime time.Duration) error { return nil },
+
+						OnLabelChange: func(ctx context.Context, label string) error {
+							labelChanges = append(labelChanges, label)
+
+							return nil
+						},
+
+						OnStartAlarm: func(ctx context.Context) error { return nil },
+						OnStopAlarm:  func(ctx context.Context) error { return nil },
+
+						OnPermittedTriggersChange: func(ctx context.Context, permittedTriggers []Trigger) error { return nil },
+					},
+				)
+
+				require.NoError(t, tt.prepare(s))
+
+				err := s.StartTimerWithLabel(t.Context(), tt.label)
+				if tt.expectErr {
+					require.Error(t, err)
+					require.Empty(t, startLabels)
+				} else {
+					require.NoError(t, err)
+
+					// Hooks already see the label when the timer starts
+					require.Equal(t, []string{tt.expectedLabel}, startLabels)
+				}
+
+				require.Equal(t, tt.expectedLabel, s.Label())
+				require.Equal(t, tt.labelChanges, labelChanges)
+			},
+		)
+	}
+}
+
+func TestSetLabelWithoutHook(t *testing.T) {
+	s := newTestingStateMachine(t, MinInitialRemainingTime, &Hooks{})
+
+	// The label change hook is optional
+	require.NoError(t, s.SetLabel(t.Context(), "Write report"))
+	require.Equal(t, "Write report", s.Label())
+}
+
+func TestExtendTimer(t *testing.T) {
+	var extendTimerTests = []struct {
+		name                         string
+		initialRemainingTime         time.Duration
+		prepare                      func(*StateMachine) error
+		extension                    time.Duration
+		expectErr                    bool
+		internalInitialRemainingTime time.Duration
+		onStartTimerCalled           int
+	}{
+		{
+			name:                 "can extend by multiple intervals while restarting the timer once",
+			initialRemainingTime: DefaultInitialRemainingTime,
+			prepare: func(sm *StateMachine) error {
+				return sm.StartTimer(t.Context())
+			},
+			extension:                    time.Minute * 5,
+			internalInitialRemainingTime: DefaultInitialRemainingTime + time.Minute*5,
+			onStartTimerCalled:           2,
+		},
+		{
+			name:                 "stops at the maximum remaining time",
+			initialRemainingTime: MaxInitialRemainingTime - time.Minute,
+			prepare: func(sm *StateMachine) error {
+				return sm.StartTimer(t.Context())
+			},
+			extension:                    time.Minute * 5,
+			internalInitialRemainingTime: MaxInitialRemainingTime,
+			onStartTimerCalled:           2,
+		},
+		{
+			name:                 "can not extend at the maximum remaining time",
+			initialRemainingTime: MaxInitialRemainingTime,
+			prepare: func(sm *StateMachine) error {
+				return sm.StartTimer(t.Context())
+			},
+			extension:                    time.Minute * 5,
+			expectErr:                    true,
+			internalInitialRemainingTime: MaxInitialRemainingTime,
+			onStartTimerCalled:           1,
+		},
+		{
+			name:                 "can not extend by less than one interval",
+			initialRemainingTime: DefaultInitialRemainingTime,
+			prepare: func(sm *StateMachine) error {
+				return sm.StartTimer(t.Context())
+			},
+			extension:                    time.Second,
+			expectErr:                    true,
+			internalInitialRemainingTime: DefaultInitialRemainingTime,
+			onStartTimerCalled:           1,
+		},
+		{
+			name:                 "can not extend in stopped state",
+			initialRemainingTime: DefaultInitialRemainingTime,
+			prepare: func(sm *StateMachine) error {
+				return nil
+			},
+			extension:                    time.Minute * 5,
+			expectErr:                    true,
+			internalInitialRemainingTime: DefaultInitialRemainingTime,
+		},
+	}
+	for _, tt := range extendTimerTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				var (
+					onStartTimerCalled           int
+					internalInitialRemainingTime = tt.initialRemainingTime
+				)
+				s := newTestingStateMachine(
+					t,
+					tt.initialRemainingTime,
+					&Hooks{
+						OnStartTimer: func(ctx context.Context) error {
+							onStartTimerCalled++
+
+							return nil
+						},
+						OnStopTimer: func(ctx context.Context) error { return nil },
+
+						OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
+							internalInitialRemainingTime = initialRemainingTime
+
+							return nil
+						},
+						OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error { return nil },
+
+						OnStartAlarm: func(ctx context.Context) error { return nil },
+						OnStopAlarm:  func(ctx context.Context) error { return nil },
+
+						OnPermittedTriggersChange: func(ctx context.Context, permittedTriggers []Trigger) error { return nil },
+					},
+				)
+				t.Cleanup(func() {
+					_ = s.StopTimer(context.Background())
+				})
+
+				require.NoError(t, tt.prepare(s))
+
+				err := s.ExtendTimer(t.Context(), tt.extension)
+				if tt.expectErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				require.Equal(t, tt.internalInitialRemainingTime, internalInitialRemainingTime)
+				require.Equal(t, tt.onStartTimerCalled, onStartTimerCalled)
+			},
+		)
+	}
+}
+
+func TestPauseAndResumeTimer(t *testing.T) {
+	var pauseAndResumeTimerTests = []struct {
+		name                 string
+		prepare              func(*StateMachine) error
+		elapsed              time.Duration
+		expectPauseErr       bool
+		onPauseTimerCalled   int
+		onResumeTimerCalled  int
+		currentRemainingTime time.Duration
+	}{
+		{
+			name: "can pause and resume from where we left off in counting down state",
+			prepare: func(sm *StateMachine) error {
+				return sm.StartTimer(t.Context())
+			},
+			elapsed:              time.Second * 10,
+			expectPauseErr:       false,
+			onPauseTimerCalled:   1,
+			onResumeTimerCalled:  1,
+			currentRemainingTime: DefaultInitialRemainingTime - time.Second*10,
+		},
+		{
+			name: "can not pause in stopped state",
+			prepare: func(sm *StateMachine) error {
+				return nil
+			},
+			expectPauseErr: true,
+		},
+		{
+			name: "can not pause in dragging state",
+			prepare: func(sm *StateMachine) error {
+				return sm.StartDragging(t.Context())
+			},
+			expectPauseErr: true,
+		},
+	}
+	for _, tt := range pauseAndResumeTimerTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				synctest.Test(t, func(t *testing.T) {
+					var (
+						onPauseTimerCalled,
+						onResumeTimerCalled,
+						onStartTimerCalled int
+
+						pausedRemainingTime,
+						resumedRemainingTime time.Duration
+					)
+					s := newTestingStateMachine(
+						t,
+						DefaultInitialRemainingTime,
+						&Hooks{
+							OnStartTimer: func(ctx context.Context) error {
+								onStartTimerCalled++
+
+								return nil
+							},
+							OnStopTimer: func(ctx context.Context) error { return nil },
+
+							OnPauseTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+								onPauseTimerCalled++
+								pausedRemainingTime = currentRemainingTime
+
+								return nil
+							},
+							OnResumeTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+								onResumeTimerCalled++
+								resumedRemainingTime = currentRemainingTime
+
+								return nil
+							},
+
+							OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error { return nil },
+							OnCurrentRemainingTimeTick:   func(ctx context.Context, currentRemainingTime time.Duration) error { return nil },
+
+							OnStartAlarm: func(ctx context.Context) error { return nil },
+							OnStopAlarm:  func(ctx context.Context) error { return nil },
+
+							OnPermittedTriggersChange: func(ctx context.Context, permittedTriggers []Trigger) error { return nil },
+						},
+					)
+
+					require.NoError(t, tt.prepare(s))
+					startTimerCalled := onStartTimerCalled
+
+					time.Sleep(tt.elapsed)
+					synctest.Wait()
+
+					err := s.PauseTimer(t.Context())
+					if tt.expectPauseErr {
+						require.Error(t, err)
+						require.Error(t, s.ResumeTimer(t.Context()))
+					} else {
+						require.NoError(t, err)
+
+						// No time passes while paused
+						time.Sleep(time.Minute)
+						synctest.Wait()
+
+						require.NoError(t, s.ResumeTimer(t.Context()))
+
+						require.Equal(t, tt.currentRemainingTime, pausedRemainingTime)
+						require.Equal(t, tt.currentRemainingTime, resumedRemainingTime)
+						require.Equal(t, tt.currentRemainingTime, s.currentRemainingTime)
+
+						// Resuming doesn't start the timer over
+						require.Equal(t, startTimerCalled, onStartTimerCalled)
+					}
+
+					require.Equal(t, tt.onPauseTimerCalled, onPauseTimerCalled)
+					require.Equal(t, tt.onResumeTimerCalled, onResumeTimerCalled)
+
+					if s.cancelTickerCtx != nil {
+						s.cancelTickerCtx()
+					}
+				})
+			},
+		)
+	}
+}
+
 func TestTimerFinished(t *testing.T) {
 	var timerFinishedTests = []struct {
 		name               string
@@ -989,3 +1458,588 @@ func TestGetInitialRemainingTimeFromCurrentRemainingTime(t *testing.T) {
 		)
 	}
 }
+
+// timerModel is a simplified reference implementation of the timer rules
+// that the state machine is checked against in TestModel
+type timerModel struct {
+	state,
+	stateBeforeDragging state
+	initialRemainingTime,
+	currentRemainingTime time.Duration
+}
+
+func (m *timerModel) currentRemainingTimeInIntervals(intervalsToAdd int) time.Duration {
+	// Rounds half up to the nearest interval, which is equivalent to math.Round
+	// for the non-negative durations we're working with here
+	intervals := (m.currentRemainingTime+RemainingTimerAdjustmentInterval/2)/RemainingTimerAdjustmentInterval + time.Duration(intervalsToAdd)
+
+	return intervals * RemainingTimerAdjustmentInterval
+}
+
+func (m *timerModel) canPlusTimer() bool {
+	switch m.state {
+	case stateStopped:
+		return m.initialRemainingTime+RemainingTimerAdjustmentInterval <= MaxInitialRemainingTime
+	case stateCountingDown:
+		return m.currentRemainingTimeInIntervals(1) <= MaxInitialRemainingTime
+	}
+
+	return false
+}
+
+func (m *timerModel) canMinusTimer() bool {
+	switch m.state {
+	case stateStopped:
+		return m.initialRemainingTime-RemainingTimerAdjustmentInterval >= MinInitialRemainingTime
+	case stateCountingDown:
+		return m.currentRemainingTimeInIntervals(-1) >= MinInitialRemainingTime
+	}
+
+	return false
+}
+
+func (m *timerModel) canExtendTimer(extension time.Duration) bool {
+	return m.state == stateCountingDown &&
+		extension >= RemainingTimerAdjustmentInterval &&
+		m.currentRemainingTimeInIntervals(1) <= MaxInitialRemainingTime
+}
+
+func (m *timerModel) canAdjustTimer(adjustment time.Duration) bool {
+	return (m.state == stateStopped || m.state == stateCountingDown || m.state == statePaused) &&
+		adjustment != 0 &&
+		(adjustment/RemainingTimerAdjustmentInterval)*RemainingTimerAdjustmentInterval == adjustment
+}
+
+func (m *timerModel) canResumeTimerWithRemainingTime(currentRemainingTime time.Duration) bool {
+	return m.state == statePaused &&
+		currentRemainingTime >= tickerInterval &&
+		currentRemainingTime <= m.initialRemainingTime &&
+		(currentRemainingTime/tickerInterval)*tickerInterval == currentRemainingTime
+}
+
+func (m *timerModel) canStopDragging(remainingTime time.Duration) bool {
+	return m.state == stateDragging &&
+		remainingTime >= MinInitialRemainingTime &&
+		remainingTime <= MaxInitialRemainingTime &&
+		(remainingTime/RemainingTimerAdjustmentInterval)*RemainingTimerAdjustmentInterval == remainingTime
+}
+
+func (m *timerModel) permittedTriggers() []Trigger {
+	permittedTriggers := []Trigger{}
+
+	if m.canPlusTimer() {
+		permittedTriggers = append(permittedTriggers, TriggerPlusTimer)
+	}
+
+	if m.canMinusTimer() {
+		permittedTriggers = append(permittedTriggers, TriggerMinusTimer)
+	}
+
+	switch m.state {
+	case stateStopped:
+		permittedTriggers = append(permittedTriggers, TriggerStartDragging, TriggerStartTimer)
+	case stateCountingDown:
+		permittedTriggers = append(permittedTriggers, TriggerStartDragging, TriggerStopTimer, TriggerPauseTimer, triggerTimerFinished)
+	case statePaused:
+		permittedTriggers = append(permittedTriggers, TriggerStartDragging, TriggerResumeTimer, TriggerStopTimer)
+	case stateDragging:
+		permittedTriggers = append(permittedTriggers, TriggerCancelDragging)
+	case stateAlarming:
+		permittedTriggers = append(permittedTriggers, TriggerStopAlarming)
+	}
+
+	return permittedTriggers
+}
+
+func (m *timerModel) permittedTriggersChangeEvent() string {
+	return permittedTriggersChangeEvent(m.permittedTriggers())
+}
+
+func (m *timerModel) startTimer() []string {
+	m.state = stateCountingDown
+	m.currentRemainingTime = m.initialRemainingTime
+
+	return []string{"startTimer"}
+}
+
+func (m *timerModel) setInitialRemainingTime(initialRemainingTime time.Duration) []string {
+	m.initialRemainingTime = initialRemainingTime
+
+	return []string{fmt.Sprintf("initialRemainingTimeChange(%v)", m.initialRemainingTime)}
+}
+
+func (m *timerModel) plusOrMinusTimer(intervalsToAdd int) []string {
+	var events []string
+	if m.state == stateCountingDown {
+		events = m.setInitialRemainingTime(m.currentRemainingTimeInIntervals(intervalsToAdd))
+		events = append(events, m.startTimer()...)
+	} else {
+		events = m.setInitialRemainingTime(m.initialRemainingTime + time.Duration(intervalsToAdd)*RemainingTimerAdjustmentInterval)
+	}
+
+	return append(events, m.permittedTriggersChangeEvent())
+}
+
+func (m *timerModel) extendTimer(extension time.Duration) []string {
+	events := m.setInitialRemainingTime(min(m.currentRemainingTimeInIntervals(int(extension/RemainingTimerAdjustmentInterval)), MaxInitialRemainingTime))
+	events = append(events, m.startTimer()...)
+
+	return append(events, m.permittedTriggersChangeEvent())
+}
+
+func (m *timerModel) adjustTimer(adjustment time.Duration) []string {
+	remainingTime := m.currentRemainingTime
+	if m.state == stateStopped {
+		remainingTime = m.initialRemainingTime
+	}
+	intervals := (remainingTime+RemainingTimerAdjustmentInterval/2)/RemainingTimerAdjustmentInterval + adjustment/RemainingTimerAdjustmentInterval
+
+	events := m.setInitialRemainingTime(min(max(intervals*RemainingTimerAdjustmentInterval, MinInitialRemainingTime), MaxInitialRemainingTime))
+	switch m.state {
+	case stateCountingDown:
+		events = append(events, m.startTimer()...)
+	case statePaused:
+		m.currentRemainingTime = m.initialRemainingTime
+	}
+
+	return append(events, m.permittedTriggersChangeEvent())
+}
+
+func (m *timerModel) advance(d time.Duration) []string {
+	events := []string{}
+	for ; d >= tickerInterval && m.state == stateCountingDown; d -= tickerInterval {
+		m.currentRemainingTime -= tickerInterval
+
+		events = append(
+			events,
+			fmt.Sprintf("currentRemainingTimeTick(%v)", m.currentRemainingTime),
+			m.permittedTriggersChangeEvent(),
+		)
+
+		if m.currentRemainingTime == 0 {
+			m.state = stateAlarming
+
+			events = append(events, "stopTimer", "startAlarm", m.permittedTriggersChangeEvent())
+		}
+	}
+
+	return events
+}
+
+func permittedTriggersChangeEvent(permittedTriggers []Trigger) string {
+	sortedPermittedTriggers := slices.Clone(permittedTriggers)
+	slices.Sort(sortedPermittedTriggers)
+
+	return fmt.Sprintf("permittedTriggersChange(%v)", sortedPermittedTriggers)
+}
+
+// modelStep runs a single random action against both the model and the state machine
+// and returns a description of the action, whether the state machine should
+// have accepted it and the hook calls that the model expects
+type modelStep func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (name string, expectOk bool, expectedEvents []string, err error)
+
+var modelSteps = []modelStep{
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		if !m.canPlusTimer() {
+			return "plusTimer", false, []string{}, s.PlusTimer(t.Context())
+		}
+
+		return "plusTimer", true, m.plusOrMinusTimer(1), s.PlusTimer(t.Context())
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		if !m.canMinusTimer() {
+			return "minusTimer", false, []string{}, s.MinusTimer(t.Context())
+		}
+
+		return "minusTimer", true, m.plusOrMinusTimer(-1), s.MinusTimer(t.Context())
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		// Mostly whole intervals, sometimes less than one
+		extension := time.Duration(r.Int64N(int64(MaxInitialRemainingTime/RemainingTimerAdjustmentInterval/4))) * RemainingTimerAdjustmentInterval
+		if r.IntN(4) == 0 {
+			extension = time.Duration(r.Int64N(int64(RemainingTimerAdjustmentInterval)))
+		}
+		name := fmt.Sprintf("extendTimer(%v)", extension)
+
+		canExtendTimer, err := s.CanExtendTimer(t.Context(), extension)
+		require.NoError(t, err)
+		require.Equal(t, m.canExtendTimer(extension), canExtendTimer, name)
+
+		if !m.canExtendTimer(extension) {
+			return name, false, []string{}, s.ExtendTimer(t.Context(), extension)
+		}
+
+		return name, true, m.extendTimer(extension), s.ExtendTimer(t.Context(), extension)
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		// Mostly whole intervals in both directions, sometimes less than one
+		adjustment := time.Duration(r.Int64N(int64(MaxInitialRemainingTime/RemainingTimerAdjustmentInterval/2))-int64(MaxInitialRemainingTime/RemainingTimerAdjustmentInterval/4)) * RemainingTimerAdjustmentInterval
+		if r.IntN(4) == 0 {
+			adjustment = time.Duration(r.Int64N(int64(RemainingTimerAdjustmentInterval)))
+		}
+		name := fmt.Sprintf("adjustTimer(%v)", adjustment)
+
+		canAdjustTimer, err := s.CanAdjustTimer(t.Context(), adjustment)
+		require.NoError(t, err)
+		require.Equal(t, m.canAdjustTimer(adjustment), canAdjustTimer, name)
+
+		if !m.canAdjustTimer(adjustment) {
+			return name, false, []string{}, s.AdjustTimer(t.Context(), adjustment)
+		}
+
+		return name, true, m.adjustTimer(adjustment), s.AdjustTimer(t.Context(), adjustment)
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		if m.state != stateStopped && m.state != stateCountingDown && m.state != statePaused {
+			return "startDragging", false, []string{}, s.StartDragging(t.Context())
+		}
+
+		m.stateBeforeDragging = m.state
+		m.state = stateDragging
+
+		return "startDragging", true, []string{m.permittedTriggersChangeEvent()}, s.StartDragging(t.Context())
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		if m.state != stateDragging {
+			return "cancelDragging", false, []string{}, s.CancelDragging(t.Context())
+		}
+
+		m.state = m.stateBeforeDragging
+
+		return "cancelDragging", true, []string{m.permittedTriggersChangeEvent()}, s.CancelDragging(t.Context())
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		var remainingTime time.Duration
+		if r.IntN(4) == 0 {
+			// Mostly invalid since it's not divisible by the adjustment interval
+			remainingTime = time.Duration(r.Int64N(int64(MaxInitialRemainingTime)))
+		} else {
+			remainingTime = time.Duration(r.Int64N(int64(MaxInitialRemainingTime/RemainingTimerAdjustmentInterval+3))) * RemainingTimerAdjustmentInterval
+		}
+		name := fmt.Sprintf("stopDragging(%v)", remainingTime)
+
+		canStopDragging, err := s.CanStopDragging(t.Context(), remainingTime)
+		require.NoError(t, err)
+		require.Equal(t, m.canStopDragging(remainingTime), canStopDragging, name)
+
+		if !m.canStopDragging(remainingTime) {
+			return name, false, []string{}, s.StopDragging(t.Context(), remainingTime)
+		}
+
+		events := m.setInitialRemainingTime(remainingTime)
+		events = append(events, m.startTimer()...)
+		events = append(events, m.permittedTriggersChangeEvent())
+
+		return name, true, events, s.StopDragging(t.Context(), remainingTime)
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		if m.state != stateStopped {
+			return "startTimer", false, []string{}, s.StartTimer(t.Context())
+		}
+
+		events := m.startTimer()
+		events = append(events, m.permittedTriggersChangeEvent())
+
+		return "startTimer", true, events, s.StartTimer(t.Context())
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		if m.state != stateCountingDown && m.state != statePaused {
+			return "stopTimer", false, []string{}, s.StopTimer(t.Context())
+		}
+
+		m.state = stateStopped
+
+		return "stopTimer", true, []string{"stopTimer", m.permittedTriggersChangeEvent()}, s.StopTimer(t.Context())
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		if m.state != stateCountingDown {
+			return "pauseTimer", false, []string{}, s.PauseTimer(t.Context())
+		}
+
+		m.state = statePaused
+
+		return "pauseTimer", true, []string{fmt.Sprintf("pauseTimer(%v)", m.currentRemainingTime), m.permittedTriggersChangeEvent()}, s.PauseTimer(t.Context())
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		if m.state != statePaused {
+			return "resumeTimer", false, []string{}, s.ResumeTimer(t.Context())
+		}
+
+		m.state = stateCountingDown
+
+		return "resumeTimer", true, []string{fmt.Sprintf("resumeTimer(%v)", m.currentRemainingTime), m.permittedTriggersChangeEvent()}, s.ResumeTimer(t.Context())
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		currentRemainingTime := time.Duration(r.Int64N(int64(MaxInitialRemainingTime/tickerInterval+2))) * tickerInterval
+		if r.IntN(4) == 0 {
+			// Mostly invalid since it's not divisible by the ticker interval
+			currentRemainingTime = time.Duration(r.Int64N(int64(MaxInitialRemainingTime)))
+		}
+		name := fmt.Sprintf("resumeTimerWithRemainingTime(%v)", currentRemainingTime)
+
+		canResume, err := s.CanResumeTimerWithRemainingTime(t.Context(), currentRemainingTime)
+		require.NoError(t, err)
+		require.Equal(t, m.canResumeTimerWithRemainingTime(currentRemainingTime), canResume, name)
+
+		if !m.canResumeTimerWithRemainingTime(currentRemainingTime) {
+			return name, false, []string{}, s.ResumeTimerWithRemainingTime(t.Context(), currentRemainingTime)
+		}
+
+		m.state = stateCountingDown
+		m.currentRemainingTime = currentRemainingTime
+
+		return name, true, []string{fmt.Sprintf("resumeTimer(%v)", m.currentRemainingTime), m.permittedTriggersChangeEvent()}, s.ResumeTimerWithRemainingTime(t.Context(), currentRemainingTime)
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		if m.state != stateAlarming {
+			return "stopAlarming", false, []string{}, s.StopAlarming(t.Context())
+		}
+
+		m.state = stateStopped
+
+		return "stopAlarming", true, []string{"stopAlarm", m.permittedTriggersChangeEvent()}, s.StopAlarming(t.Context())
+	},
+	func(t *testing.T, r *rand.Rand, m *timerModel, s *StateMachine) (string, bool, []string, error) {
+		var d time.Duration
+		switch r.IntN(3) {
+		case 0:
+			d = tickerInterval * time.Duration(1+r.IntN(10))
+		case 1:
+			d = tickerInterval * time.Duration(1+r.IntN(int(RemainingTimerAdjustmentInterval/tickerInterval)*4))
+		default:
+			d = tickerInterval * time.Duration(1+r.IntN(int((MaxInitialRemainingTime+RemainingTimerAdjustmentInterval)/tickerInterval)))
+		}
+
+		events := m.advance(d)
+
+		// Since all other steps happen at whole multiples of the ticker interval, the ticker
+		// will fire at exactly the same instant as this sleep returns, so we need to wait for
+		// the ticker goroutine to process it
+		time.Sleep(d)
+		synctest.Wait()
+
+		return fmt.Sprintf("advance(%v)", d), true, events, nil
+	},
+}
+
+func TestModel(t *testing.T) {
+	const (
+		seeds         = 25
+		stepsPerSeed  = 200
+		initialStates = MaxInitialRemainingTime / RemainingTimerAdjustmentInterval
+	)
+
+	for seed := range uint64(seeds) {
+		t.Run(
+			fmt.Sprintf("seed %v", seed),
+			func(t *testing.T) {
+				synctest.Test(t, func(t *testing.T) {
+					r := rand.New(rand.NewPCG(seed, seed))
+
+					m := &timerModel{
+						state:                stateStopped,
+						initialRemainingTime: MinInitialRemainingTime + time.Duration(r.Int64N(int64(initialStates)))*RemainingTimerAdjustmentInterval,
+					}
+
+					events := []string{}
+					s := newTestingStateMachine(
+						t,
+						m.initialRemainingTime,
+						&Hooks{
+							OnStartTimer: func(ctx context.Context) error {
+								events = append(events, "startTimer")
+
+								return nil
+							},
+							OnStopTimer: func(ctx context.Context) error {
+								events = append(events, "stopTimer")
+
+								return nil
+							},
+
+							OnPauseTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+								events = append(events, fmt.Sprintf("pauseTimer(%v)", currentRemainingTime))
+
+								return nil
+							},
+							OnResumeTimer: func(ctx context.Context, currentRemainingTime time.Duration) error {
+								events = append(events, fmt.Sprintf("resumeTimer(%v)", currentRemainingTime))
+
+								return nil
+							},
+
+							OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
+								events = append(events, fmt.Sprintf("initialRemainingTimeChange(%v)", initialRemainingTime))
+
+								return nil
+							},
+							OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error {
+								events = append(events, fmt.Sprintf("currentRemainingTimeTick(%v)", currentRemainingTime))
+
+								return nil
+							},
+
+							OnStartAlarm: func(ctx context.Context) error {
+								events = append(events, "startAlarm")
+
+								return nil
+							},
+							OnStopAlarm: func(ctx context.Context) error {
+								events = append(events, "stopAlarm")
+
+								return nil
+							},
+
+							OnPermittedTriggersChange: func(ctx context.Context, permittedTriggers []Trigger) error {
+								events = append(events, permittedTriggersChangeEvent(permittedTriggers))
+
+								return nil
+							},
+						},
+					)
+
+					s.FlushPermittedTriggers(t.Context())
+					require.Equal(t, []string{m.permittedTriggersChangeEvent()}, events)
+
+					steps := []string{}
+					for range stepsPerSeed {
+						events = []string{}
+
+						name, expectOk, expectedEvents, err := modelSteps[r.IntN(len(modelSteps))](t, r, m, s)
+						steps = append(steps, name)
+						if expectOk {
+							require.NoError(t, err, "steps: %v", steps)
+						} else {
+							require.Error(t, err, "steps: %v", steps)
+						}
+
+						// Hooks are called in the order the model expects
+						require.Equal(t, expectedEvents, events, "steps: %v", steps)
+
+						// The state and remaining time are the same as in the model, and within bounds
+						require.Equal(t, m.state, s.machine.MustState(), "steps: %v", steps)
+						require.Equal(t, m.initialRemainingTime, s.initialRemainingTime, "steps: %v", steps)
+						require.GreaterOrEqual(t, s.initialRemainingTime, MinInitialRemainingTime, "steps: %v", steps)
+						require.LessOrEqual(t, s.initialRemainingTime, MaxInitialRemainingTime, "steps: %v", steps)
+						if m.state == stateCountingDown || m.state == statePaused || m.state == stateAlarming {
+							require.Equal(t, m.currentRemainingTime, s.currentRemainingTime, "steps: %v", steps)
+							require.GreaterOrEqual(t, s.currentRemainingTime, time.Duration(0), "steps: %v", steps)
+							require.LessOrEqual(t, s.currentRemainingTime, s.initialRemainingTime, "steps: %v", steps)
+						}
+
+						// The ticker is only running while we're counting down
+						if m.state == stateCountingDown {
+							require.NoError(t, s.tickerCtx.Err(), "steps: %v", steps)
+						} else if s.tickerCtx != nil {
+							require.Error(t, s.tickerCtx.Err(), "steps: %v", steps)
+						}
+
+						// The permitted triggers are the same as in the model
+						events = []string{}
+						s.FlushPermittedTriggers(t.Context())
+						require.Equal(t, []string{m.permittedTriggersChangeEvent()}, events, "steps: %v", steps)
+					}
+				})
+			},
+		)
+	}
+}
+
+func FuzzValidInitialRemainingTime(f *testing.F) {
+	f.Add(int64(0))
+	f.Add(int64(MinInitialRemainingTime))
+	f.Add(int64(MinInitialRemainingTime - 1))
+	f.Add(int64(MaxInitialRemainingTime))
+	f.Add(int64(MaxInitialRemainingTime + RemainingTimerAdjustmentInterval))
+	f.Add(int64(DefaultInitialRemainingTime + time.Millisecond*50))
+	f.Add(int64(-RemainingTimerAdjustmentInterval))
+
+	f.Fuzz(func(t *testing.T, rawRemainingTime int64) {
+		remainingTime := time.Duration(rawRemainingTime)
+
+		internalInitialRemainingTime := MinInitialRemainingTime
+		s := newTestingStateMachine(
+			t,
+			MinInitialRemainingTime,
+			&Hooks{
+				OnStartTimer: func(ctx context.Context) error { return nil },
+				OnStopTimer:  func(ctx context.Context) error { return nil },
+
+				OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
+					internalInitialRemainingTime = initialRemainingTime
+
+					return nil
+				},
+				OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error { return nil },
+
+				OnStartAlarm: func(ctx context.Context) error { return nil },
+				OnStopAlarm:  func(ctx context.Context) error { return nil },
+
+				OnPermittedTriggersChange: func(ctx context.Context, permittedTriggers []Trigger) error { return nil },
+			},
+		)
+
+		valid := s.validInitialRemainingTime(t.Context(), remainingTime)
+
+		// Valid remaining times are always within bounds and snapped to the adjustment interval
+		if valid {
+			require.GreaterOrEqual(t, remainingTime, MinInitialRemainingTime)
+			require.LessOrEqual(t, remainingTime, MaxInitialRemainingTime)
+			require.Equal(t, remainingTime, getInitialRemainingTimeFromCurrentRemainingTime(remainingTime, 0))
+		}
+
+		// Stopping to drag is only possible with valid remaining times
+		require.NoError(t, s.StartDragging(t.Context()))
+
+		canStopDragging, err := s.CanStopDragging(t.Context(), remainingTime)
+		require.NoError(t, err)
+		require.Equal(t, valid, canStopDragging)
+
+		err = s.StopDragging(t.Context(), remainingTime)
+		if valid {
+			require.NoError(t, err)
+			require.Equal(t, remainingTime, internalInitialRemainingTime)
+
+			require.NoError(t, s.StopTimer(t.Context()))
+		} else {
+			require.Error(t, err)
+			require.Equal(t, MinInitialRemainingTime, internalInitialRemainingTime)
+		}
+	})
+}
+
+func FuzzGetInitialRemainingTimeFromCurrentRemainingTime(f *testing.F) {
+	f.Add(int64(0), 1)
+	f.Add(int64(time.Second*14), 2)
+	f.Add(int64(time.Second*15), 2)
+	f.Add(int64(RemainingTimerAdjustmentInterval/2-1), -1)
+	f.Add(int64(MaxInitialRemainingTime), 1)
+	f.Add(int64(MinInitialRemainingTime), -1)
+
+	f.Fuzz(func(t *testing.T, rawCurrentRemainingTime int64, intervalsToAdd int) {
+		currentRemainingTime := time.Duration(rawCurrentRemainingTime)
+		// The current remaining time can never be negative or larger than the maximum initial remaining
+		// time, and we never add or remove more than a few intervals at once
+		if currentRemainingTime < 0 || currentRemainingTime > MaxInitialRemainingTime || intervalsToAdd < -1000 || intervalsToAdd > 1000 {
+			t.Skip()
+		}
+
+		initialRemainingTime := getInitialRemainingTimeFromCurrentRemainingTime(currentRemainingTime, intervalsToAdd)
+
+		// The result is always snapped to the adjustment interval
+		require.Zero(t, initialRemainingTime%RemainingTimerAdjustmentInterval)
+
+		// Snapping rounds to the nearest interval before adding the intervals
+		snappedRemainingTime := getInitialRemainingTimeFromCurrentRemainingTime(currentRemainingTime, 0)
+		require.LessOrEqual(t, (snappedRemainingTime - currentRemainingTime).Abs(), RemainingTimerAdjustmentInterval/2)
+		require.Equal(t, snappedRemainingTime+time.Duration(intervalsToAdd)*RemainingTimerAdjustmentInterval, initialRemainingTime)
+
+		// Snapping is idempotent
+		require.Equal(t, initialRemainingTime, getInitialRemainingTimeFromCurrentRemainingTime(initialRemainingTime, 0))
+
+		// Snapping is monotonic
+		if currentRemainingTime < MaxInitialRemainingTime {
+			require.LessOrEqual(t, initialRemainingTime, getInitialRemainingTimeFromCurrentRemainingTime(currentRemainingTime+1, intervalsToAdd))
+		}
+	})
+}
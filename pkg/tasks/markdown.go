@@ -0,0 +1,28 @@
+package tasks
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownOpenTaskPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[ \]\s+(.+)$`)
+)
+
+// NewMarkdown creates a source for the checklists in a Markdown file, e.g. "- [ ] Write report".
+// Checked items are skipped.
+func NewMarkdown(path string) Source {
+	return &fileSource{
+		path:  path,
+		parse: parseMarkdownLine,
+	}
+}
+
+func parseMarkdownLine(line string) (string, bool) {
+	match := markdownOpenTaskPattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(match[1]), true
+}
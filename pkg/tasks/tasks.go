@@ -0,0 +1,202 @@
+package tasks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Key of the tag that counts the completed pomodoros of a task, e.g. "pom:3"
+	PomodoroTagKey = "pom"
+)
+
+var (
+	ErrTaskNotFound = errors.New("task not found, the file might have changed since it was read")
+
+	pomodoroTagPattern = regexp.MustCompile(`(^|\s)` + PomodoroTagKey + `:(\d+)(\s|$)`)
+)
+
+// Task is an open task from a task source
+type Task struct {
+	// Title of the task without metadata such as its priority or tags
+	Title string
+	// Number of pomodoros that were completed for the task
+	Pomodoros int
+
+	// Line of the task in its file, starting at zero
+	line int
+	// Line as it was when the task was read, to check that it didn't change when we write it
+	raw string
+}
+
+// Source is a list of tasks that sessions can be for
+type Source interface {
+	// Tasks returns the open tasks
+	Tasks() ([]Task, error)
+	// AddPomodoro counts a completed pomodoro for a task
+	AddPomodoro(task Task) error
+}
+
+// NewSource returns the source for a task file based on its extension. Markdown files are
+// read as checklists, all other files as todo.txt files.
+func NewSource(path string) Source {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return NewMarkdown(path)
+
+	default:
+		return NewTodoTxt(path)
+	}
+}
+
+// lineParser returns the title of the open task on a line, and whether there is one
+type lineParser func(line string) (title string, ok bool)
+
+// fileSource is a source that has one task per line in a text file
+type fileSource struct {
+	path  string
+	parse lineParser
+}
+
+func (s *fileSource) readLines() ([]string, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Split(string(content), "\n"), nil
+}
+
+func (s *fileSource) Tasks() ([]Task, error) {
+	lines, err := s.readLines()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []Task{}
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+
+		title, ok := s.parse(line)
+		if !ok {
+			continue
+		}
+
+		title, pomodoros := parsePomodoroTag(title)
+		if title == "" {
+			continue
+		}
+
+		tasks = append(tasks, Task{
+			Title:     title,
+			Pomodoros: pomodoros,
+
+			line: i,
+			raw:  line,
+		})
+	}
+
+	return tasks, nil
+}
+
+func (s *fileSource) AddPomodoro(task Task) error {
+	lines, err := s.readLines()
+	if err != nil {
+		return err
+	}
+
+	// The task might have moved if lines were added or removed before it
+	i := task.line
+	if i >= len(lines) || strings.TrimSuffix(lines[i], "\r") != task.raw {
+		i = -1
+		for j, line := range lines {
+			if strings.TrimSuffix(line, "\r") == task.raw {
+				i = j
+
+				break
+			}
+		}
+	}
+
+	if i < 0 || task.raw == "" {
+		return ErrTaskNotFound
+	}
+
+	lineEnding := ""
+	if strings.HasSuffix(lines[i], "\r") {
+		lineEnding = "\r"
+	}
+	lines[i] = setPomodoroTag(task.raw, task.Pomodoros+1) + lineEnding
+
+	return writeFileAtomically(s.path, []byte(strings.Join(lines, "\n")))
+}
+
+// parsePomodoroTag removes the pomodoro tag from a title and returns its count
+func parsePomodoroTag(title string) (string, int) {
+	match := pomodoroTagPattern.FindStringSubmatchIndex(title)
+	if match == nil {
+		return title, 0
+	}
+
+	pomodoros, err := strconv.Atoi(title[match[4]:match[5]])
+	if err != nil {
+		return title, 0
+	}
+
+	return strings.TrimSpace(title[:match[0]] + " " + title[match[1]:]), pomodoros
+}
+
+// setPomodoroTag sets the pomodoro tag on a line, which is appended if it doesn't have one yet
+func setPomodoroTag(line string, pomodoros int) string {
+	tag := PomodoroTagKey + ":" + strconv.Itoa(pomodoros)
+
+	match := pomodoroTagPattern.FindStringSubmatchIndex(line)
+	if match == nil {
+		return strings.TrimRight(line, " \t") + " " + tag
+	}
+
+	return line[:match[3]] + tag + line[match[6]:]
+}
+
+// writeFileAtomically replaces a file so that it is never left half-written, e.g. if the
+// app is closed while writing
+func writeFileAtomically(path string, content []byte) error {
+	// Renaming onto a symlink would replace it, so we replace the file it points to instead
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	if err := f.Chmod(info.Mode()); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
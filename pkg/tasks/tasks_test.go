@@ -0,0 +1,163 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var tasksTests = []struct {
+	name     string
+	file     string
+	content  string
+	expected []string
+	counts   []int
+}{
+	{
+		name: "todo.txt",
+		file: "todo.txt",
+		content: `(A) 2026-10-01 Write report +work @office
+x 2026-10-02 2026-10-01 Send invoice
+Call Alice pom:3
+
+2026-10-03 Review pull request pom:1 due:2026-10-05
+`,
+		expected: []string{"Write report +work @office", "Call Alice", "Review pull request due:2026-10-05"},
+		counts:   []int{0, 3, 1},
+	},
+	{
+		name:     "markdown checklist",
+		file:     "tasks.md",
+		content:  "# Today\r\n\r\n- [ ] Write report\r\n- [x] Send invoice\r\n  * [ ] Call Alice pom:2\r\n1. [ ] Review pull request\r\nSome notes\r\n",
+		expected: []string{"Write report", "Call Alice", "Review pull request"},
+		counts:   []int{0, 2, 0},
+	},
+}
+
+func TestTasks(t *testing.T) {
+	for _, tt := range tasksTests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			tasks, err := NewSource(path).Tasks()
+			require.NoError(t, err)
+
+			titles := []string{}
+			counts := []int{}
+			for _, task := range tasks {
+				titles = append(titles, task.Title)
+				counts = append(counts, task.Pomodoros)
+			}
+			require.Equal(t, tt.expected, titles)
+			require.Equal(t, tt.counts, counts)
+		})
+	}
+}
+
+var addPomodoroTests = []struct {
+	name     string
+	file     string
+	content  string
+	task     int
+	change   func(content string) string
+	expected string
+}{
+	{
+		name:     "appends tag",
+		file:     "todo.txt",
+		content:  "(A) Write report +work\nCall Alice\n",
+		task:     0,
+		expected: "(A) Write report +work pom:1\nCall Alice\n",
+	},
+	{
+		name:     "increments tag",
+		file:     "todo.txt",
+		content:  "Write report\nCall Alice pom:2 @phone\n",
+		task:     1,
+		expected: "Write report\nCall Alice pom:3 @phone\n",
+	},
+	{
+		name:     "keeps line endings",
+		file:     "tasks.md",
+		content:  "- [ ] Write report\r\n- [ ] Call Alice\r\n",
+		task:     1,
+		expected: "- [ ] Write report\r\n- [ ] Call Alice pom:1\r\n",
+	},
+	{
+		name:    "finds task that moved",
+		file:    "todo.txt",
+		content: "Write report\nCall Alice\n",
+		task:    1,
+		change: func(content string) string {
+			return "Send invoice\n" + content
+		},
+		expected: "Send invoice\nWrite report\nCall Alice pom:1\n",
+	},
+}
+
+func TestAddPomodoro(t *testing.T) {
+	for _, tt := range addPomodoroTests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			source := NewSource(path)
+
+			tasks, err := source.Tasks()
+			require.NoError(t, err)
+
+			if tt.change != nil {
+				require.NoError(t, os.WriteFile(path, []byte(tt.change(tt.content)), 0o644))
+			}
+
+			require.NoError(t, source.AddPomodoro(tasks[tt.task]))
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(content))
+		})
+	}
+}
+
+func TestAddPomodoroRemovedTask(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	require.NoError(t, os.WriteFile(path, []byte("Write report\n"), 0o644))
+
+	source := NewSource(path)
+
+	tasks, err := source.Tasks()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("x Write report\n"), 0o644))
+
+	require.ErrorIs(t, source.AddPomodoro(tasks[0]), ErrTaskNotFound)
+}
+
+func TestAddPomodoroSymlink(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "todo.txt")
+	require.NoError(t, os.WriteFile(path, []byte("Write report\n"), 0o644))
+
+	link := filepath.Join(dir, "link.txt")
+	require.NoError(t, os.Symlink(path, link))
+
+	source := NewSource(link)
+
+	tasks, err := source.Tasks()
+	require.NoError(t, err)
+
+	require.NoError(t, source.AddPomodoro(tasks[0]))
+
+	// The link keeps pointing to the file, which has the pomodoro
+	target, err := os.Readlink(link)
+	require.NoError(t, err)
+	require.Equal(t, path, target)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "Write report pom:1\n", string(content))
+}
@@ -0,0 +1,31 @@
+package tasks
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	todoTxtPriorityPattern     = regexp.MustCompile(`^\([A-Z]\) `)
+	todoTxtCreationDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} `)
+)
+
+// NewTodoTxt creates a source for a file in the todo.txt format, see https://github.com/todotxt/todo.txt.
+// Completed tasks are skipped, and the priority and creation date are removed from the titles.
+func NewTodoTxt(path string) Source {
+	return &fileSource{
+		path:  path,
+		parse: parseTodoTxtLine,
+	}
+}
+
+func parseTodoTxtLine(line string) (string, bool) {
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "x ") {
+		return "", false
+	}
+
+	line = todoTxtPriorityPattern.ReplaceAllString(line, "")
+	line = todoTxtCreationDatePattern.ReplaceAllString(line, "")
+
+	return strings.TrimSpace(line), true
+}
@@ -0,0 +1,315 @@
+package tray
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+const (
+	menuInterface = "com.canonical.dbusmenu"
+	menuPath      = dbus.ObjectPath("/MenuBar")
+
+	// ID of the invisible item that contains all other items
+	menuRootID int32 = 0
+)
+
+// MenuItem is an item of the context menu
+type MenuItem struct {
+	Label   string
+	Enabled bool
+	// Separators ignore all other fields
+	Separator bool
+
+	// OnClicked is called when the item is clicked
+	OnClicked func(ctx context.Context) error
+}
+
+type menuLayout struct {
+	ID         int32
+	Properties map[string]dbus.Variant
+	Children   []dbus.Variant
+}
+
+type menuItemProperties struct {
+	ID         int32
+	Properties map[string]dbus.Variant
+}
+
+type menuEvent struct {
+	ID        int32
+	EventID   string
+	Data      dbus.Variant
+	Timestamp uint32
+}
+
+// menu implements the DBusMenu protocol, which is what hosts use to show the context menu
+type menu struct {
+	ctx  context.Context
+	conn *dbus.Conn
+	log  *slog.Logger
+
+	lock     sync.Mutex
+	items    []MenuItem
+	revision uint32
+	exported bool
+}
+
+func newMenu(ctx context.Context, conn *dbus.Conn, log *slog.Logger) *menu {
+	return &menu{
+		ctx:  ctx,
+		conn: conn,
+		log:  log,
+
+		items: []MenuItem{},
+	}
+}
+
+func (m *menu) export() error {
+	props, err := prop.Export(m.conn, menuPath, prop.Map{
+		menuInterface: {
+			"Version":       {Value: uint32(3), Emit: prop.EmitFalse},
+			"TextDirection": {Value: "ltr", Emit: prop.EmitFalse},
+			"Status":        {Value: "normal", Emit: prop.EmitFalse},
+			"IconThemePath": {Value: []string{}, Emit: prop.EmitFalse},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := m.conn.Export(m, menuPath, menuInterface); err != nil {
+		return err
+	}
+
+	if err := m.conn.Export(introspect.NewIntrospectable(&introspect.Node{
+		Name: string(menuPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{
+				Name:       menuInterface,
+				Methods:    introspect.Methods(m),
+				Properties: props.Introspection(menuInterface),
+				Signals: []introspect.Signal{
+					{Name: "ItemsPropertiesUpdated", Args: []introspect.Arg{
+						{Name: "updatedProps", Type: "a(ia{sv})"},
+						{Name: "removedProps", Type: "a(ias)"},
+					}},
+					{Name: "LayoutUpdated", Args: []introspect.Arg{
+						{Name: "revision", Type: "u"},
+						{Name: "parent", Type: "i"},
+					}},
+					{Name: "ItemActivationRequested", Args: []introspect.Arg{
+						{Name: "id", Type: "i"},
+						{Name: "timestamp", Type: "u"},
+					}},
+				},
+			},
+		},
+	}), menuPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	m.exported = true
+	m.lock.Unlock()
+
+	return nil
+}
+
+func (m *menu) unexport() error {
+	m.lock.Lock()
+	m.exported = false
+	m.lock.Unlock()
+
+	for _, iface := range []string{menuInterface, "org.freedesktop.DBus.Properties", "org.freedesktop.DBus.Introspectable"} {
+		if err := m.conn.Export(nil, menuPath, iface); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *menu) setItems(items []MenuItem) error {
+	m.lock.Lock()
+	m.items = items
+	m.revision++
+
+	revision, exported := m.revision, m.exported
+	m.lock.Unlock()
+
+	if !exported {
+		return nil
+	}
+
+	return m.conn.Emit(menuPath, menuInterface+".LayoutUpdated", revision, menuRootID)
+}
+
+// Item IDs are the index of the item plus one, since the root item has ID zero
+func (m *menu) itemProperties(id int32) (map[string]dbus.Variant, bool) {
+	if id == menuRootID {
+		return map[string]dbus.Variant{
+			"children-display": dbus.MakeVariant("submenu"),
+		}, true
+	}
+
+	if id < 1 || int(id) > len(m.items) {
+		return nil, false
+	}
+
+	item := m.items[id-1]
+	if item.Separator {
+		return map[string]dbus.Variant{
+			"type": dbus.MakeVariant("separator"),
+		}, true
+	}
+
+	return map[string]dbus.Variant{
+		"label":   dbus.MakeVariant(item.Label),
+		"enabled": dbus.MakeVariant(item.Enabled),
+		"visible": dbus.MakeVariant(true),
+	}, true
+}
+
+func filterProperties(properties map[string]dbus.Variant, names []string) map[string]dbus.Variant {
+	if len(names) == 0 {
+		return properties
+	}
+
+	filtered := map[string]dbus.Variant{}
+	for _, name := range names {
+		if value, ok := properties[name]; ok {
+			filtered[name] = value
+		}
+	}
+
+	return filtered
+}
+
+// GetLayout returns the items below a parent item. Our menu is flat, so only the root has children.
+func (m *menu) GetLayout(parentID int32, recursionDepth int32, propertyNames []string) (uint32, menuLayout, *dbus.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	properties, ok := m.itemProperties(parentID)
+	if !ok {
+		return 0, menuLayout{}, dbus.MakeFailedError(ErrUnknownMenuItem)
+	}
+
+	layout := menuLayout{
+		ID:         parentID,
+		Properties: filterProperties(properties, propertyNames),
+		Children:   []dbus.Variant{},
+	}
+
+	if parentID == menuRootID && recursionDepth != 0 {
+		for i := range m.items {
+			id := int32(i + 1)
+			childProperties, _ := m.itemProperties(id)
+
+			layout.Children = append(layout.Children, dbus.MakeVariant(menuLayout{
+				ID:         id,
+				Properties: filterProperties(childProperties, propertyNames),
+				Children:   []dbus.Variant{},
+			}))
+		}
+	}
+
+	return m.revision, layout, nil
+}
+
+// GetGroupProperties returns the properties of multiple items, skipping unknown items
+func (m *menu) GetGroupProperties(ids []int32, propertyNames []string) ([]menuItemProperties, *dbus.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if len(ids) == 0 {
+		for i := range len(m.items) + 1 {
+			ids = append(ids, int32(i))
+		}
+	}
+
+	result := []menuItemProperties{}
+	for _, id := range ids {
+		if properties, ok := m.itemProperties(id); ok {
+			result = append(result, menuItemProperties{ID: id, Properties: filterProperties(properties, propertyNames)})
+		}
+	}
+
+	return result, nil
+}
+
+// GetProperty returns a single property of an item
+func (m *menu) GetProperty(id int32, name string) (dbus.Variant, *dbus.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	properties, ok := m.itemProperties(id)
+	if !ok {
+		return dbus.Variant{}, dbus.MakeFailedError(ErrUnknownMenuItem)
+	}
+
+	value, ok := properties[name]
+	if !ok {
+		return dbus.Variant{}, dbus.MakeFailedError(ErrUnknownMenuItemProperty)
+	}
+
+	return value, nil
+}
+
+// Event is called by the host when the user interacts with an item
+func (m *menu) Event(id int32, eventID string, data dbus.Variant, timestamp uint32) *dbus.Error {
+	if eventID != "clicked" {
+		return nil
+	}
+
+	m.lock.Lock()
+	if id < 1 || int(id) > len(m.items) {
+		m.lock.Unlock()
+
+		return dbus.MakeFailedError(ErrUnknownMenuItem)
+	}
+	item := m.items[id-1]
+	m.lock.Unlock()
+
+	if item.Separator || !item.Enabled || item.OnClicked == nil {
+		return nil
+	}
+
+	if err := item.OnClicked(m.ctx); err != nil {
+		m.log.Warn("Could not handle click on menu item", "label", item.Label, "err", err)
+
+		return dbus.MakeFailedError(err)
+	}
+
+	return nil
+}
+
+// EventGroup handles multiple events at once and returns the IDs of unknown items
+func (m *menu) EventGroup(events []menuEvent) ([]int32, *dbus.Error) {
+	idErrors := []int32{}
+	for _, event := range events {
+		if err := m.Event(event.ID, event.EventID, event.Data, event.Timestamp); err != nil {
+			idErrors = append(idErrors, event.ID)
+		}
+	}
+
+	return idErrors, nil
+}
+
+// AboutToShow is called by the host before showing an item. Our items are always up to date,
+// so the host never needs to update its layout.
+func (m *menu) AboutToShow(id int32) (bool, *dbus.Error) {
+	return false, nil
+}
+
+// AboutToShowGroup is AboutToShow for multiple items
+func (m *menu) AboutToShowGroup(ids []int32) ([]int32, []int32, *dbus.Error) {
+	return []int32{}, []int32{}, nil
+}
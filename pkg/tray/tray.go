@@ -0,0 +1,345 @@
+package tray
+
+import (
+	"context"
+	"errors"
+	"image"
+	"log/slog"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+const (
+	itemInterface = "org.kde.StatusNotifierItem"
+	itemPath      = dbus.ObjectPath("/StatusNotifierItem")
+
+	watcherName      = "org.kde.StatusNotifierWatcher"
+	watcherInterface = "org.kde.StatusNotifierWatcher"
+	watcherPath      = dbus.ObjectPath("/StatusNotifierWatcher")
+)
+
+var (
+	ErrAlreadyOpen             = errors.New("status notifier item is already open")
+	ErrUnknownMenuItem         = errors.New("unknown menu item")
+	ErrUnknownMenuItemProperty = errors.New("unknown menu item property")
+)
+
+// Status is the status of a status notifier item
+type Status string
+
+const (
+	// StatusPassive items are hidden by most hosts
+	StatusPassive Status = "Passive"
+	// StatusActive items are shown
+	StatusActive Status = "Active"
+	// StatusNeedsAttention items are shown and highlighted
+	StatusNeedsAttention Status = "NeedsAttention"
+)
+
+// Pixmap is an icon in ARGB32 format with the bytes in network byte order
+type Pixmap struct {
+	Width, Height int32
+	Data          []byte
+}
+
+// NewPixmap converts an image into a pixmap
+func NewPixmap(img image.Image) Pixmap {
+	bounds := img.Bounds()
+
+	pixmap := Pixmap{
+		Width:  int32(bounds.Dx()),
+		Height: int32(bounds.Dy()),
+		Data:   make([]byte, 0, bounds.Dx()*bounds.Dy()*4),
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Pixmaps aren't premultiplied, while colors returned from images are
+			r, g, b, a := img.At(x, y).RGBA()
+			if a > 0 {
+				r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+			}
+
+			pixmap.Data = append(pixmap.Data, uint8(a>>8), uint8(r>>8), uint8(g>>8), uint8(b>>8))
+		}
+	}
+
+	return pixmap
+}
+
+type toolTip struct {
+	IconName    string
+	IconPixmap  []Pixmap
+	Title       string
+	Description string
+}
+
+type Hooks struct {
+	// OnActivate is called when the item is clicked
+	OnActivate func(ctx context.Context) error
+}
+
+// StatusNotifierItem is a tray icon that implements the StatusNotifierItem specification
+type StatusNotifierItem struct {
+	ctx   context.Context
+	conn  *dbus.Conn
+	log   *slog.Logger
+	hooks *Hooks
+
+	id,
+	title string
+
+	lock   sync.Mutex
+	props  *prop.Properties
+	menu   *menu
+	cancel context.CancelFunc
+}
+
+// NewStatusNotifierItem creates a new status notifier item on a connection. The
+// item is only exported and registered once it is opened.
+func NewStatusNotifierItem(
+	ctx context.Context,
+	conn *dbus.Conn,
+	log *slog.Logger,
+	id,
+	title string,
+	hooks *Hooks,
+) *StatusNotifierItem {
+	return &StatusNotifierItem{
+		ctx:   ctx,
+		conn:  conn,
+		log:   log,
+		hooks: hooks,
+
+		id:    id,
+		title: title,
+
+		menu: newMenu(ctx, conn, log),
+	}
+}
+
+// Open exports the item and registers it with the status notifier watcher. If no
+// watcher is running yet, the item registers itself once one starts.
+func (s *StatusNotifierItem) Open() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.props != nil {
+		return ErrAlreadyOpen
+	}
+
+	props, err := prop.Export(s.conn, itemPath, prop.Map{
+		itemInterface: {
+			"Category":            {Value: "ApplicationStatus", Emit: prop.EmitFalse},
+			"Id":                  {Value: s.id, Emit: prop.EmitFalse},
+			"Title":               {Value: s.title, Emit: prop.EmitFalse},
+			"Status":              {Value: string(StatusPassive), Emit: prop.EmitFalse},
+			"WindowId":            {Value: int32(0), Emit: prop.EmitFalse},
+			"IconName":            {Value: "", Emit: prop.EmitFalse},
+			"IconPixmap":          {Value: []Pixmap{}, Emit: prop.EmitFalse},
+			"OverlayIconName":     {Value: "", Emit: prop.EmitFalse},
+			"OverlayIconPixmap":   {Value: []Pixmap{}, Emit: prop.EmitFalse},
+			"AttentionIconName":   {Value: "", Emit: prop.EmitFalse},
+			"AttentionIconPixmap": {Value: []Pixmap{}, Emit: prop.EmitFalse},
+			"AttentionMovieName":  {Value: "", Emit: prop.EmitFalse},
+			"ToolTip":             {Value: toolTip{IconPixmap: []Pixmap{}, Title: s.title}, Emit: prop.EmitFalse},
+			"ItemIsMenu":          {Value: false, Emit: prop.EmitFalse},
+			"Menu":                {Value: menuPath, Emit: prop.EmitFalse},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.conn.Export(s, itemPath, itemInterface); err != nil {
+		return err
+	}
+
+	if err := s.conn.Export(introspect.NewIntrospectable(&introspect.Node{
+		Name: string(itemPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{
+				Name:       itemInterface,
+				Methods:    introspect.Methods(s),
+				Properties: props.Introspection(itemInterface),
+				Signals: []introspect.Signal{
+					{Name: "NewTitle"},
+					{Name: "NewIcon"},
+					{Name: "NewAttentionIcon"},
+					{Name: "NewOverlayIcon"},
+					{Name: "NewToolTip"},
+					{Name: "NewStatus", Args: []introspect.Arg{{Name: "status", Type: "s"}}},
+				},
+			},
+		},
+	}), itemPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return err
+	}
+
+	if err := s.menu.export(); err != nil {
+		return err
+	}
+
+	s.props = props
+
+	// Hosts forget about all items when the watcher restarts, so we need to register again
+	if err := s.conn.AddMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.DBus"),
+		dbus.WithMatchMember("NameOwnerChanged"),
+		dbus.WithMatchArg(0, watcherName),
+	); err != nil {
+		return err
+	}
+
+	signals := make(chan *dbus.Signal, 10)
+	s.conn.Signal(signals)
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.cancel = cancel
+
+	go func() {
+		defer s.conn.RemoveSignal(signals)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case signal := <-signals:
+				if signal.Name != "org.freedesktop.DBus.NameOwnerChanged" || len(signal.Body) < 3 {
+					continue
+				}
+
+				if name, ok := signal.Body[0].(string); !ok || name != watcherName {
+					continue
+				}
+
+				if newOwner, ok := signal.Body[2].(string); !ok || newOwner == "" {
+					continue
+				}
+
+				if err := s.register(); err != nil {
+					s.log.Warn("Could not register status notifier item with restarted watcher", "err", err)
+				}
+			}
+		}
+	}()
+
+	var hasWatcher bool
+	if err := s.conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, watcherName).Store(&hasWatcher); err != nil {
+		return err
+	}
+
+	if !hasWatcher {
+		s.log.Debug("No status notifier watcher is running, waiting for one to start")
+
+		return nil
+	}
+
+	return s.register()
+}
+
+func (s *StatusNotifierItem) register() error {
+	// Registering the unique name instead of a well-known name means that sandboxed apps
+	// don't need permission to own a name
+	return s.conn.Object(watcherName, watcherPath).Call(watcherInterface+".RegisterStatusNotifierItem", 0, s.conn.Names()[0]).Err
+}
+
+// Close unexports the item, which removes it from the hosts
+func (s *StatusNotifierItem) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.props == nil {
+		return nil
+	}
+
+	s.cancel()
+
+	_ = s.conn.RemoveMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.DBus"),
+		dbus.WithMatchMember("NameOwnerChanged"),
+		dbus.WithMatchArg(0, watcherName),
+	)
+
+	for _, iface := range []string{itemInterface, "org.freedesktop.DBus.Properties", "org.freedesktop.DBus.Introspectable"} {
+		if err := s.conn.Export(nil, itemPath, iface); err != nil {
+			return err
+		}
+	}
+
+	if err := s.menu.unexport(); err != nil {
+		return err
+	}
+
+	s.props = nil
+
+	return nil
+}
+
+func (s *StatusNotifierItem) set(property string, value any, signal string, args ...any) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.props == nil {
+		return nil
+	}
+
+	s.props.SetMust(itemInterface, property, value)
+
+	return s.conn.Emit(itemPath, itemInterface+"."+signal, args...)
+}
+
+// SetStatus sets whether the item is shown
+func (s *StatusNotifierItem) SetStatus(status Status) error {
+	return s.set("Status", string(status), "NewStatus", string(status))
+}
+
+// SetIcon sets the icon of the item. Hosts pick the pixmap that fits best.
+func (s *StatusNotifierItem) SetIcon(pixmaps []Pixmap) error {
+	return s.set("IconPixmap", pixmaps, "NewIcon")
+}
+
+// SetToolTip sets the tool tip of the item
+func (s *StatusNotifierItem) SetToolTip(title, description string) error {
+	return s.set("ToolTip", toolTip{IconPixmap: []Pixmap{}, Title: title, Description: description}, "NewToolTip")
+}
+
+// SetMenu replaces the items of the context menu
+func (s *StatusNotifierItem) SetMenu(items []MenuItem) error {
+	return s.menu.setItems(items)
+}
+
+// Activate is called by the host when the item is clicked
+func (s *StatusNotifierItem) Activate(x, y int32) *dbus.Error {
+	if s.hooks.OnActivate == nil {
+		return nil
+	}
+
+	if err := s.hooks.OnActivate(s.ctx); err != nil {
+		s.log.Warn("Could not activate status notifier item", "err", err)
+
+		return dbus.MakeFailedError(err)
+	}
+
+	return nil
+}
+
+// SecondaryActivate is called by the host when the item is middle-clicked
+func (s *StatusNotifierItem) SecondaryActivate(x, y int32) *dbus.Error {
+	return nil
+}
+
+// ContextMenu is only called by hosts that don't support the exported menu
+func (s *StatusNotifierItem) ContextMenu(x, y int32) *dbus.Error {
+	return nil
+}
+
+// Scroll is called by the host when the mouse wheel is used on the item
+func (s *StatusNotifierItem) Scroll(delta int32, orientation string) *dbus.Error {
+	return nil
+}
@@ -0,0 +1,223 @@
+package tray
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/neilotoole/slogt"
+	"github.com/pojntfx/sessions/internal/dbustest"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWatcher struct {
+	registrations chan string
+}
+
+func (w *fakeWatcher) RegisterStatusNotifierItem(service string) *dbus.Error {
+	w.registrations <- service
+
+	return nil
+}
+
+func startWatcher(t *testing.T, address string) *fakeWatcher {
+	t.Helper()
+
+	conn := dbustest.Connect(t, address)
+
+	w := &fakeWatcher{registrations: make(chan string, 10)}
+	require.NoError(t, conn.Export(w, watcherPath, watcherInterface))
+
+	reply, err := conn.RequestName(watcherName, dbus.NameFlagDoNotQueue)
+	require.NoError(t, err)
+	require.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+
+	return w
+}
+
+func openItem(t *testing.T, conn *dbus.Conn, hooks *Hooks) *StatusNotifierItem {
+	t.Helper()
+
+	s := NewStatusNotifierItem(t.Context(), conn, slogt.New(t), "test", "Test", hooks)
+	require.NoError(t, s.Open())
+
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+	})
+
+	return s
+}
+
+func receive[T any](t *testing.T, c <-chan T) T {
+	t.Helper()
+
+	select {
+	case v := <-c:
+		return v
+
+	case <-time.After(time.Second * 5):
+		require.FailNow(t, "timed out waiting for value")
+	}
+
+	panic("unreachable")
+}
+
+func TestRegistration(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	t.Run("registers with a running watcher", func(t *testing.T) {
+		w := startWatcher(t, address)
+
+		conn := dbustest.Connect(t, address)
+		openItem(t, conn, &Hooks{})
+
+		require.Equal(t, conn.Names()[0], receive(t, w.registrations))
+	})
+
+	t.Run("registers with a watcher that starts later", func(t *testing.T) {
+		conn := dbustest.Connect(t, address)
+		openItem(t, conn, &Hooks{})
+
+		w := startWatcher(t, address)
+
+		require.Equal(t, conn.Names()[0], receive(t, w.registrations))
+	})
+}
+
+func TestProperties(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	conn := dbustest.Connect(t, address)
+	s := openItem(t, conn, &Hooks{})
+
+	host := dbustest.Connect(t, address)
+	require.NoError(t, host.AddMatchSignal(dbus.WithMatchInterface(itemInterface)))
+
+	signals := make(chan *dbus.Signal, 10)
+	host.Signal(signals)
+
+	obj := host.Object(conn.Names()[0], itemPath)
+
+	id, err := obj.GetProperty(itemInterface + ".Id")
+	require.NoError(t, err)
+	require.Equal(t, "test", id.Value())
+
+	menu, err := obj.GetProperty(itemInterface + ".Menu")
+	require.NoError(t, err)
+	require.Equal(t, menuPath, menu.Value())
+
+	require.NoError(t, s.SetStatus(StatusActive))
+	require.Equal(t, itemInterface+".NewStatus", receive(t, signals).Name)
+
+	status, err := obj.GetProperty(itemInterface + ".Status")
+	require.NoError(t, err)
+	require.Equal(t, string(StatusActive), status.Value())
+
+	require.NoError(t, s.SetToolTip("Test", "12:30"))
+	require.Equal(t, itemInterface+".NewToolTip", receive(t, signals).Name)
+
+	var tip toolTip
+	require.NoError(t, obj.StoreProperty(itemInterface+".ToolTip", &tip))
+	require.Equal(t, "Test", tip.Title)
+	require.Equal(t, "12:30", tip.Description)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff})
+	require.NoError(t, s.SetIcon([]Pixmap{NewPixmap(img)}))
+	require.Equal(t, itemInterface+".NewIcon", receive(t, signals).Name)
+
+	var pixmaps []Pixmap
+	require.NoError(t, obj.StoreProperty(itemInterface+".IconPixmap", &pixmaps))
+	require.Equal(t, []Pixmap{{Width: 2, Height: 1, Data: []byte{0xff, 0x11, 0x22, 0x33, 0, 0, 0, 0}}}, pixmaps)
+}
+
+func TestActivate(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	activations := make(chan struct{}, 1)
+
+	conn := dbustest.Connect(t, address)
+	openItem(t, conn, &Hooks{
+		OnActivate: func(ctx context.Context) error {
+			activations <- struct{}{}
+
+			return nil
+		},
+	})
+
+	host := dbustest.Connect(t, address)
+	require.NoError(t, host.Object(conn.Names()[0], itemPath).Call(itemInterface+".Activate", 0, int32(0), int32(0)).Err)
+
+	receive(t, activations)
+}
+
+func TestMenu(t *testing.T) {
+	address := dbustest.StartBus(t)
+
+	clicks := make(chan string, 10)
+	click := func(label string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			clicks <- label
+
+			return nil
+		}
+	}
+
+	conn := dbustest.Connect(t, address)
+	s := openItem(t, conn, &Hooks{})
+
+	host := dbustest.Connect(t, address)
+	require.NoError(t, host.AddMatchSignal(dbus.WithMatchInterface(menuInterface)))
+
+	signals := make(chan *dbus.Signal, 10)
+	host.Signal(signals)
+
+	require.NoError(t, s.SetMenu([]MenuItem{
+		{Label: "Start", Enabled: true, OnClicked: click("Start")},
+		{Label: "Add", Enabled: false, OnClicked: click("Add")},
+		{Separator: true},
+		{Label: "Show", Enabled: true, OnClicked: click("Show")},
+	}))
+
+	layoutUpdated := receive(t, signals)
+	require.Equal(t, menuInterface+".LayoutUpdated", layoutUpdated.Name)
+	require.Equal(t, []any{uint32(1), menuRootID}, layoutUpdated.Body)
+
+	obj := host.Object(conn.Names()[0], menuPath)
+
+	var (
+		revision uint32
+		layout   menuLayout
+	)
+	require.NoError(t, obj.Call(menuInterface+".GetLayout", 0, menuRootID, int32(-1), []string{}).Store(&revision, &layout))
+	require.Equal(t, uint32(1), revision)
+	require.Len(t, layout.Children, 4)
+
+	labels := []any{}
+	for _, child := range layout.Children {
+		var item menuLayout
+		require.NoError(t, dbus.Store([]any{child.Value()}, &item))
+
+		labels = append(labels, item.Properties["label"].Value())
+	}
+	require.Equal(t, []any{"Start", "Add", nil, "Show"}, labels)
+
+	var label dbus.Variant
+	require.NoError(t, obj.Call(menuInterface+".GetProperty", 0, int32(4), "label").Store(&label))
+	require.Equal(t, "Show", label.Value())
+
+	require.Error(t, obj.Call(menuInterface+".GetProperty", 0, int32(5), "label").Err)
+
+	require.NoError(t, obj.Call(menuInterface+".Event", 0, int32(1), "clicked", dbus.MakeVariant(""), uint32(0)).Err)
+	require.Equal(t, "Start", receive(t, clicks))
+
+	// Disabled items and hovers are ignored
+	require.NoError(t, obj.Call(menuInterface+".Event", 0, int32(2), "clicked", dbus.MakeVariant(""), uint32(0)).Err)
+	require.NoError(t, obj.Call(menuInterface+".Event", 0, int32(4), "hovered", dbus.MakeVariant(""), uint32(0)).Err)
+	require.NoError(t, obj.Call(menuInterface+".Event", 0, int32(4), "clicked", dbus.MakeVariant(""), uint32(0)).Err)
+	require.Equal(t, "Show", receive(t, clicks))
+	require.Empty(t, clicks)
+}
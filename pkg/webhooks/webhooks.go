@@ -0,0 +1,320 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pojntfx/sessions/pkg/state"
+)
+
+// Event is a timer event that webhooks are sent for
+type Event string
+
+const (
+	EventStart          Event = "start"
+	EventStop           Event = "stop"
+	EventPause          Event = "pause"
+	EventResume         Event = "resume"
+	EventDurationChange Event = "duration-change"
+	EventAlarmStart     Event = "alarm-start"
+	EventAlarmStop      Event = "alarm-stop"
+)
+
+const (
+	HeaderEvent     = "X-Sessions-Event"
+	HeaderSignature = "X-Sessions-Signature"
+
+	// Prefix of the signature header value, followed by the hex-encoded HMAC-SHA256 of the body
+	SignaturePrefix = "sha256="
+
+	// Upper bound for the time between two attempts
+	maxBackoff = time.Minute
+)
+
+var (
+	errUnexpectedStatus = errors.New("unexpected status code")
+)
+
+// Payload is the JSON body of a webhook request. Durations are in seconds.
+type Payload struct {
+	Event     Event     `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+	Duration  int64     `json:"duration"`
+	Remaining int64     `json:"remaining"`
+}
+
+// Sign returns the signature header value for a body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks whether a signature header value matches a body
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Sender posts webhooks for timer events. Requests are sent in the background and
+// retried with exponential backoff if they fail.
+type Sender struct {
+	ctx    context.Context
+	log    *slog.Logger
+	client *http.Client
+
+	maxAttempts    int
+	initialBackoff time.Duration
+
+	lock      sync.Mutex
+	urls      []string
+	secret    string
+	duration  time.Duration
+	remaining time.Duration
+	// Whether a session is running, since the timer also starts again after its time was adjusted
+	running bool
+
+	wg sync.WaitGroup
+}
+
+func NewSender(
+	ctx context.Context,
+	log *slog.Logger,
+	client *http.Client,
+	maxAttempts int,
+	initialBackoff time.Duration,
+	initialRemainingTime time.Duration,
+) *Sender {
+	return &Sender{
+		ctx:    ctx,
+		log:    log,
+		client: client,
+
+		maxAttempts:    maxAttempts,
+		initialBackoff: initialBackoff,
+
+		urls:      []string{},
+		duration:  initialRemainingTime,
+		remaining: initialRemainingTime,
+	}
+}
+
+// SetURLs sets the URLs that webhooks are posted to
+func (s *Sender) SetURLs(urls []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.urls = urls
+}
+
+// SetSecret sets the secret that requests are signed with. Requests aren't signed if it is empty.
+func (s *Sender) SetSecret(secret string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.secret = secret
+}
+
+// Send posts a webhook for an event to all URLs in the background
+func (s *Sender) Send(event Event) {
+	s.lock.Lock()
+	urls, secret := s.urls, s.secret
+	payload := Payload{
+		Event:     event,
+		Timestamp: time.Now().UTC(),
+		Duration:  int64(s.duration.Seconds()),
+		Remaining: int64(s.remaining.Seconds()),
+	}
+	s.lock.Unlock()
+
+	if len(urls) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		s.log.Warn("Could not encode webhook payload", "event", event, "err", err)
+
+		return
+	}
+
+	for _, url := range urls {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			if err := s.deliver(url, event, secret, body); err != nil {
+				s.log.Warn("Could not deliver webhook", "event", event, "url", url, "err", err)
+
+				return
+			}
+
+			s.log.Debug("Delivered webhook", "event", event, "url", url)
+		}()
+	}
+}
+
+func (s *Sender) deliver(url string, event Event, secret string, body []byte) error {
+	backoff := s.initialBackoff
+
+	var err error
+	for attempt := 1; attempt <= s.maxAttempts; attempt++ {
+		var retry bool
+		retry, err = s.post(url, event, secret, body)
+		if err == nil || !retry {
+			return err
+		}
+
+		if attempt == s.maxAttempts {
+			break
+		}
+
+		s.log.Debug("Retrying webhook", "event", event, "url", url, "attempt", attempt, "backoff", backoff, "err", err)
+
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+
+	return err
+}
+
+// post sends a single request and returns whether it should be retried if it failed
+func (s *Sender) post(url string, event Event, secret string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(event))
+	if secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, body))
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return s.ctx.Err() == nil, err
+	}
+	defer res.Body.Close()
+
+	// We need to read the body for the connection to be reused
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+
+	// Client errors won't go away by retrying, except for rate limits
+	retry := res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+
+	return retry, fmt.Errorf("%w: %v", errUnexpectedStatus, res.Status)
+}
+
+// Wait waits for all webhooks that are being sent to be delivered or to fail
+func (s *Sender) Wait() {
+	s.wg.Wait()
+}
+
+// Hooks returns state machine hooks that send the webhooks, which can be combined with other hooks
+func (s *Sender) Hooks() *state.Hooks {
+	send := func(event Event) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			s.Send(event)
+
+			return nil
+		}
+	}
+
+	sendWithRemainingTime := func(event Event) func(ctx context.Context, currentRemainingTime time.Duration) error {
+		return func(ctx context.Context, currentRemainingTime time.Duration) error {
+			s.lock.Lock()
+			s.remaining = currentRemainingTime
+			s.lock.Unlock()
+
+			s.Send(event)
+
+			return nil
+		}
+	}
+
+	return &state.Hooks{
+		OnStartTimer: func(ctx context.Context) error {
+			s.lock.Lock()
+			// Adjusting the time continues the session, which was already sent as a duration change
+			if s.running {
+				s.lock.Unlock()
+
+				return nil
+			}
+			s.running = true
+			s.remaining = s.duration
+			s.lock.Unlock()
+
+			s.Send(EventStart)
+
+			return nil
+		},
+		OnStopTimer: func(ctx context.Context) error {
+			s.lock.Lock()
+			s.running = false
+			s.lock.Unlock()
+
+			s.Send(EventStop)
+
+			return nil
+		},
+
+		OnPauseTimer:  sendWithRemainingTime(EventPause),
+		OnResumeTimer: sendWithRemainingTime(EventResume),
+
+		OnInitialRemainingTimeChange: func(ctx context.Context, initialRemainingTime time.Duration) error {
+			s.lock.Lock()
+			s.duration = initialRemainingTime
+			// A running timer counts down from the adjusted time
+			if s.running {
+				s.remaining = initialRemainingTime
+			}
+			s.lock.Unlock()
+
+			s.Send(EventDurationChange)
+
+			return nil
+		},
+		OnCurrentRemainingTimeTick: func(ctx context.Context, currentRemainingTime time.Duration) error {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+
+			s.remaining = currentRemainingTime
+
+			return nil
+		},
+
+		OnStartAlarm: func(ctx context.Context) error {
+			s.lock.Lock()
+			s.remaining = 0
+			s.lock.Unlock()
+
+			s.Send(EventAlarmStart)
+
+			return nil
+		},
+		OnStopAlarm: send(EventAlarmStop),
+	}
+}
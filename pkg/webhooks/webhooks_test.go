@@ -0,0 +1,244 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/neilotoole/slogt"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSecret = "secret"
+)
+
+type request struct {
+	event     string
+	signature string
+	payload   Payload
+	body      []byte
+}
+
+// startServer starts a webhook receiver that responds with the given status codes in
+// order, and with 200 once it runs out of them
+func startServer(t *testing.T, statuses ...int) (*httptest.Server, func() []request) {
+	t.Helper()
+
+	var (
+		lock     sync.Mutex
+		requests []request
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var payload Payload
+		require.NoError(t, json.Unmarshal(body, &payload))
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		requests = append(requests, request{
+			event:     r.Header.Get(HeaderEvent),
+			signature: r.Header.Get(HeaderSignature),
+			payload:   payload,
+			body:      body,
+		})
+
+		status := http.StatusOK
+		if len(requests) <= len(statuses) {
+			status = statuses[len(requests)-1]
+		}
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []request {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return append([]request{}, requests...)
+	}
+}
+
+func newTestSender(t *testing.T, urls ...string) *Sender {
+	t.Helper()
+
+	s := NewSender(t.Context(), slogt.New(t), http.DefaultClient, 3, time.Millisecond, time.Minute*25)
+	s.SetURLs(urls)
+	s.SetSecret(testSecret)
+
+	t.Cleanup(s.Wait)
+
+	return s
+}
+
+func TestHooks(t *testing.T) {
+	server, requests := startServer(t)
+	s := newTestSender(t, server.URL)
+	h := s.Hooks()
+
+	require.NoError(t, h.OnInitialRemainingTimeChange(t.Context(), time.Minute*10))
+	s.Wait()
+	require.NoError(t, h.OnStartTimer(t.Context()))
+	s.Wait()
+	require.NoError(t, h.OnCurrentRemainingTimeTick(t.Context(), time.Minute*8))
+	require.NoError(t, h.OnPauseTimer(t.Context(), time.Minute*7))
+	s.Wait()
+	require.NoError(t, h.OnResumeTimer(t.Context(), time.Minute*7))
+	s.Wait()
+	require.NoError(t, h.OnStartAlarm(t.Context()))
+	s.Wait()
+	require.NoError(t, h.OnStopAlarm(t.Context()))
+	s.Wait()
+
+	// Ticks don't send webhooks
+	received := requests()
+	require.Len(t, received, 6)
+
+	type summary struct {
+		Event     Event
+		Duration  int64
+		Remaining int64
+	}
+	summaries := []summary{}
+	for _, r := range received {
+		require.Equal(t, string(r.payload.Event), r.event)
+		require.True(t, Verify(testSecret, r.body, r.signature))
+		require.False(t, r.payload.Timestamp.IsZero())
+
+		summaries = append(summaries, summary{r.payload.Event, r.payload.Duration, r.payload.Remaining})
+	}
+
+	require.Equal(t, []summary{
+		{EventDurationChange, 600, 1500},
+		{EventStart, 600, 600},
+		{EventPause, 600, 420},
+		{EventResume, 600, 420},
+		{EventAlarmStart, 600, 0},
+		{EventAlarmStop, 600, 0},
+	}, summaries)
+}
+
+func TestAdjustingTime(t *testing.T) {
+	server, requests := startServer(t)
+	s := newTestSender(t, server.URL)
+	h := s.Hooks()
+
+	require.NoError(t, h.OnStartTimer(t.Context()))
+	s.Wait()
+	require.NoError(t, h.OnCurrentRemainingTimeTick(t.Context(), time.Minute*8))
+
+	// The timer starts again after its time was adjusted
+	require.NoError(t, h.OnInitialRemainingTimeChange(t.Context(), time.Minute*9))
+	s.Wait()
+	require.NoError(t, h.OnStartTimer(t.Context()))
+	s.Wait()
+	require.NoError(t, h.OnStopTimer(t.Context()))
+	s.Wait()
+
+	received := requests()
+	require.Len(t, received, 3)
+
+	require.Equal(t, EventStart, received[0].payload.Event)
+	require.Equal(t, int64(1500), received[0].payload.Remaining)
+
+	require.Equal(t, EventDurationChange, received[1].payload.Event)
+	require.Equal(t, int64(540), received[1].payload.Duration)
+	require.Equal(t, int64(540), received[1].payload.Remaining)
+
+	require.Equal(t, EventStop, received[2].payload.Event)
+}
+
+var retryTests = []struct {
+	name     string
+	statuses []int
+	attempts int
+}{
+	{
+		name:     "success is not retried",
+		statuses: []int{http.StatusNoContent},
+		attempts: 1,
+	},
+	{
+		name:     "server errors are retried",
+		statuses: []int{http.StatusInternalServerError, http.StatusBadGateway},
+		attempts: 3,
+	},
+	{
+		name:     "rate limits are retried",
+		statuses: []int{http.StatusTooManyRequests},
+		attempts: 2,
+	},
+	{
+		name:     "client errors are not retried",
+		statuses: []int{http.StatusBadRequest},
+		attempts: 1,
+	},
+	{
+		name:     "retries stop after the maximum number of attempts",
+		statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+		attempts: 3,
+	},
+}
+
+func TestRetry(t *testing.T) {
+	for _, tt := range retryTests {
+		t.Run(
+			tt.name,
+			func(t *testing.T) {
+				server, requests := startServer(t, tt.statuses...)
+				s := newTestSender(t, server.URL)
+
+				s.Send(EventStart)
+				s.Wait()
+
+				require.Len(t, requests(), tt.attempts)
+			},
+		)
+	}
+}
+
+func TestMultipleURLs(t *testing.T) {
+	first, firstRequests := startServer(t)
+	second, secondRequests := startServer(t)
+
+	s := newTestSender(t, first.URL, second.URL)
+	s.SetSecret("")
+
+	s.Send(EventStop)
+	s.Wait()
+
+	for _, requests := range [][]request{firstRequests(), secondRequests()} {
+		require.Len(t, requests, 1)
+		require.Equal(t, EventStop, requests[0].payload.Event)
+
+		// Requests aren't signed without a secret
+		require.Empty(t, requests[0].signature)
+	}
+
+	s.SetURLs(nil)
+	s.Send(EventStop)
+	s.Wait()
+
+	require.Len(t, firstRequests(), 1)
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"start"}`)
+
+	signature := Sign(testSecret, body)
+	require.Equal(t, "sha256=", signature[:len(SignaturePrefix)])
+	require.Len(t, signature, len(SignaturePrefix)+64)
+
+	require.True(t, Verify(testSecret, body, signature))
+	require.False(t, Verify("other", body, signature))
+	require.False(t, Verify(testSecret, []byte(`{"event":"stop"}`), signature))
+}